@@ -16,12 +16,16 @@ import (
 	httpDelivery "go-boilerplate/internal/delivery/http"
 	grpcgateway "go-boilerplate/internal/gateway/grpc"
 	httpgateway "go-boilerplate/internal/gateway/http"
+	"go-boilerplate/internal/infrastructure/cache"
 	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/infrastructure/migration"
+	"go-boilerplate/internal/infrastructure/minio"
 	"go-boilerplate/internal/infrastructure/rabbitmq"
 	"go-boilerplate/internal/infrastructure/redis"
 
 	"go-boilerplate/pkg/logger"
 
+	"go.elastic.co/apm/v2"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -60,6 +64,14 @@ func main() {
 	db := database.Connect(cfg.Database)
 	defer db.Close()
 
+	if cfg.Database.AutoMigrate {
+		applied, err := migration.UpEmbedded(context.Background(), cfg.Database.Master)
+		if err != nil {
+			logger.Fatal("Failed to run migrations", zap.Error(err))
+		}
+		logger.Info("Database migrations applied", zap.Int("count", len(applied)))
+	}
+
 	// Initialize Redis
 	rdb := redis.Connect(cfg.Redis)
 	defer rdb.Close()
@@ -68,8 +80,12 @@ func main() {
 	mqConn := rabbitmq.Connect(cfg.RabbitMQ)
 	defer mqConn.Close()
 
-	// Initialize Minio (Optional, just ensuring it connects)
-	// minioClient := minio.Connect(cfg.Minio)
+	// Initialize Minio
+	minioClient := minio.Connect(cfg.Minio)
+	if err := minio.EnsureBucket(context.Background(), minioClient, cfg.Minio.BucketName); err != nil {
+		logger.Fatal("Failed to prepare Minio bucket", zap.Error(err))
+	}
+	storage := minio.NewStorage(minioClient, cfg.Minio.BucketName)
 
 	// Initialize Gateways
 	productGateway := httpgateway.NewProductGateway(cfg.External.ProductAPIURL)
@@ -84,11 +100,19 @@ func main() {
 	paymentClient := pb.NewPaymentServiceClient(grpcConn)
 	paymentGateway := grpcgateway.NewPaymentGateway(paymentClient)
 
+	// Report cache hit/miss counters with the APM metrics
+	apm.DefaultTracer().RegisterMetricsGatherer(cache.DefaultMetrics)
+
 	// Initialize Container (Repositories → Usecases → Handlers)
-	c := container.NewContainer(cfg, db, rdb, mqConn, productGateway, paymentGateway)
+	c := container.NewContainer(cfg, db, rdb, mqConn, storage, productGateway, paymentGateway)
+
+	// Evict local cache entries invalidated by other replicas
+	busCtx, stopBus := context.WithCancel(context.Background())
+	defer stopBus()
+	go c.CacheBus.Run(busCtx)
 
 	// Initialize Router
-	router := httpDelivery.NewRouter(cfg, rdb, c)
+	router := httpDelivery.NewRouter(cfg, c)
 
 	// Start Server
 	srv := &http.Server{
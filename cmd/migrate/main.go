@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/infrastructure/migration"
+	"go-boilerplate/migrations"
+	"go-boilerplate/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up              apply all pending migrations
+  down [N]        revert the last N migrations, 1 by default
+  status          print the current version and the pending migrations
+  force VERSION   set the version without migrating, after fixing a failed migration
+  create NAME     add empty up and down files to ./migrations`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command, args := os.Args[1], os.Args[2:]
+
+	// Creating files needs no database
+	if command == "create" {
+		if len(args) != 1 {
+			log.Fatal("Usage: migrate create NAME")
+		}
+		up, down, err := migration.Create("migrations", args[0])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Printf("Created %s\nCreated %s\n", up, down)
+		return
+	}
+
+	// Load config
+	cfg := config.LoadConfig()
+
+	// Initialize Logger
+	logger.InitLogger(&logger.LogstashConfig{
+		Host: cfg.Logstash.Host,
+		Port: cfg.Logstash.Port,
+	})
+
+	loaded, err := migration.Load(migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	conn, err := migration.Connect(ctx, cfg.Database.Master)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	migrator := migration.New(conn, loaded)
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		logMigrations("Applied migration", applied)
+		if err != nil {
+			logger.Fatal("Migration failed", zap.Error(err))
+		}
+		logger.Info("Database is up to date", zap.Int("applied", len(applied)))
+
+	case "down":
+		n := 1
+		if len(args) > 0 {
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				log.Fatalf("Invalid number of migrations %q", args[0])
+			}
+		}
+		reverted, err := migrator.Down(ctx, n)
+		logMigrations("Reverted migration", reverted)
+		if err != nil {
+			logger.Fatal("Migration failed", zap.Error(err))
+		}
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Version: %d", status.Version)
+		if status.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Printf("\nPending: %d\n", len(status.Pending))
+		for _, m := range status.Pending {
+			fmt.Printf("  %06d_%s\n", m.Version, m.Name)
+		}
+
+	case "force":
+		if len(args) != 1 {
+			log.Fatal("Usage: migrate force VERSION")
+		}
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid version %q", args[0])
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			log.Fatal(err)
+		}
+		logger.Info("Forced migration version", zap.Uint64("version", version))
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func logMigrations(message string, migrations []migration.Migration) {
+	for _, m := range migrations {
+		logger.Info(message, zap.Uint64("version", m.Version), zap.String("name", m.Name))
+	}
+}
@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
-	"os"
-	"path/filepath"
 
 	"go-boilerplate/internal/config"
 	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/infrastructure/seed"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/migrations"
 	"go-boilerplate/pkg/logger"
+	"go-boilerplate/pkg/tenant"
 
 	"go.uber.org/zap"
 )
@@ -17,6 +20,13 @@ func main() {
 	// Load config
 	cfg := config.LoadConfig()
 
+	env := flag.String("env", cfg.Seed.Environment, "seed set to apply: dev, staging or test")
+	fakeUsers := flag.Int("fake-users", 0, "only create this many more fake users, without recording it, for load tests")
+	flag.Parse()
+	if err := seed.Validate(*env); err != nil {
+		log.Fatal(err)
+	}
+
 	// Initialize Logger
 	logger.InitLogger(&logger.LogstashConfig{
 		Host: cfg.Logstash.Host,
@@ -27,40 +37,40 @@ func main() {
 	db := database.Connect(cfg.Database)
 	defer db.Close()
 
-	ctx := context.Background()
-
-	// Path to seeder directory
-	seederDir := "migrations/seeder"
-
-	files, err := os.ReadDir(seederDir)
-	if err != nil {
-		log.Fatalf("Failed to read seeder directory: %v", err)
+	// SQL seeders are not scoped to an organization
+	ctx := tenant.WithSystem(context.Background())
+	userRepo := repository.NewUserRepository(db)
+	fakeUserOptions := seed.FakeUserOptions{
+		Count:          cfg.Seed.FakeUsers,
+		Password:       cfg.Seed.FakeUserPassword,
+		OrganizationID: cfg.Seed.FakeUserOrganization,
 	}
 
-	logger.Info("Running SQL seeders...")
-
-	for _, file := range files {
-		if file.IsDir() || filepath.Ext(file.Name()) != ".sql" {
-			continue
-		}
-
-		logger.Info("Executing seeder file", zap.String("file", file.Name()))
-
-		content, err := os.ReadFile(filepath.Join(seederDir, file.Name()))
-		if err != nil {
-			logger.Error("Failed to read seeder file", zap.String("file", file.Name()), zap.Error(err))
-			continue
+	if *fakeUsers > 0 {
+		fakeUserOptions.Count = *fakeUsers
+		users := seed.FakeUsers("fake_users", userRepo, fakeUserOptions)
+		if err := db.WithTx(ctx, database.TxOptions{}, users.Run); err != nil {
+			logger.Fatal("Failed to create fake users", zap.Error(err))
 		}
+		logger.Info("Fake users created", zap.Int("count", *fakeUsers))
+		return
+	}
 
-		query := string(content)
-		_, err = db.Master.Exec(ctx, query)
-		if err != nil {
-			logger.Error("Failed to execute seeder", zap.String("file", file.Name()), zap.Error(err))
-		} else {
-			logger.Info("Successfully executed seeder", zap.String("file", file.Name()))
-		}
+	seeds, err := seed.LoadSQL(migrations.SeederFS, *env, db)
+	if err != nil {
+		logger.Fatal("Failed to load seeders", zap.Error(err))
+	}
+	if *env == seed.EnvironmentStaging {
+		seeds = append(seeds, seed.FakeUsers("fake_users", userRepo, fakeUserOptions))
 	}
 
-	logger.Info("Seeding completed!")
-	os.Exit(0)
+	logger.Info("Running seeders...", zap.String("environment", *env))
+	applied, err := seed.NewRunner(db).Run(ctx, *env, seeds)
+	for _, name := range applied {
+		logger.Info("Successfully executed seeder", zap.String("seed", name))
+	}
+	if err != nil {
+		logger.Fatal("Seeding failed", zap.Error(err))
+	}
+	logger.Info("Seeding completed!", zap.Int("applied", len(applied)), zap.Int("skipped", len(seeds)-len(applied)))
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/delivery/worker"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/infrastructure/mailer"
+	"go-boilerplate/internal/infrastructure/minio"
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+	"go-boilerplate/internal/infrastructure/redis"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// Load Config
+	cfg := config.LoadConfig()
+
+	// Initialize Logger
+	logger.InitLogger(&logger.LogstashConfig{
+		Host: cfg.Logstash.Host,
+		Port: cfg.Logstash.Port,
+	})
+	defer logger.Log.Sync()
+
+	// Initialize Database
+	db := database.Connect(cfg.Database)
+	defer db.Close()
+
+	// Initialize Redis
+	rdb := redis.Connect(cfg.Redis)
+	defer rdb.Close()
+
+	// Initialize RabbitMQ
+	mqConn := rabbitmq.Connect(cfg.RabbitMQ)
+	defer mqConn.Close()
+
+	// Initialize Minio
+	minioClient := minio.Connect(cfg.Minio)
+	storage := minio.NewStorage(minioClient, cfg.Minio.BucketName)
+
+	// Repositories → Usecases → Workers
+	userRepo := repository.NewUserRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	publisher := rabbitmq.NewPublisher(mqConn)
+	// No local layers in the worker, the bus only broadcasts its invalidations to the API replicas
+	cacheBus := cache.NewBus(rdb)
+	userCache := cacheBus.Namespace("user", cache.LocalOptions{Broadcast: true})
+	revocations := cacheBus.Namespace("revocation", cache.LocalOptions{Broadcast: true})
+	userImportUsecase := usecase.NewUserImportUsecase(userRepo, jobRepo, storage, publisher, cfg.Import)
+	privacyUsecase := usecase.NewPrivacyUsecase(userRepo, auditRepo, jobRepo, orgRepo, invitationRepo, db, storage, publisher, revocations, userCache, cfg)
+	userUsecase := usecase.NewUserUsecase(userRepo, orgRepo, auditRepo, outboxRepo, db, cfg, revocations, userCache)
+	outboxUsecase := usecase.NewOutboxUsecase(outboxRepo, db, rabbitmq.NewEventPublisher(mqConn, cfg.Outbox.Exchange), cfg.Outbox)
+	userImportWorker := worker.NewUserImportWorker(userImportUsecase)
+	userErasureWorker := worker.NewUserErasureWorker(privacyUsecase)
+	mailWorker := worker.NewMailWorker(mailer.NewSMTPMailer(cfg.Mail))
+	suspensionWorker := worker.NewSuspensionWorker(userUsecase, cfg.Suspension.LiftInterval)
+	staleImportWorker := worker.NewStaleImportWorker(userImportUsecase, cfg.Import.StaleAfter)
+	outboxRelayWorker := worker.NewOutboxRelayWorker(outboxUsecase, cfg.Outbox.PollInterval, cfg.Outbox.BatchSize)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	consumers := map[string]rabbitmq.HandlerFunc{
+		cfg.Import.Queue:         userImportWorker.Handle,
+		cfg.Privacy.ErasureQueue: userErasureWorker.Handle,
+		cfg.Mail.Queue:           mailWorker.Handle,
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Printf("Worker lifting expired suspensions every %s", cfg.Suspension.LiftInterval)
+		suspensionWorker.Run(ctx)
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Printf("Worker requeuing import jobs stale for %s", cfg.Import.StaleAfter)
+		staleImportWorker.Run(ctx)
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Printf("Worker relaying outbox events to exchange %s", cfg.Outbox.Exchange)
+		outboxRelayWorker.Run(ctx)
+	}()
+	for queue, handle := range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Worker consuming queue %s", queue)
+			if err := rabbitmq.Consume(ctx, mqConn, queue, 1, handle); err != nil {
+				logger.Fatal("Worker stopped", zap.String("queue", queue), zap.Error(err))
+			}
+		}()
+	}
+	wg.Wait()
+
+	log.Println("Worker exiting")
+}
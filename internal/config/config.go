@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"time"
+
 	"go-boilerplate/pkg/auth"
 
 	"github.com/caarlos0/env/v10"
@@ -10,17 +11,30 @@ import (
 )
 
 type Config struct {
-	App       AppConfig       `envPrefix:"APP_"`
-	Database  DatabaseConfig  `envPrefix:"DATABASE_"`
-	Redis     RedisConfig     `envPrefix:"REDIS_"`
-	RabbitMQ  RabbitMQConfig  `envPrefix:"RABBITMQ_"`
-	Minio     MinioConfig     `envPrefix:"MINIO_"`
-	JWT       JWTConfig       `envPrefix:"JWT_"`
-	External  ExternalConfig  `envPrefix:"EXTERNAL_"`
-	Logstash  LogstashConfig  `envPrefix:"LOGSTASH_"`
-	RateLimit RateLimitConfig `envPrefix:"RATE_LIMIT_"`
-	CORS      CORSConfig      `envPrefix:"CORS_"`
-	APM       APMConfig       `envPrefix:"ELASTIC_APM_"`
+	App         AppConfig         `envPrefix:"APP_"`
+	Database    DatabaseConfig    `envPrefix:"DATABASE_"`
+	Redis       RedisConfig       `envPrefix:"REDIS_"`
+	RabbitMQ    RabbitMQConfig    `envPrefix:"RABBITMQ_"`
+	Minio       MinioConfig       `envPrefix:"MINIO_"`
+	JWT         JWTConfig         `envPrefix:"JWT_"`
+	External    ExternalConfig    `envPrefix:"EXTERNAL_"`
+	Logstash    LogstashConfig    `envPrefix:"LOGSTASH_"`
+	RateLimit   RateLimitConfig   `envPrefix:"RATE_LIMIT_"`
+	CORS        CORSConfig        `envPrefix:"CORS_"`
+	APM         APMConfig         `envPrefix:"ELASTIC_APM_"`
+	Import      ImportConfig      `envPrefix:"IMPORT_"`
+	Privacy     PrivacyConfig     `envPrefix:"PRIVACY_"`
+	Mail        MailConfig        `envPrefix:"MAIL_"`
+	Invite      InviteConfig      `envPrefix:"INVITATION_"`
+	Session     SessionConfig     `envPrefix:"SESSION_"`
+	Suspension  SuspensionConfig  `envPrefix:"SUSPENSION_"`
+	Cache       CacheConfig       `envPrefix:"CACHE_"`
+	HTTPCache   HTTPCacheConfig   `envPrefix:"HTTP_CACHE_"`
+	Product     ProductConfig     `envPrefix:"PRODUCT_CACHE_"`
+	Concurrency ConcurrencyConfig `envPrefix:"CONCURRENCY_"`
+	Consistency ConsistencyConfig `envPrefix:"CONSISTENCY_"`
+	Seed        SeedConfig        `envPrefix:"SEED_"`
+	Outbox      OutboxConfig      `envPrefix:"OUTBOX_"`
 }
 
 type APMConfig struct {
@@ -60,6 +74,39 @@ type DBConnectionConfig struct {
 type DatabaseConfig struct {
 	Master DBConnectionConfig `envPrefix:"MASTER_"`
 	Slave  DBConnectionConfig `envPrefix:"SLAVE_"`
+	// Replicas share the SLAVE_ credentials and pool settings
+	Replica ReplicaConfig `envPrefix:"REPLICA_"`
+
+	QueryLog QueryLogConfig `envPrefix:"QUERY_LOG_"`
+
+	// Applies the embedded migrations on API startup, replicas starting together take turns
+	AutoMigrate bool `env:"AUTO_MIGRATE" envDefault:"false"`
+
+	// Sets app.tenant_id on every acquired connection so Postgres row-level security policies apply
+	RLSEnabled bool `env:"RLS_ENABLED" envDefault:"false"`
+}
+
+type ReplicaConfig struct {
+	// host:port of each replica, SLAVE_HOST and SLAVE_PORT when empty
+	Hosts []string `env:"HOSTS" envSeparator:","`
+	// round_robin or least_connections
+	Balancer string `env:"BALANCER" envDefault:"round_robin"`
+	// Replicas replaying further behind the master are ejected until they catch up
+	MaxLag        time.Duration `env:"MAX_LAG" envDefault:"10s"`
+	CheckInterval time.Duration `env:"CHECK_INTERVAL" envDefault:"5s"`
+	CheckTimeout  time.Duration `env:"CHECK_TIMEOUT" envDefault:"2s"`
+}
+
+type QueryLogConfig struct {
+	// Percentage of successful queries under SlowThreshold that are logged, 0 to 100
+	SamplePercent float64 `env:"SAMPLE_PERCENT" envDefault:"1"`
+	// Slower queries are always logged at Warn
+	SlowThreshold time.Duration `env:"SLOW_THRESHOLD" envDefault:"500ms"`
+	// Logs the plan of slow queries, running EXPLAIN on the same connection
+	Explain        bool          `env:"EXPLAIN" envDefault:"false"`
+	ExplainTimeout time.Duration `env:"EXPLAIN_TIMEOUT" envDefault:"2s"`
+	// Query arguments may hold personal data and password hashes
+	LogArgs bool `env:"LOG_ARGS" envDefault:"false"`
 }
 
 type RedisConfig struct {
@@ -84,6 +131,149 @@ type MinioConfig struct {
 type RateLimitConfig struct {
 	Limit  int `env:"LIMIT" envDefault:"60"`  // Requests per window
 	Window int `env:"WINDOW" envDefault:"60"` // Window size in seconds
+	// sliding_window or token_bucket
+	Algorithm string `env:"ALGORITHM" envDefault:"sliding_window"`
+	// Token bucket capacity, Limit when 0
+	Burst int `env:"BURST" envDefault:"0"`
+	// JSON file with per-route policies. Limit, Window and Burst apply to the routes none matches.
+	PoliciesFile string `env:"POLICIES_FILE"`
+	// While Redis is unavailable: open allows every request, closed rejects them with 503 and
+	// local limits each replica in process, dividing every limit by Replicas
+	FailureMode string `env:"FAILURE_MODE" envDefault:"local"`
+	Replicas    int    `env:"REPLICAS" envDefault:"1"`
+	// Redis calls slower than this count as failures
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"100ms"`
+	// Consecutive failures after which Redis is skipped for BreakerCooldown
+	BreakerThreshold int           `env:"BREAKER_THRESHOLD" envDefault:"5"`
+	BreakerCooldown  time.Duration `env:"BREAKER_COOLDOWN" envDefault:"30s"`
+}
+
+type ImportConfig struct {
+	Queue       string `env:"QUEUE" envDefault:"user.import"`
+	BatchSize   int    `env:"BATCH_SIZE" envDefault:"500"`
+	MaxFileSize int64  `env:"MAX_FILE_SIZE" envDefault:"10485760"` // in bytes (10 MB)
+	// A processing job that reported no progress for this long is requeued, its worker is assumed dead.
+	// The worker also checks for such jobs at this interval.
+	StaleAfter time.Duration `env:"STALE_AFTER" envDefault:"10m"`
+}
+
+type PrivacyConfig struct {
+	ErasureQueue     string        `env:"ERASURE_QUEUE" envDefault:"user.erasure"`
+	ExportLinkExpiry time.Duration `env:"EXPORT_LINK_EXPIRY" envDefault:"15m"`
+}
+
+type MailConfig struct {
+	Host     string `env:"HOST" envDefault:"localhost"`
+	Port     string `env:"PORT" envDefault:"1025"`
+	Username string `env:"USERNAME"`
+	Password string `env:"PASSWORD"`
+	From     string `env:"FROM" envDefault:"no-reply@go-boilerplate.local"`
+	Queue    string `env:"QUEUE" envDefault:"mail.send"`
+}
+
+type InviteConfig struct {
+	Expiry    time.Duration `env:"EXPIRY" envDefault:"72h"`
+	AcceptURL string        `env:"ACCEPT_URL" envDefault:"http://localhost:8080/invitations/accept"` // token is appended as ?token=
+}
+
+type SessionConfig struct {
+	// How long the user loaded for authenticated requests is cached. Deleted accounts keep access at most this long
+	CurrentUserTTL time.Duration `env:"CURRENT_USER_TTL" envDefault:"30s"`
+}
+
+type CacheConfig struct {
+	UserTTL time.Duration `env:"USER_TTL" envDefault:"1h"`
+	// Up to this fraction of the TTL is added at random, so entries cached together expire apart
+	Jitter float64 `env:"JITTER" envDefault:"0.1"`
+	// How long "user not found" results are cached, 0 disables it
+	NegativeTTL time.Duration `env:"NEGATIVE_TTL" envDefault:"30s"`
+	// Redis calls slower than this are abandoned and the database is read instead
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"50ms"`
+
+	// In-process layers in front of Redis, per namespace
+	UserLocal       LocalCacheConfig `envPrefix:"USER_LOCAL_"`
+	RevocationLocal LocalCacheConfig `envPrefix:"REVOCATION_LOCAL_"`
+}
+
+type LocalCacheConfig struct {
+	// Maximum entries per replica, 0 disables the local layer
+	Size int `env:"SIZE" envDefault:"10000"`
+	// Bounds how long a replica that missed an invalidation serves stale data
+	TTL time.Duration `env:"TTL" envDefault:"5s"`
+}
+
+type SuspensionConfig struct {
+	// How often the worker reactivates users whose suspension has expired
+	LiftInterval time.Duration `env:"LIFT_INTERVAL" envDefault:"1m"`
+}
+
+type HTTPCacheConfig struct {
+	// Cache-Control per route, as "route|directives" pairs separated by ";". Only these routes get ETags.
+	Control map[string]string `env:"CONTROL" envSeparator:";" envKeyValSeparator:"|" envDefault:"/api/v1/users/:id|private, no-cache;/api/v1/products|private, max-age=30"`
+	// Routes whose whole responses are cached in Redis and shared between callers.
+	// Only list routes whose response does not depend on who is asking.
+	SharedRoutes []string      `env:"SHARED_ROUTES" envSeparator:";" envDefault:"/api/v1/products"`
+	SharedTTL    time.Duration `env:"SHARED_TTL" envDefault:"30s"`
+	// Request headers that change the response, part of the shared cache key
+	Vary []string `env:"VARY" envDefault:"Accept,Accept-Language"`
+}
+
+type ProductConfig struct {
+	// Pages younger than this are served from Redis without calling the catalog
+	FreshTTL time.Duration `env:"FRESH_TTL" envDefault:"1m"`
+	// Pages older than FreshTTL but within this window are served right away and refreshed in the background
+	StaleTTL time.Duration `env:"STALE_TTL" envDefault:"10m"`
+	// How long the last known page is kept to be served while the catalog is down
+	MaxStale time.Duration `env:"MAX_STALE" envDefault:"24h"`
+	// Bounds each background refresh, including its retries
+	RefreshTimeout time.Duration `env:"REFRESH_TIMEOUT" envDefault:"30s"`
+}
+
+// ConcurrencyConfig configures the adaptive in-flight limit of each route group.
+type ConcurrencyConfig struct {
+	Enabled      bool `env:"ENABLED" envDefault:"true"`
+	InitialLimit int  `env:"INITIAL_LIMIT" envDefault:"50"`
+	MinLimit     int  `env:"MIN_LIMIT" envDefault:"5"`
+	MaxLimit     int  `env:"MAX_LIMIT" envDefault:"500"`
+	// Requests slower than this shrink the limit
+	LatencyTarget time.Duration `env:"LATENCY_TARGET" envDefault:"500ms"`
+	Backoff       float64       `env:"BACKOFF" envDefault:"0.9"`
+	// Fraction of the limit unauthenticated requests may fill, the rest is kept for authenticated ones
+	AnonymousShare float64 `env:"ANONYMOUS_SHARE" envDefault:"0.5"`
+	// Sent in Retry-After when a request is shed
+	RetryAfter time.Duration `env:"RETRY_AFTER" envDefault:"1s"`
+	// Long-running routes, such as streaming exports, are not limited or measured
+	ExemptRoutes []string `env:"EXEMPT_ROUTES" envSeparator:";" envDefault:"/api/v1/users/me/export;/api/v1/admin/users/export"`
+}
+
+type ConsistencyConfig struct {
+	// Reads after a write go to the master or replicas that replayed it
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+	// How long the last write of a user is remembered, longer than the replication lag
+	TTL time.Duration `env:"TTL" envDefault:"1m"`
+}
+
+type SeedConfig struct {
+	// dev, staging or test
+	Environment string `env:"ENVIRONMENT" envDefault:"dev"`
+	// Fake users created once in staging, for load tests
+	FakeUsers        int    `env:"FAKE_USERS" envDefault:"1000"`
+	FakeUserPassword string `env:"FAKE_USER_PASSWORD" envDefault:"password123"`
+	// Organization the fake users join
+	FakeUserOrganization string `env:"FAKE_USER_ORGANIZATION" envDefault:"00000000-0000-0000-0000-000000000001"`
+}
+
+type OutboxConfig struct {
+	// Topic exchange domain events are published to, with the event type as routing key
+	Exchange string `env:"EXCHANGE" envDefault:"domain.events"`
+	// How often the worker relays pending events
+	PollInterval   time.Duration `env:"POLL_INTERVAL" envDefault:"1s"`
+	BatchSize      int           `env:"BATCH_SIZE" envDefault:"100"`
+	PublishTimeout time.Duration `env:"PUBLISH_TIMEOUT" envDefault:"5s"`
+	// Failed publishes after which an event is parked, 0 retries forever
+	MaxAttempts int `env:"MAX_ATTEMPTS" envDefault:"10"`
+	// Sent events are deleted after this long
+	Retention time.Duration `env:"RETENTION" envDefault:"168h"`
 }
 
 type CORSConfig struct {
@@ -92,7 +282,6 @@ type CORSConfig struct {
 
 type JWTConfig = auth.JWTConfig
 
-
 func LoadConfig() *Config {
 	// Load .env file if exists
 	if err := godotenv.Load(); err != nil {
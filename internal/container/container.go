@@ -1,11 +1,17 @@
 package container
 
 import (
+	"log"
+
 	"go-boilerplate/internal/config"
 	"go-boilerplate/internal/delivery/http/handler"
 	grpcgateway "go-boilerplate/internal/gateway/grpc"
 	httpgateway "go-boilerplate/internal/gateway/http"
+	"go-boilerplate/internal/infrastructure/cache"
 	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/infrastructure/minio"
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+	"go-boilerplate/internal/infrastructure/ratelimit"
 	"go-boilerplate/internal/infrastructure/redis"
 	"go-boilerplate/internal/repository"
 	"go-boilerplate/internal/usecase"
@@ -19,6 +25,30 @@ type Container struct {
 	HealthHandler  *handler.HealthHandler
 	ProductHandler *handler.ProductHandler
 	PaymentHandler *handler.PaymentHandler
+
+	UserImportHandler *handler.UserImportHandler
+	AuditHandler      *handler.AuditHandler
+	PrivacyHandler    *handler.PrivacyHandler
+
+	OrganizationHandler *handler.OrganizationHandler
+	InvitationHandler   *handler.InvitationHandler
+
+	// Loads the authenticated user for middleware.CurrentUserMiddleware
+	UserUsecase usecase.UserUsecase
+	// Loads the caller's membership for middleware.RequireMembershipRole
+	OrganizationUsecase usecase.OrganizationUsecase
+	// Token revocation markers checked by middleware.AuthMiddleware
+	Revocations cache.Cache
+	// Evicts local cache entries invalidated by other replicas, run it for the lifetime of the app
+	CacheBus *cache.Bus
+	// Shared responses stored by middleware.HTTPCacheMiddleware
+	ResponseCache cache.Cache
+	// Quota checks of middleware.RateLimitMiddleware
+	RateLimiter       ratelimit.Limiter
+	RateLimitPolicies *ratelimit.Policies
+	// Master LSN and the last write of every user for middleware.ConsistencyMiddleware
+	DB                *database.Database
+	ConsistencyTokens cache.Cache
 }
 
 // NewContainer wires repositories → usecases → handlers and returns a ready-to-use Container.
@@ -27,27 +57,91 @@ func NewContainer(
 	db *database.Database,
 	rdb *redis.Client,
 	mqConn *amqp.Connection,
+	storage minio.Storage,
 	productGateway httpgateway.ProductGateway,
 	paymentGateway grpcgateway.PaymentGateway,
 ) *Container {
 	// Repositories
 	userRepo := repository.NewUserRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+
+	// Infrastructure
+	publisher := rabbitmq.NewPublisher(mqConn)
+	cacheBus := cache.NewBus(rdb)
+	userCache := cacheBus.Namespace("user", cache.LocalOptions{
+		Size: cfg.Cache.UserLocal.Size,
+		TTL:  cfg.Cache.UserLocal.TTL,
+	})
+	// Most users have no marker, so misses are cached locally too
+	revocations := cacheBus.Namespace("revocation", cache.LocalOptions{
+		Size:        cfg.Cache.RevocationLocal.Size,
+		TTL:         cfg.Cache.RevocationLocal.TTL,
+		CacheMisses: true,
+	})
+	responseCache := cacheBus.Namespace("http", cache.LocalOptions{})
+	rateLimitPolicies, err := ratelimit.LoadPolicies(cfg.RateLimit.PoliciesFile, ratelimit.Policy{
+		Limit:         cfg.RateLimit.Limit,
+		WindowSeconds: cfg.RateLimit.Window,
+		Burst:         cfg.RateLimit.Burst,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load rate limit policies: %v", err)
+	}
+	productGateway = httpgateway.NewCachedProductGateway(productGateway, cacheBus.Namespace("product", cache.LocalOptions{}), cfg.Product)
 
 	// Usecases
-	userUsecase := usecase.NewUserUsecase(userRepo, cfg, rdb)
+	userUsecase := usecase.NewUserUsecase(userRepo, orgRepo, auditRepo, outboxRepo, db, cfg, revocations, userCache)
 	productUsecase := usecase.NewProductUsecase(productGateway)
 	paymentUsecase := usecase.NewPaymentUsecase(paymentGateway)
+	userImportUsecase := usecase.NewUserImportUsecase(userRepo, jobRepo, storage, publisher, cfg.Import)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
+	privacyUsecase := usecase.NewPrivacyUsecase(userRepo, auditRepo, jobRepo, orgRepo, invitationRepo, db, storage, publisher, revocations, userCache, cfg)
+	organizationUsecase := usecase.NewOrganizationUsecase(orgRepo, auditRepo, db)
+	invitationUsecase := usecase.NewInvitationUsecase(invitationRepo, orgRepo, userRepo, auditRepo, db, publisher, cfg)
 
 	// Handlers
 	userHandler := handler.NewUserHandler(userUsecase)
 	healthHandler := handler.NewHealthHandler(db, rdb, mqConn)
 	productHandler := handler.NewProductHandler(productUsecase)
 	paymentHandler := handler.NewPaymentHandler(paymentUsecase)
+	userImportHandler := handler.NewUserImportHandler(userImportUsecase)
+	auditHandler := handler.NewAuditHandler(auditUsecase)
+	privacyHandler := handler.NewPrivacyHandler(privacyUsecase)
+	organizationHandler := handler.NewOrganizationHandler(organizationUsecase)
+	invitationHandler := handler.NewInvitationHandler(invitationUsecase)
 
 	return &Container{
 		UserHandler:    userHandler,
 		HealthHandler:  healthHandler,
 		ProductHandler: productHandler,
 		PaymentHandler: paymentHandler,
+
+		UserImportHandler: userImportHandler,
+		AuditHandler:      auditHandler,
+		PrivacyHandler:    privacyHandler,
+
+		OrganizationHandler: organizationHandler,
+		InvitationHandler:   invitationHandler,
+
+		UserUsecase:         userUsecase,
+		OrganizationUsecase: organizationUsecase,
+		Revocations:         revocations,
+		CacheBus:            cacheBus,
+
+		ResponseCache: responseCache,
+		RateLimiter: ratelimit.WithFallback(ratelimit.New(rdb, cfg.RateLimit.Algorithm), ratelimit.FallbackOptions{
+			Mode:      cfg.RateLimit.FailureMode,
+			Replicas:  cfg.RateLimit.Replicas,
+			Timeout:   cfg.RateLimit.Timeout,
+			Threshold: cfg.RateLimit.BreakerThreshold,
+			Cooldown:  cfg.RateLimit.BreakerCooldown,
+		}),
+		RateLimitPolicies: rateLimitPolicies,
+		DB:                db,
+		ConsistencyTokens: cacheBus.Namespace("consistency", cache.LocalOptions{}),
 	}
 }
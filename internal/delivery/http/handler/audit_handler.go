@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/response"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	usecase usecase.AuditUsecase
+}
+
+func NewAuditHandler(u usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{usecase: u}
+}
+
+// ListEvents godoc
+// @Summary      List audit events
+// @Description  Paginated audit trail of mutating operations, newest first
+// @Tags         admin
+// @Produce      json
+// @Param        request query dto.ListAuditEventsRequest false "List Audit Events Request"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/admin/audit [get]
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "AuditHandler.ListEvents", "handler")
+	defer span.End()
+
+	var q dto.ListAuditEventsRequest
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	events, meta, err := h.usecase.ListEvents(ctx, q)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithPagination(c, http.StatusOK, "Audit events", events, meta)
+}
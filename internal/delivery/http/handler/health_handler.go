@@ -71,6 +71,17 @@ func (h *HealthHandler) Check(c *gin.Context) {
 		response.Services["postgres_slave"] = ServiceStatus{Status: "up"}
 	}
 
+	// Ejected replicas do not make the service down, their reads go to the other replicas or the master
+	if replicas, ok := h.db.Slave.(*database.ReplicaSet); ok {
+		for _, replica := range replicas.Status() {
+			status := ServiceStatus{Status: "up"}
+			if !replica.Healthy {
+				status = ServiceStatus{Status: "down", Message: replica.Err.Error()}
+			}
+			response.Services["postgres_replica_"+replica.Name] = status
+		}
+	}
+
 	// Check Redis
 	if _, err := h.rdb.Ping(c.Request.Context()).Result(); err != nil {
 		response.Services["redis"] = ServiceStatus{Status: "down", Message: err.Error()}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/response"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	usecase usecase.InvitationUsecase
+}
+
+func NewInvitationHandler(u usecase.InvitationUsecase) *InvitationHandler {
+	return &InvitationHandler{usecase: u}
+}
+
+// Invite godoc
+// @Summary      Invite a user
+// @Description  Email an invitation to join the organization. Requires the owner or admin role; only owners can invite admins
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "Organization ID"
+// @Param        request  body      dto.CreateInvitationRequest  true  "Create Invitation Request"
+// @Success      201  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/organizations/{id}/invitations [post]
+func (h *InvitationHandler) Invite(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "InvitationHandler.Invite", "handler")
+	defer span.End()
+
+	var req dto.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	invitation, err := h.usecase.Invite(ctx, c.Param("id"), c.GetString("userID"), req.Email, req.Role)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Invitation sent", invitation)
+}
+
+// ListInvitations godoc
+// @Summary      List pending invitations
+// @Description  List pending invitations of the organization, including expired ones that can be resent
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      string  true  "Organization ID"
+// @Success      200  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/organizations/{id}/invitations [get]
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "InvitationHandler.ListInvitations", "handler")
+	defer span.End()
+
+	invitations, err := h.usecase.ListPending(ctx, c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Invitations", invitations)
+}
+
+// Resend godoc
+// @Summary      Resend invitation
+// @Description  Email a new invitation link with a fresh expiry. Earlier links stop working
+// @Tags         organizations
+// @Produce      json
+// @Param        id            path      string  true  "Organization ID"
+// @Param        invitationId  path      string  true  "Invitation ID"
+// @Success      200  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/organizations/{id}/invitations/{invitationId}/resend [post]
+func (h *InvitationHandler) Resend(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "InvitationHandler.Resend", "handler")
+	defer span.End()
+
+	invitation, err := h.usecase.Resend(ctx, c.Param("id"), c.GetString("userID"), c.Param("invitationId"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Invitation resent", invitation)
+}
+
+// Revoke godoc
+// @Summary      Revoke invitation
+// @Description  Revoke a pending invitation so its link can no longer be accepted
+// @Tags         organizations
+// @Produce      json
+// @Param        id            path      string  true  "Organization ID"
+// @Param        invitationId  path      string  true  "Invitation ID"
+// @Success      200  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/organizations/{id}/invitations/{invitationId} [delete]
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "InvitationHandler.Revoke", "handler")
+	defer span.End()
+
+	if err := h.usecase.Revoke(ctx, c.Param("id"), c.GetString("userID"), c.Param("invitationId")); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Invitation revoked", nil)
+}
+
+// Accept godoc
+// @Summary      Accept invitation
+// @Description  Join the organization with an invitation token. A password is only needed when no account exists for the invited email
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.AcceptInvitationRequest  true  "Accept Invitation Request"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /api/v1/auth/invitations/accept [post]
+func (h *InvitationHandler) Accept(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "InvitationHandler.Accept", "handler")
+	defer span.End()
+
+	var req dto.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	res, err := h.usecase.Accept(ctx, req.Token, req.Password)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Invitation accepted", res)
+}
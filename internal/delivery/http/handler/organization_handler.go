@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/response"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	usecase usecase.OrganizationUsecase
+}
+
+func NewOrganizationHandler(u usecase.OrganizationUsecase) *OrganizationHandler {
+	return &OrganizationHandler{usecase: u}
+}
+
+// ListOrganizations godoc
+// @Summary      List my organizations
+// @Description  List every organization the current user belongs to, with their role in each
+// @Tags         organizations
+// @Produce      json
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "OrganizationHandler.ListOrganizations", "handler")
+	defer span.End()
+
+	memberships, err := h.usecase.ListMine(ctx, c.GetString("userID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Organizations", memberships)
+}
+
+// CreateOrganization godoc
+// @Summary      Create organization
+// @Description  Create an organization owned by the current user. Log in with its ID to act inside it
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.CreateOrganizationRequest true "Create Organization Request"
+// @Success      201  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "OrganizationHandler.CreateOrganization", "handler")
+	defer span.End()
+
+	var req dto.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	org, err := h.usecase.Create(ctx, c.GetString("userID"), req.Name)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusCreated, "Organization created", org)
+}
+
+// ListMembers godoc
+// @Summary      List organization members
+// @Description  List the members of the organization the token is scoped to
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      string  true  "Organization ID"
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/organizations/{id}/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "OrganizationHandler.ListMembers", "handler")
+	defer span.End()
+
+	members, err := h.usecase.ListMembers(ctx, c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Members", members)
+}
+
+// RemoveMember godoc
+// @Summary      Remove organization member
+// @Description  Remove a member from the organization. Requires the owner or admin role; owners cannot be removed
+// @Tags         organizations
+// @Produce      json
+// @Param        id      path      string  true  "Organization ID"
+// @Param        userId  path      string  true  "User ID"
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/organizations/{id}/members/{userId} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "OrganizationHandler.RemoveMember", "handler")
+	defer span.End()
+
+	if err := h.usecase.RemoveMember(ctx, c.Param("id"), c.GetString("userID"), c.Param("userId")); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Member removed", nil)
+}
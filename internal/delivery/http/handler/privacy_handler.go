@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/response"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyHandler struct {
+	usecase usecase.PrivacyUsecase
+}
+
+func NewPrivacyHandler(u usecase.PrivacyUsecase) *PrivacyHandler {
+	return &PrivacyHandler{usecase: u}
+}
+
+// ExportMyData godoc
+// @Summary      Export my data
+// @Description  Build a ZIP archive of everything held about the current user and return a temporary download link
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/users/me/export [get]
+func (h *PrivacyHandler) ExportMyData(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "PrivacyHandler.ExportMyData", "handler")
+	defer span.End()
+
+	export, err := h.usecase.ExportData(ctx, c.GetString("userID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Data export ready", export)
+}
+
+// EraseMe godoc
+// @Summary      Erase my account
+// @Description  Revoke all tokens and start anonymizing the current user's personal data
+// @Tags         users
+// @Produce      json
+// @Success      202  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/users/me [delete]
+func (h *PrivacyHandler) EraseMe(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "PrivacyHandler.EraseMe", "handler")
+	defer span.End()
+
+	job, err := h.usecase.RequestErasure(ctx, c.GetString("userID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Erasure started", gin.H{"job_id": job.ID})
+}
+
+// GetErasureCertificate godoc
+// @Summary      Get erasure certificate
+// @Description  Get the completion certificate of a finished erasure job
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/admin/jobs/{id}/certificate [get]
+func (h *PrivacyHandler) GetErasureCertificate(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "PrivacyHandler.GetErasureCertificate", "handler")
+	defer span.End()
+
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.Error(c, errors.New(http.StatusBadRequest, "Invalid Job ID"))
+		return
+	}
+
+	cert, err := h.usecase.GetErasureCertificate(ctx, idStr)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Erasure certificate", cert)
+}
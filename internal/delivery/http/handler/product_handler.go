@@ -2,8 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"go-boilerplate/internal/dto"
+	httpgateway "go-boilerplate/internal/gateway/http"
 	"go-boilerplate/internal/usecase"
 	"go-boilerplate/pkg/response"
 
@@ -20,7 +22,7 @@ func NewProductHandler(u usecase.ProductUsecase) *ProductHandler {
 
 // ListProducts godoc
 // @Summary      List products from external API
-// @Description  Get list of products from DummyJSON
+// @Description  Get list of products from DummyJSON. Pages are cached: X-Cache is HIT, MISS or STALE, and stale pages carry a Warning header.
 // @Tags         products
 // @Accept       json
 // @Produce      json
@@ -44,11 +46,13 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		}
 	}
 
-	products, total, err := h.usecase.ListProducts(c.Request.Context(), page, limit)
+	ctx, cacheInfo := httpgateway.WithCacheInfo(c.Request.Context())
+	products, total, err := h.usecase.ListProducts(ctx, page, limit)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
+	setCacheHeaders(c, cacheInfo)
 
 	totalPage := int(total) / limit
 	if int(total)%limit != 0 {
@@ -64,3 +68,22 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 
 	response.SuccessWithPagination(c, http.StatusOK, "Product list", products, meta)
 }
+
+// setCacheHeaders tells clients how fresh a cached page is.
+func setCacheHeaders(c *gin.Context, info *httpgateway.CacheInfo) {
+	if info.Result == "" {
+		return
+	}
+	c.Header("X-Cache", info.Result)
+	if info.Result == httpgateway.CacheMiss {
+		return
+	}
+
+	c.Header("Age", strconv.Itoa(int(info.Age.Seconds())))
+	switch {
+	case info.UpstreamFailed:
+		c.Header("Warning", `111 - "Revalidation Failed"`)
+	case info.Result == httpgateway.CacheStale:
+		c.Header("Warning", `110 - "Response is Stale"`)
+	}
+}
@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
+	"go-boilerplate/internal/delivery/http/middleware"
 	"go-boilerplate/internal/dto"
 	"go-boilerplate/internal/usecase"
 	"go-boilerplate/pkg/errors"
@@ -53,7 +58,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary      Login user
-// @Description  Login with email and password to get JWT token
+// @Description  Login with email and password to get JWT token, scoped to organization_id or the oldest membership
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -61,6 +66,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 // @Success      200  {object}  response.Response
 // @Failure      400  {object}  response.Response
 // @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /api/v1/auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
@@ -74,7 +80,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.usecase.Login(ctx, req.Email, req.Password)
+	accessToken, refreshToken, err := h.usecase.Login(ctx, req.Email, req.Password, req.OrganizationID)
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -152,6 +158,124 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	response.SuccessWithPagination(c, http.StatusOK, "User list", userResponses, meta)
 }
 
+// ExportUsers godoc
+// @Summary      Export users
+// @Description  Stream all users matching the list filters as CSV or NDJSON
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        request query dto.ExportUsersRequest true "Export Users Request"
+// @Success      200
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/admin/users/export [get]
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "UserHandler.ExportUsers", "handler")
+	defer span.End()
+
+	var q dto.ExportUsersRequest
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	var w exportWriter
+	switch q.Format {
+	case "", "csv":
+		w = newCSVExportWriter(c)
+	case "ndjson":
+		w = newNDJSONExportWriter(c)
+	default:
+		response.Error(c, errors.New(http.StatusBadRequest, "Unsupported format, use csv or ndjson"))
+		return
+	}
+
+	tz := request.GetTimeLocation(c)
+	err := h.usecase.ExportUsers(ctx, q.ListUsersRequest, tz, w.Write)
+	if err != nil && !w.Started() {
+		response.Error(c, err)
+		return
+	}
+	if err != nil {
+		// Headers are already on the wire, the truncated body is all the client will see
+		c.Error(err)
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		c.Error(err)
+	}
+}
+
+// SearchUsers godoc
+// @Summary      Search users
+// @Description  Fuzzy type-ahead search over email and name, ranked by similarity
+// @Tags         admin
+// @Produce      json
+// @Param        request query dto.SearchUsersRequest true "Search Users Request"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/admin/users/search [get]
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "UserHandler.SearchUsers", "handler")
+	defer span.End()
+
+	var q dto.SearchUsersRequest
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	results, err := h.usecase.SearchUsers(ctx, q)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User search results", results)
+}
+
+// Me godoc
+// @Summary      Get current user profile
+// @Description  Get the profile of the authenticated user, with timestamps in the requested timezone
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/users/me [get]
+func (h *UserHandler) Me(c *gin.Context) {
+	_, span := tracer.StartSpan(c.Request.Context(), "UserHandler.Me", "handler")
+	defer span.End()
+
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		response.Error(c, errors.New(http.StatusUnauthorized, "Current user is not loaded"))
+		return
+	}
+
+	loc, err := time.LoadLocation(request.GetTimeLocation(c))
+	if err != nil {
+		loc = time.UTC
+	}
+
+	response.Success(c, http.StatusOK, "Profile retrieved successfully", dto.ProfileResponse{
+		UserResponse: dto.UserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			CreatedAt: user.CreatedAt.In(loc),
+			UpdatedAt: user.UpdatedAt.In(loc),
+		},
+		Role:           user.Role,
+		OrganizationID: c.GetString("tenantID"),
+	})
+}
+
 // GetUser godoc
 // @Summary      Get a user by ID
 // @Tags         users
@@ -181,6 +305,8 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	userResponse := dto.UserResponse{
 		ID:        user.ID,
 		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
@@ -248,3 +374,194 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, "User deleted successfully", nil)
 }
+
+// exportFlushEvery is the number of rows written between flushes to the client.
+const exportFlushEvery = 500
+
+// exportWriter writes users to the response as they are streamed from the database.
+// Headers are only sent with the first row so errors before that can still become a JSON error.
+type exportWriter interface {
+	Write(user dto.UserResponse) error
+	Started() bool
+	Close() error
+}
+
+type csvExportWriter struct {
+	c       *gin.Context
+	csv     *csv.Writer
+	started bool
+	rows    int
+}
+
+func newCSVExportWriter(c *gin.Context) *csvExportWriter {
+	return &csvExportWriter{c: c, csv: csv.NewWriter(c.Writer)}
+}
+
+func (w *csvExportWriter) start() error {
+	w.started = true
+	writeExportHeaders(w.c, "text/csv; charset=utf-8", "csv")
+	return w.csv.Write([]string{"id", "email", "first_name", "last_name", "created_at", "updated_at"})
+}
+
+func (w *csvExportWriter) Write(user dto.UserResponse) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	err := w.csv.Write([]string{
+		user.ID,
+		user.Email,
+		user.FirstName,
+		user.LastName,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%exportFlushEvery == 0 {
+		w.csv.Flush()
+		w.c.Writer.Flush()
+	}
+	return w.csv.Error()
+}
+
+func (w *csvExportWriter) Started() bool {
+	return w.started
+}
+
+func (w *csvExportWriter) Close() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+type ndjsonExportWriter struct {
+	c       *gin.Context
+	enc     *json.Encoder
+	started bool
+	rows    int
+}
+
+func newNDJSONExportWriter(c *gin.Context) *ndjsonExportWriter {
+	return &ndjsonExportWriter{c: c, enc: json.NewEncoder(c.Writer)}
+}
+
+func (w *ndjsonExportWriter) Write(user dto.UserResponse) error {
+	if !w.started {
+		w.started = true
+		writeExportHeaders(w.c, "application/x-ndjson", "ndjson")
+	}
+
+	// Encode terminates every value with a newline
+	if err := w.enc.Encode(user); err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%exportFlushEvery == 0 {
+		w.c.Writer.Flush()
+	}
+	return nil
+}
+
+func (w *ndjsonExportWriter) Started() bool {
+	return w.started
+}
+
+func (w *ndjsonExportWriter) Close() error {
+	if !w.started {
+		w.started = true
+		writeExportHeaders(w.c, "application/x-ndjson", "ndjson")
+	}
+	return nil
+}
+
+func writeExportHeaders(c *gin.Context, contentType, ext string) {
+	filename := fmt.Sprintf("users-%s.%s", time.Now().UTC().Format("20060102-150405"), ext)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+}
+
+// ChangeRole godoc
+// @Summary      Change a user's role
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Param        request body dto.ChangeRoleRequest true "Change Role Request"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/admin/users/{id}/role [put]
+func (h *UserHandler) ChangeRole(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "UserHandler.ChangeRole", "handler")
+	defer span.End()
+
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.Error(c, errors.New(http.StatusBadRequest, "Invalid User ID"))
+		return
+	}
+
+	var req dto.ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.usecase.ChangeRole(ctx, idStr, req.Role); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User role changed successfully", nil)
+}
+
+// ChangeStatus godoc
+// @Summary      Suspend, ban or reactivate a user
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Param        request body dto.ChangeUserStatusRequest true "Change Status Request"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/admin/users/{id}/status [put]
+func (h *UserHandler) ChangeStatus(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "UserHandler.ChangeStatus", "handler")
+	defer span.End()
+
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.Error(c, errors.New(http.StatusBadRequest, "Invalid User ID"))
+		return
+	}
+
+	var req dto.ChangeUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.usecase.ChangeStatus(ctx, c.GetString("userID"), idStr, req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "User status changed successfully", nil)
+}
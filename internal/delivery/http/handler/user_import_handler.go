@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/response"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserImportHandler struct {
+	usecase usecase.UserImportUsecase
+}
+
+func NewUserImportHandler(u usecase.UserImportUsecase) *UserImportHandler {
+	return &UserImportHandler{usecase: u}
+}
+
+// Import godoc
+// @Summary      Import users from CSV
+// @Description  Upload a CSV file with email and password columns. Rows are imported asynchronously.
+// @Tags         admin
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file formData file true "CSV file"
+// @Success      202  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      413  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/admin/users/import [post]
+func (h *UserImportHandler) Import(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "UserImportHandler.Import", "handler")
+	defer span.End()
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, "CSV file is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, errors.Wrap(err, http.StatusBadRequest, "Failed to read uploaded file"))
+		return
+	}
+	defer file.Close()
+
+	job, err := h.usecase.StartImport(ctx, c.GetString("userID"), fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusAccepted, "Import started", gin.H{"job_id": job.ID})
+}
+
+// GetJob godoc
+// @Summary      Get job status
+// @Description  Get progress and per-row errors of a background job
+// @Tags         admin
+// @Produce      json
+// @Param        id            path   string  true   "Job ID"
+// @Param        errors_limit  query  int     false  "Maximum number of row errors to return" default(100)
+// @Success      200  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Security     BearerAuth
+// @Router       /api/v1/admin/jobs/{id} [get]
+func (h *UserImportHandler) GetJob(c *gin.Context) {
+	ctx, span := tracer.StartSpan(c.Request.Context(), "UserImportHandler.GetJob", "handler")
+	defer span.End()
+
+	var q dto.GetJobRequest
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.Error(c, errors.New(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	job, err := h.usecase.GetJob(ctx, c.Param("id"), q.ErrorsLimit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, "Job retrieved successfully", job)
+}
@@ -5,32 +5,82 @@ import (
 	"strings"
 
 	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/infrastructure/cache"
 	"go-boilerplate/pkg/auth"
+	"go-boilerplate/pkg/logger"
+	"go-boilerplate/pkg/request"
+	"go-boilerplate/pkg/tenant"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-func AuthMiddleware(cfg config.JWTConfig) gin.HandlerFunc {
+// claimsKey holds the access token claims already validated earlier in the request.
+const claimsKey = "tokenClaims"
+
+// AuthMiddleware validates the bearer token and refuses revoked tokens. Revocation markers are
+// read through revocations, so replicas with a local cache layer skip Redis for most requests.
+// Claims already validated by ConcurrencyLimitMiddleware are reused rather than verified again.
+func AuthMiddleware(cfg config.JWTConfig, revocations cache.Cache) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			return
+		claims, ok := validatedClaims(c)
+		if !ok {
+			authHeader := c.GetHeader("Authorization")
+			if authHeader == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+				return
+			}
+
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+				return
+			}
+
+			var err error
+			claims, err = auth.ValidateToken(parts[1], cfg)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				return
+			}
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+		revoked, err := auth.IsRevoked(c.Request.Context(), revocations, claims)
+		if err != nil {
+			// Fail open like the rate limiter, tokens are still short-lived
+			logger.ErrorCtx(c.Request.Context(), "Failed to check token revocation", zap.Error(err))
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
 			return
 		}
 
-		claims, err := auth.ValidateToken(parts[1], cfg)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		// Tokens issued before organizations existed cannot be scoped to a tenant
+		if claims.TenantID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has no organization, please log in again"})
 			return
 		}
 
 		c.Set("userID", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Set("tenantID", claims.TenantID)
+
+		md := request.MetadataFromContext(c.Request.Context())
+		md.ActorID = claims.UserID
+		ctx := request.WithMetadata(c.Request.Context(), md)
+		c.Request = c.Request.WithContext(tenant.WithID(ctx, claims.TenantID))
+
 		c.Next()
 	}
 }
+
+// validatedClaims returns the claims of the bearer token if ConcurrencyLimitMiddleware already
+// validated it for this request.
+func validatedClaims(c *gin.Context) (*auth.Claims, bool) {
+	value, ok := c.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*auth.Claims)
+	return claims, ok
+}
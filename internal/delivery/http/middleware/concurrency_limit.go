@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/pkg/auth"
+	"go-boilerplate/pkg/concurrency"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConcurrencyLimitMiddleware sheds requests with 503 once as many are in flight as its adaptive
+// limit allows. Each call has its own limit: use one per route group, so a slow group does not
+// starve the others. It runs before authentication, so it checks the signature and expiry of the
+// bearer token itself: requests with a valid access token get the share of the limit anonymous
+// requests cannot use. The claims are kept for AuthMiddleware, which still checks revocation.
+func ConcurrencyLimitMiddleware(cfg config.ConcurrencyConfig, jwtCfg config.JWTConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	limiter := concurrency.NewLimiter(concurrency.Options{
+		InitialLimit:     cfg.InitialLimit,
+		MinLimit:         cfg.MinLimit,
+		MaxLimit:         cfg.MaxLimit,
+		LatencyTarget:    cfg.LatencyTarget,
+		Backoff:          cfg.Backoff,
+		LowPriorityShare: cfg.AnonymousShare,
+	})
+	retryAfter := strconv.Itoa(int(math.Ceil(cfg.RetryAfter.Seconds())))
+
+	return func(c *gin.Context) {
+		if slices.Contains(cfg.ExemptRoutes, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		priority := concurrency.PriorityLow
+		if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			if claims, err := auth.ValidateToken(token, jwtCfg); err == nil {
+				c.Set(claimsKey, claims)
+				priority = concurrency.PriorityNormal
+			}
+		}
+
+		release, ok := limiter.Acquire(priority)
+		if !ok {
+			// Not logged here, a log line per shed request would add to the overload
+			c.Header("Retry-After", retryAfter)
+			response.Error(c, appErrors.New(http.StatusServiceUnavailable, "Server is busy, please retry later"))
+			c.Abort()
+			return
+		}
+
+		start := time.Now()
+		defer func() { release(time.Since(start)) }()
+		c.Next()
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ConsistencyHeader carries the master LSN after a write. Clients send it back so their reads
+// see the write, which matters before they are authenticated, such as right after registering.
+const ConsistencyHeader = "X-Consistency-Token"
+
+// LSNReader returns the current master LSN, implemented by database.Database.
+type LSNReader interface {
+	CurrentLSN(ctx context.Context) (database.LSN, error)
+}
+
+// ConsistencyMiddleware gives read-your-writes consistency. Reads of a request only go to
+// replicas that replayed the last write of the caller, taken from ConsistencyHeader and, for
+// authenticated users, from tokens. The header is not checked against the master: a position no
+// replica replayed only sends the reads of that request to the master, which a client could get
+// anyway. After a request that wrote to the master, the master LSN is returned in ConsistencyHeader
+// and stored in tokens for the user for cfg.TTL.
+// It must run after AuthMiddleware on authenticated groups.
+func ConsistencyMiddleware(cfg config.ConsistencyConfig, db LSNReader, tokens cache.Cache) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := c.GetString("userID")
+
+		var required database.LSN
+		if header := c.GetHeader(ConsistencyHeader); header != "" {
+			// A malformed token only costs consistency, it is not worth failing the request
+			if lsn, err := database.ParseLSN(header); err == nil {
+				required = lsn
+			}
+		}
+		if userID != "" {
+			var stored string
+			if _, err := tokens.Get(ctx, userID, &stored); err != nil {
+				logger.WarnCtx(ctx, "Failed to load consistency token", zap.Error(err))
+			} else if lsn, err := database.ParseLSN(stored); err == nil {
+				required = max(required, lsn)
+			}
+		}
+
+		ctx, session := database.WithSession(ctx, required)
+		c.Request = c.Request.WithContext(ctx)
+
+		// The token must be in the headers, which go out with the first byte of the body
+		writer := &consistencyWriter{ResponseWriter: c.Writer}
+		writer.beforeWrite = func() {
+			if !session.Wrote() {
+				return
+			}
+			lsn, err := db.CurrentLSN(ctx)
+			if err != nil {
+				logger.ErrorCtx(ctx, "Failed to record consistency token", zap.Error(err))
+				return
+			}
+			writer.Header().Set(ConsistencyHeader, lsn.String())
+			if userID == "" {
+				return
+			}
+			if err := tokens.Set(ctx, userID, lsn.String(), cfg.TTL); err != nil {
+				logger.ErrorCtx(ctx, "Failed to store consistency token", zap.Error(err))
+			}
+		}
+		c.Writer = writer
+		c.Next()
+		writer.once.Do(writer.beforeWrite)
+	}
+}
+
+// consistencyWriter runs beforeWrite once, right before the headers are sent.
+type consistencyWriter struct {
+	gin.ResponseWriter
+	beforeWrite func()
+	once        sync.Once
+}
+
+func (w *consistencyWriter) WriteHeader(code int) {
+	w.once.Do(w.beforeWrite)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *consistencyWriter) WriteHeaderNow() {
+	w.once.Do(w.beforeWrite)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *consistencyWriter) Write(data []byte) (int, error) {
+	w.once.Do(w.beforeWrite)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *consistencyWriter) WriteString(s string) (int, error) {
+	w.once.Do(w.beforeWrite)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -16,7 +16,9 @@ func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
 	}
 
 	c.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"}
-	c.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"}
+	c.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-RateLimit-Policy", "X-RateLimit-Plan", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", ConsistencyHeader}
+	// Clients read the token after writes to send it back with their next reads
+	c.ExposeHeaders = []string{ConsistencyHeader}
 
 	return cors.New(c)
 }
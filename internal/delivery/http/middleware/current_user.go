@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go-boilerplate/internal/entity"
+	appErrors "go-boilerplate/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const currentUserKey = "currentUser"
+
+// CurrentUserLoader loads the authenticated user, implemented by usecase.UserUsecase.
+type CurrentUserLoader interface {
+	GetCurrentUser(ctx context.Context, id string) (*entity.User, error)
+}
+
+// CurrentUserMiddleware loads the user behind the token and rejects accounts that no longer exist
+// or are suspended, passing on the error reason so clients can tell the cases apart.
+// It must run after AuthMiddleware. Handlers read the user with CurrentUser.
+func CurrentUserMiddleware(loader CurrentUserLoader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := loader.GetCurrentUser(c.Request.Context(), c.GetString("userID"))
+		if err != nil {
+			status, body := http.StatusInternalServerError, gin.H{"error": "Failed to load current user"}
+			var customErr *appErrors.CustomError
+			if errors.As(err, &customErr) {
+				status, body = customErr.Code, gin.H{"error": customErr.Message}
+				if customErr.Reason != "" {
+					body["reason"] = customErr.Reason
+				}
+			}
+			c.AbortWithStatusJSON(status, body)
+			return
+		}
+
+		c.Set(currentUserKey, user)
+		// The stored role wins over the token, so RequireRole sees demotions before the token expires
+		c.Set("role", user.Role)
+		c.Next()
+	}
+}
+
+// CurrentUser returns the user loaded by CurrentUserMiddleware.
+func CurrentUser(c *gin.Context) (*entity.User, bool) {
+	value, ok := c.Get(currentUserKey)
+	if !ok {
+		return nil, false
+	}
+	user, ok := value.(*entity.User)
+	return user, ok
+}
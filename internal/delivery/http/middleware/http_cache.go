@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// cachedResponse is a successful response stored for shared routes.
+type cachedResponse struct {
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// HTTPCacheMiddleware adds strong ETags and Cache-Control to the GET routes configured in cfg and
+// answers matching If-None-Match requests with 304. Shared routes also have their successful
+// responses cached in store. It buffers the response, so it must not wrap streaming routes, and
+// it must run after authentication so shared responses are never served to anonymous callers.
+func HTTPCacheMiddleware(cfg config.HTTPCacheConfig, store cache.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		control, ok := cfg.Control[c.FullPath()]
+		if !ok || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		shared := slices.Contains(cfg.SharedRoutes, c.FullPath())
+		key := sharedCacheKey(c.Request, cfg.Vary)
+		if len(cfg.Vary) > 0 {
+			c.Writer.Header().Add("Vary", strings.Join(cfg.Vary, ", "))
+		}
+
+		if shared {
+			var cached cachedResponse
+			found, err := store.Get(c.Request.Context(), key, &cached)
+			if err != nil {
+				logger.ErrorCtx(c.Request.Context(), "Failed to read cached response", zap.Error(err))
+			}
+			if found {
+				c.Header("X-Cache", "HIT")
+				writeCacheable(c, control, http.StatusOK, cached.ContentType, cached.Body)
+				c.Abort()
+				return
+			}
+			c.Header("X-Cache", "MISS")
+		}
+
+		original := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		if buffered.status != http.StatusOK {
+			c.Header("Cache-Control", "no-store")
+			original.WriteHeader(buffered.status)
+			original.Write(buffered.body.Bytes())
+			return
+		}
+
+		body := buffered.body.Bytes()
+		// Stale data is served as a fallback, sharing it would keep it around longer
+		if shared && original.Header().Get("Warning") == "" {
+			entry := cachedResponse{ContentType: original.Header().Get("Content-Type"), Body: body}
+			if err := store.Set(c.Request.Context(), key, entry, cfg.SharedTTL); err != nil {
+				logger.ErrorCtx(c.Request.Context(), "Failed to cache response", zap.Error(err))
+			}
+		}
+		writeCacheable(c, control, http.StatusOK, "", body)
+	}
+}
+
+// writeCacheable writes body with its ETag, or 304 when the client already has it.
+func writeCacheable(c *gin.Context, control string, status int, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", control)
+	if contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Writer.WriteHeader(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Writer.WriteHeader(status)
+	c.Writer.Write(body)
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedCacheKey identifies a response by path, query and the values of the vary headers.
+// Query parameters are sorted so their order does not split the cache.
+func sharedCacheKey(r *http.Request, vary []string) string {
+	h := sha256.New()
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(url.Values(r.URL.Query()).Encode()))
+	for _, name := range vary {
+		h.Write([]byte{0})
+		h.Write([]byte(r.Header.Get(name)))
+	}
+	return "http:response:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// bufferedWriter holds the response back until the middleware decides how to send it.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"go-boilerplate/pkg/request"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMetadataMiddleware stores the client IP and user agent in the request context
+// so lower layers can attribute their work, e.g. in audit events.
+func RequestMetadataMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := request.WithMetadata(c.Request.Context(), request.Metadata{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
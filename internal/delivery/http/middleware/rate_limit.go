@@ -1,56 +1,101 @@
 package middleware
 
 import (
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
-	"go-boilerplate/internal/config"
-	"go-boilerplate/internal/infrastructure/redis"
+	"go-boilerplate/internal/infrastructure/ratelimit"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/logger"
 	"go-boilerplate/pkg/response"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-func RateLimitMiddleware(rdb *redis.Client, cfg config.RateLimitConfig) gin.HandlerFunc {
+// APIKeyHeader identifies API clients for rate limit policies counted by api_key.
+const APIKeyHeader = "X-API-Key"
+
+// RateLimitMiddleware applies the policy matching the route to the identity it counts by. Place
+// it after AuthMiddleware on authenticated routes, otherwise user and tenant policies fall back
+// to counting by IP.
+func RateLimitMiddleware(limiter ratelimit.Limiter, policies *ratelimit.Policies) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		key := fmt.Sprintf("rate_limit:%s", ip)
+		policy := policies.Match(c.Request.Method, c.FullPath())
+		identities := requestIdentities(c, policies)
+		counted := countedIdentity(policy, identities)
+		c.Header("X-RateLimit-Policy", policy.Name)
 
-		// Increment the counter
-		count, err := rdb.Incr(c.Request.Context(), key).Result()
-		if err != nil {
-			// Fail open if Redis is down, or log and fail closed depending on requirements
-			// For now, let's log and proceed to avoid blocking users due to infra issues
-			// In a strict environment, might want to return 500
+		if policies.IsExempt(identities...) {
 			c.Next()
 			return
 		}
 
-		// If this is the first request, set expiration
-		if count == 1 {
-			rdb.Expire(c.Request.Context(), key, time.Duration(cfg.Window)*time.Second)
+		limit, plan := policies.LimitFor(policy, identities...)
+		result, err := limiter.Allow(c.Request.Context(), policy.Name+":"+counted, limit)
+		if err != nil {
+			// Failures the limiter does not absorb itself mean it is configured to fail closed
+			logger.ErrorCtx(c.Request.Context(), "Rate limiter unavailable, rejecting request", zap.Error(err))
+			response.Error(c, appErrors.New(http.StatusServiceUnavailable, "Service temporarily unavailable"))
+			c.Abort()
+			return
+		}
+		if result.Limit == 0 {
+			// Failing open, no limit was applied
+			c.Next()
+			return
 		}
 
-		// Check limit
-		if count > int64(cfg.Limit) {
-			// Get TTL to set Retry-After header
-			ttl, _ := rdb.TTL(c.Request.Context(), key).Result()
-
-			c.Header("X-RateLimit-Limit", strconv.Itoa(cfg.Limit))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
-			c.Header("Retry-After", strconv.FormatInt(int64(ttl.Seconds()), 10))
+		if plan != "" {
+			c.Header("X-RateLimit-Plan", plan)
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(result.ResetAfter).Unix(), 10))
 
-			response.Error(c, fmt.Errorf("too many requests"))
-			c.AbortWithStatus(http.StatusTooManyRequests)
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+			response.Error(c, appErrors.New(http.StatusTooManyRequests, "Too many requests"))
+			c.Abort()
 			return
 		}
 
-		c.Header("X-RateLimit-Limit", strconv.Itoa(cfg.Limit))
-		c.Header("X-RateLimit-Remaining", strconv.FormatInt(int64(cfg.Limit)-count, 10))
-
 		c.Next()
 	}
 }
+
+// requestIdentities lists every identity of the request as "<identity>:<value>", the most
+// specific first. The IP is always last. API keys are not verified, so only the keys configured in
+// policies count; a client sending a new random key on each request is counted by IP.
+func requestIdentities(c *gin.Context, policies *ratelimit.Policies) []string {
+	var identities []string
+	if userID := c.GetString("userID"); userID != "" {
+		identities = append(identities, ratelimit.IdentityUser+":"+userID)
+	}
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		// Keys are referenced by their SHA-256, so they never appear in config or Redis
+		sum := sha256.Sum256([]byte(key))
+		if identity := ratelimit.IdentityAPIKey + ":" + hex.EncodeToString(sum[:]); policies.IsKnown(identity) {
+			identities = append(identities, identity)
+		}
+	}
+	if tenantID := c.GetString("tenantID"); tenantID != "" {
+		identities = append(identities, ratelimit.IdentityTenant+":"+tenantID)
+	}
+	return append(identities, ratelimit.IdentityIP+":"+c.ClientIP())
+}
+
+// countedIdentity returns the identity policy counts by, or the IP when the request has none.
+func countedIdentity(policy ratelimit.Policy, identities []string) string {
+	for _, identity := range identities {
+		if strings.HasPrefix(identity, policy.Identity+":") {
+			return identity
+		}
+	}
+	return identities[len(identities)-1]
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"go-boilerplate/internal/entity"
+	appErrors "go-boilerplate/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets through requests whose user holds one of the given global roles.
+// It must be registered after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	}
+}
+
+// MembershipLoader loads the caller's membership in the tenant of ctx, implemented by
+// usecase.OrganizationUsecase.
+type MembershipLoader interface {
+	CurrentMembership(ctx context.Context, userID string) (*entity.Membership, error)
+}
+
+// RequireMembershipRole only lets through requests whose user holds one of the given roles in the
+// organization of the token. Use it for routes scoped to that organization, the global role says
+// nothing about it. It must be registered after AuthMiddleware.
+func RequireMembershipRole(loader MembershipLoader, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		membership, err := loader.CurrentMembership(c.Request.Context(), c.GetString("userID"))
+		if err != nil {
+			status, message := http.StatusInternalServerError, "Failed to load membership"
+			var customErr *appErrors.CustomError
+			if errors.As(err, &customErr) {
+				status, message = customErr.Code, customErr.Message
+			}
+			c.AbortWithStatusJSON(status, gin.H{"error": message})
+			return
+		}
+		if !slices.Contains(roles, membership.Role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient organization role"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,14 +1,11 @@
 package http
 
 import (
-	"net/http"
-
 	"go-boilerplate/docs"
 	"go-boilerplate/internal/config"
 	"go-boilerplate/internal/container"
 	"go-boilerplate/internal/delivery/http/middleware"
-
-	"go-boilerplate/internal/infrastructure/redis"
+	"go-boilerplate/internal/entity"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -18,7 +15,6 @@ import (
 
 func NewRouter(
 	cfg *config.Config,
-	rdb *redis.Client,
 	c *container.Container,
 ) *gin.Engine {
 	// Extract handlers from container
@@ -26,6 +22,18 @@ func NewRouter(
 	healthHandler := c.HealthHandler
 	productHandler := c.ProductHandler
 	paymentHandler := c.PaymentHandler
+	userImportHandler := c.UserImportHandler
+	auditHandler := c.AuditHandler
+	privacyHandler := c.PrivacyHandler
+	organizationHandler := c.OrganizationHandler
+	invitationHandler := c.InvitationHandler
+	currentUser := middleware.CurrentUserMiddleware(c.UserUsecase)
+	httpCache := middleware.HTTPCacheMiddleware(cfg.HTTPCache, c.ResponseCache)
+	// Runs after AuthMiddleware on authenticated groups, so policies can count by user or tenant
+	rateLimit := middleware.RateLimitMiddleware(c.RateLimiter, c.RateLimitPolicies)
+	auth := middleware.AuthMiddleware(cfg.JWT, c.Revocations)
+	// Runs before CurrentUserMiddleware, so loading the user also sees the caller's last write
+	consistency := middleware.ConsistencyMiddleware(cfg.Consistency, c.DB, c.ConsistencyTokens)
 	// Gin Mode
 	if cfg.App.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -41,6 +49,7 @@ func NewRouter(
 	r.Use(middleware.LoggerMiddleware())
 	r.Use(middleware.RecoveryMiddleware())
 	r.Use(middleware.CORSMiddleware(cfg.CORS))
+	r.Use(middleware.RequestMetadataMiddleware())
 
 	// Swagger
 	docs.SwaggerInfo.BasePath = "/" // Fix fetch error
@@ -49,44 +58,74 @@ func NewRouter(
 		ginSwagger.DefaultModelsExpandDepth(-1),
 	))
 
-	// Health Check
+	// Health Check, never shed by the concurrency limits of the groups below
 	r.GET("/health", healthHandler.Check)
 
 	api := r.Group("/api/v1")
-	api.Use(middleware.RateLimitMiddleware(rdb, cfg.RateLimit))
 	{
-		auth := api.Group("/auth")
+		authentication := api.Group("/auth")
+		authentication.Use(middleware.ConcurrencyLimitMiddleware(cfg.Concurrency, cfg.JWT), rateLimit, consistency)
 		{
-			auth.POST("/register", userHandler.Register)
-			auth.POST("/login", userHandler.Login)
-			auth.POST("/refresh", userHandler.RefreshToken)
+			authentication.POST("/register", userHandler.Register)
+			authentication.POST("/login", userHandler.Login)
+			authentication.POST("/refresh", userHandler.RefreshToken)
+			authentication.POST("/invitations/accept", invitationHandler.Accept)
 		}
 
 		user := api.Group("/users")
-		user.Use(middleware.AuthMiddleware(cfg.JWT))
+		user.Use(middleware.ConcurrencyLimitMiddleware(cfg.Concurrency, cfg.JWT), auth, rateLimit, consistency, currentUser, httpCache)
 		{
 			user.GET("", userHandler.ListUsers) // GET /api/v1/users
 			user.GET("/:id", userHandler.GetUser)
 			user.PUT("/:id", userHandler.UpdateUser)
 			user.DELETE("/:id", userHandler.DeleteUser)
-			user.GET("/me", func(c *gin.Context) {
-				// Example protected route
-				userID, _ := c.Get("userID")
-				c.JSON(http.StatusOK, gin.H{"user_id": userID})
-			})
+			user.GET("/me", userHandler.Me)
+			user.GET("/me/export", privacyHandler.ExportMyData)
+			user.DELETE("/me", privacyHandler.EraseMe)
+		}
+
+		organization := api.Group("/organizations")
+		organization.Use(middleware.ConcurrencyLimitMiddleware(cfg.Concurrency, cfg.JWT), auth, rateLimit, consistency, currentUser)
+		{
+			organization.GET("", organizationHandler.ListOrganizations)
+			organization.POST("", organizationHandler.CreateOrganization)
+			organization.GET("/:id/members", organizationHandler.ListMembers)
+			organization.DELETE("/:id/members/:userId", organizationHandler.RemoveMember)
+			organization.POST("/:id/invitations", invitationHandler.Invite)
+			organization.GET("/:id/invitations", invitationHandler.ListInvitations)
+			organization.POST("/:id/invitations/:invitationId/resend", invitationHandler.Resend)
+			organization.DELETE("/:id/invitations/:invitationId", invitationHandler.Revoke)
 		}
 
 		product := api.Group("/products")
-		product.Use(middleware.AuthMiddleware(cfg.JWT))
+		product.Use(middleware.ConcurrencyLimitMiddleware(cfg.Concurrency, cfg.JWT), auth, rateLimit, currentUser, httpCache)
 		{
 			product.GET("", productHandler.ListProducts)
 		}
 
 		// Payment (gRPC)
 		payment := api.Group("/payments")
+		payment.Use(middleware.ConcurrencyLimitMiddleware(cfg.Concurrency, cfg.JWT), rateLimit)
 		{
 			payment.GET("/:id", paymentHandler.CheckStatus)
 		}
+
+		admin := api.Group("/admin")
+		admin.Use(middleware.ConcurrencyLimitMiddleware(cfg.Concurrency, cfg.JWT), auth, rateLimit, consistency, currentUser)
+		// Data of the organization in the token, managed by its owners and admins
+		orgAdmin := middleware.RequireMembershipRole(c.OrganizationUsecase, entity.MembershipRoleOwner, entity.MembershipRoleAdmin)
+		// Role and status are global, an admin of one organization must not change them for the others
+		platformAdmin := middleware.RequireRole(entity.RolePlatformAdmin)
+		{
+			admin.POST("/users/import", orgAdmin, userImportHandler.Import)
+			admin.GET("/users/export", orgAdmin, userHandler.ExportUsers)
+			admin.GET("/users/search", orgAdmin, userHandler.SearchUsers)
+			admin.PUT("/users/:id/role", platformAdmin, userHandler.ChangeRole)
+			admin.PUT("/users/:id/status", platformAdmin, userHandler.ChangeStatus)
+			admin.GET("/jobs/:id", orgAdmin, userImportHandler.GetJob)
+			admin.GET("/jobs/:id/certificate", orgAdmin, privacyHandler.GetErasureCertificate)
+			admin.GET("/audit", orgAdmin, auditHandler.ListEvents)
+		}
 	}
 
 	return r
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go-boilerplate/internal/infrastructure/mailer"
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+)
+
+type MailWorker struct {
+	mailer mailer.Mailer
+}
+
+func NewMailWorker(m mailer.Mailer) *MailWorker {
+	return &MailWorker{mailer: m}
+}
+
+// Handle delivers a single message from the mail queue.
+func (w *MailWorker) Handle(ctx context.Context, body []byte) error {
+	var msg mailer.Message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return rabbitmq.Permanent(fmt.Errorf("invalid mail message: %w", err))
+	}
+	return w.mailer.Send(ctx, msg)
+}
@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// outboxPurgeInterval is how often sent events past their retention are deleted.
+const outboxPurgeInterval = time.Hour
+
+// OutboxRelayWorker periodically publishes the pending outbox events to RabbitMQ.
+type OutboxRelayWorker struct {
+	usecase   usecase.OutboxUsecase
+	interval  time.Duration
+	batchSize int
+}
+
+func NewOutboxRelayWorker(u usecase.OutboxUsecase, interval time.Duration, batchSize int) *OutboxRelayWorker {
+	return &OutboxRelayWorker{usecase: u, interval: interval, batchSize: batchSize}
+}
+
+// Run relays pending events every interval until ctx is cancelled. A full batch is followed by
+// the next one right away, so a backlog drains without waiting for the ticks. Failures are logged
+// and retried on the next tick.
+func (w *OutboxRelayWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(outboxPurgeInterval)
+	defer purgeTicker.Stop()
+
+	w.purge(ctx)
+	for {
+		if w.relay(ctx) && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-purgeTicker.C:
+			w.purge(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publishes one batch and reports whether more events may be pending.
+func (w *OutboxRelayWorker) relay(ctx context.Context) bool {
+	sent, err := w.usecase.Relay(ctx)
+	if sent > 0 {
+		logger.InfoCtx(ctx, "Relayed outbox events", zap.Int("count", sent))
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to relay outbox events", zap.Error(err))
+		return false
+	}
+	return sent == w.batchSize
+}
+
+func (w *OutboxRelayWorker) purge(ctx context.Context) {
+	purged, err := w.usecase.PurgeSent(ctx)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to purge sent outbox events", zap.Error(err))
+		return
+	}
+	if purged > 0 {
+		logger.InfoCtx(ctx, "Purged sent outbox events", zap.Int64("count", purged))
+	}
+}
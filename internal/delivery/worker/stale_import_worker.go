@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// StaleImportWorker periodically requeues import jobs left in processing by a worker that died.
+type StaleImportWorker struct {
+	usecase  usecase.UserImportUsecase
+	interval time.Duration
+}
+
+func NewStaleImportWorker(u usecase.UserImportUsecase, interval time.Duration) *StaleImportWorker {
+	return &StaleImportWorker{usecase: u, interval: interval}
+}
+
+// Run requeues stale import jobs every interval until ctx is cancelled. Failures are logged
+// and retried on the next tick.
+func (w *StaleImportWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.requeue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *StaleImportWorker) requeue(ctx context.Context) {
+	requeued, err := w.usecase.RequeueStale(ctx)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to requeue stale import jobs", zap.Error(err))
+	}
+	if requeued > 0 {
+		logger.InfoCtx(ctx, "Requeued stale import jobs", zap.Int("count", requeued))
+	}
+}
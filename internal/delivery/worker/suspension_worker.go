@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// SuspensionWorker periodically reactivates users whose suspension has expired.
+type SuspensionWorker struct {
+	usecase  usecase.UserUsecase
+	interval time.Duration
+}
+
+func NewSuspensionWorker(u usecase.UserUsecase, interval time.Duration) *SuspensionWorker {
+	return &SuspensionWorker{usecase: u, interval: interval}
+}
+
+// Run lifts expired suspensions every interval until ctx is cancelled. Failures are logged
+// and retried on the next tick.
+func (w *SuspensionWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.lift(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *SuspensionWorker) lift(ctx context.Context) {
+	lifted, err := w.usecase.LiftExpiredSuspensions(ctx)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to lift expired suspensions", zap.Error(err))
+		return
+	}
+	if lifted > 0 {
+		logger.InfoCtx(ctx, "Lifted expired suspensions", zap.Int("count", lifted))
+	}
+}
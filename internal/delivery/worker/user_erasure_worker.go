@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+	"go-boilerplate/internal/usecase"
+)
+
+type UserErasureWorker struct {
+	usecase usecase.PrivacyUsecase
+}
+
+func NewUserErasureWorker(u usecase.PrivacyUsecase) *UserErasureWorker {
+	return &UserErasureWorker{usecase: u}
+}
+
+// Handle processes a single message from the erasure queue.
+func (w *UserErasureWorker) Handle(ctx context.Context, body []byte) error {
+	var msg usecase.ErasureMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return rabbitmq.Permanent(fmt.Errorf("invalid erasure message: %w", err))
+	}
+	return w.usecase.ProcessErasure(ctx, msg.JobID)
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+	"go-boilerplate/internal/usecase"
+)
+
+type UserImportWorker struct {
+	usecase usecase.UserImportUsecase
+}
+
+func NewUserImportWorker(u usecase.UserImportUsecase) *UserImportWorker {
+	return &UserImportWorker{usecase: u}
+}
+
+// Handle processes a single message from the import queue.
+func (w *UserImportWorker) Handle(ctx context.Context, body []byte) error {
+	var msg usecase.ImportMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return rabbitmq.Permanent(fmt.Errorf("invalid import message: %w", err))
+	}
+	return w.usecase.ProcessImport(ctx, msg.JobID)
+}
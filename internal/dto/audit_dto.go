@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type ListAuditEventsRequest struct {
+	// filters
+	ActorID      string    `form:"actor_id"`
+	Action       string    `form:"action"`
+	ResourceType string    `form:"resource_type"`
+	ResourceID   string    `form:"resource_id"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+
+	// pagination
+	Page  int `form:"page"`
+	Limit int `form:"limit"`
+}
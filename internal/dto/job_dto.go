@@ -0,0 +1,12 @@
+package dto
+
+import "go-boilerplate/internal/entity"
+
+type JobResponse struct {
+	entity.Job
+	Errors []entity.JobError `json:"errors"`
+}
+
+type GetJobRequest struct {
+	ErrorsLimit int `form:"errors_limit"`
+}
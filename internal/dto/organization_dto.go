@@ -0,0 +1,22 @@
+package dto
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=admin member"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+	// Only needed when no account exists yet for the invited email
+	Password string `json:"password" binding:"omitempty,min=6"`
+}
+
+type AcceptInvitationResponse struct {
+	OrganizationID string `json:"organization_id"`
+	UserID         string `json:"user_id"`
+	AccountCreated bool   `json:"account_created"`
+}
@@ -5,18 +5,28 @@ import "time"
 type UserResponse struct {
 	ID        string    `json:"id"`
 	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// ProfileResponse is the current user's own profile.
+type ProfileResponse struct {
+	UserResponse
+	Role           string `json:"role"`
+	OrganizationID string `json:"organization_id"`
+}
+
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email          string `json:"email" binding:"required,email"`
+	Password       string `json:"password" binding:"required"`
+	OrganizationID string `json:"organization_id" binding:"omitempty,uuid"`
 }
 
 type RefreshTokenRequest struct {
@@ -32,3 +42,33 @@ type ListUsersRequest struct {
 	Limit int    `form:"limit"`
 	Order string `form:"order"`
 }
+
+type ExportUsersRequest struct {
+	ListUsersRequest
+
+	// csv (default) or ndjson
+	Format string `form:"format"`
+}
+
+type SearchUsersRequest struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit"`
+}
+
+type UserSearchResult struct {
+	UserResponse
+	Score float64 `json:"score"`
+
+	// Matched fields with the matching part wrapped in <mark></mark>, HTML-escaped otherwise
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin platform_admin"`
+}
+
+type ChangeUserStatusRequest struct {
+	Status    string     `json:"status" binding:"required,oneof=active suspended banned"`
+	Reason    string     `json:"reason" binding:"max=500"`
+	ExpiresAt *time.Time `json:"expires_at"` // suspensions only, omit for an indefinite suspension
+}
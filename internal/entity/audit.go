@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	AuditActionUserRegister     = "user.register"
+	AuditActionUserUpdate       = "user.update"
+	AuditActionUserDelete       = "user.delete"
+	AuditActionUserRoleChange   = "user.role_change"
+	AuditActionUserExport       = "user.export"
+	AuditActionUserErase        = "user.erase"
+	AuditActionUserStatusChange = "user.status_change"
+
+	AuditActionOrganizationCreate = "organization.create"
+	AuditActionMemberRemove       = "organization.member_remove"
+	AuditActionInvitationCreate   = "invitation.create"
+	AuditActionInvitationResend   = "invitation.resend"
+	AuditActionInvitationRevoke   = "invitation.revoke"
+	AuditActionInvitationAccept   = "invitation.accept"
+
+	AuditResourceUser         = "user"
+	AuditResourceOrganization = "organization"
+	AuditResourceInvitation   = "invitation"
+)
+
+// AuditChange holds the value of a single field before and after a mutation.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditEvent struct {
+	ID           string                 `json:"id"`
+	TenantID     string                 `json:"tenant_id,omitempty"`
+	ActorID      string                 `json:"actor_id,omitempty"`
+	Action       string                 `json:"action"`
+	ResourceType string                 `json:"resource_type"`
+	ResourceID   string                 `json:"resource_id"`
+	Changes      map[string]AuditChange `json:"changes"`
+	IP           string                 `json:"ip"`
+	UserAgent    string                 `json:"user_agent"`
+	TraceID      string                 `json:"trace_id,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
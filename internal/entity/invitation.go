@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+)
+
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"
+	InvitationStatusAccepted InvitationStatus = "accepted"
+	InvitationStatusRevoked  InvitationStatus = "revoked"
+)
+
+// Invitation asks someone to join an organization. Only the hash of the latest emailed token is kept,
+// so resending an invitation invalidates earlier links.
+type Invitation struct {
+	ID             string           `json:"id"`
+	OrganizationID string           `json:"organization_id"`
+	Email          string           `json:"email"`
+	Role           string           `json:"role"`
+	Status         InvitationStatus `json:"status"`
+	TokenHash      string           `json:"-"`
+	InvitedBy      string           `json:"invited_by,omitempty"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+	AcceptedAt     *time.Time       `json:"accepted_at,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// Expired reports whether the invitation can no longer be accepted.
+func (i *Invitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
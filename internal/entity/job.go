@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"time"
+)
+
+type JobType string
+
+const (
+	JobTypeUserImport  JobType = "user_import"
+	JobTypeUserErasure JobType = "user_erasure"
+)
+
+type JobStatus string
+
+const (
+	JobStatusPending    JobStatus = "pending"
+	JobStatusProcessing JobStatus = "processing"
+	JobStatusCompleted  JobStatus = "completed"
+	JobStatusFailed     JobStatus = "failed"
+)
+
+// Job tracks the progress of a long-running background task such as a bulk import or an erasure.
+type Job struct {
+	ID            string     `json:"id"`
+	TenantID      string     `json:"tenant_id,omitempty"`
+	Type          JobType    `json:"type"`
+	Status        JobStatus  `json:"status"`
+	ObjectKey     string     `json:"-"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	SucceededRows int        `json:"succeeded_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	Error         string     `json:"error,omitempty"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// JobError records a single row that could not be processed by a job.
+type JobError struct {
+	JobID   string `json:"-"`
+	Row     int    `json:"row"`
+	Email   string `json:"email,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	MembershipRoleOwner  = "owner"
+	MembershipRoleAdmin  = "admin"
+	MembershipRoleMember = "member"
+)
+
+// Organization is a tenant. Every tenant-scoped row belongs to exactly one organization.
+type Organization struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Membership links a user to an organization. A user can belong to several organizations.
+type Membership struct {
+	OrganizationID   string    `json:"organization_id"`
+	OrganizationName string    `json:"organization_name,omitempty"`
+	UserID           string    `json:"user_id"`
+	UserEmail        string    `json:"user_email,omitempty"`
+	Role             string    `json:"role"`
+	CreatedAt        time.Time `json:"created_at"`
+}
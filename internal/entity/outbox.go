@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Domain event types, also used as routing keys on the events exchange.
+const (
+	EventUserRegistered = "user.registered"
+	EventUserDeleted    = "user.deleted"
+)
+
+// OutboxEvent is a domain event stored in the transaction of the change that caused it, so it is
+// published if and only if the change commits.
+type OutboxEvent struct {
+	ID            string          `json:"id"`
+	TenantID      string          `json:"tenant_id,omitempty"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	TraceID       string          `json:"trace_id,omitempty"`
+	Attempts      int             `json:"-"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// UserEvent is the payload of the user events.
+type UserEvent struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	ErasureStepDeleteInvitations = "delete_invitations"
+	ErasureStepRemoveMemberships = "remove_memberships"
+	ErasureStepAnonymizeProfile  = "anonymize_profile"
+	ErasureStepRedactAuditTrail  = "redact_audit_trail"
+	ErasureStepDeleteExports     = "delete_exports"
+	ErasureStepRevokeTokens      = "revoke_tokens"
+	ErasureStepPurgeCache        = "purge_cache"
+)
+
+// ErasureStep records when a single part of an erasure finished.
+type ErasureStep struct {
+	Name        string    `json:"name"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// ErasureCertificate is the proof of completion stored when an erasure job finishes.
+// Digest is the SHA-256 of the certificate encoded with an empty digest.
+type ErasureCertificate struct {
+	JobID       string        `json:"job_id"`
+	SubjectID   string        `json:"subject_id"`
+	RequestedAt time.Time     `json:"requested_at"`
+	CompletedAt time.Time     `json:"completed_at"`
+	Steps       []ErasureStep `json:"steps"`
+	Digest      string        `json:"digest"`
+}
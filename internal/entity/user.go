@@ -4,11 +4,52 @@ import (
 	"time"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// RolePlatformAdmin may also change the role and status of accounts, which apply in every
+	// organization the user belongs to
+	RolePlatformAdmin = "platform_admin"
+)
+
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusBanned    UserStatus = "banned"
+)
+
 type User struct {
 	ID        string     `json:"id"`
 	Email     string     `json:"email"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
 	Password  string     `json:"-"`
+	Role      string     `json:"role"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"-"`
+
+	Status          UserStatus `json:"status"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusExpiresAt *time.Time `json:"status_expires_at,omitempty"` // only for suspensions, nil means indefinite
+}
+
+// Blocked reports whether the account may not sign in or make requests at now.
+// A suspension past its expiry no longer blocks, even before the scheduled job lifts it.
+func (u *User) Blocked(now time.Time) bool {
+	switch u.Status {
+	case UserStatusBanned:
+		return true
+	case UserStatusSuspended:
+		return u.StatusExpiresAt == nil || now.Before(*u.StatusExpiresAt)
+	}
+	return false
+}
+
+// UserSearchResult is a user matched by fuzzy search together with its relevance score.
+type UserSearchResult struct {
+	User
+	Score float64
 }
@@ -0,0 +1,140 @@
+package httpgateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/pkg/logger"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// productCacheTag is set on every cached page, invalidating it drops the whole catalog.
+const productCacheTag = "product"
+
+// Cache results reported through CacheInfo, also used as X-Cache header values.
+const (
+	CacheHit   = "HIT"
+	CacheMiss  = "MISS"
+	CacheStale = "STALE"
+)
+
+// CacheInfo describes how a cached gateway answered a call.
+type CacheInfo struct {
+	Result string
+	// Age of the served page
+	Age time.Duration
+	// UpstreamFailed is set when a stale page was served because the catalog could not be reached
+	UpstreamFailed bool
+}
+
+type cacheInfoKey struct{}
+
+// WithCacheInfo returns a context in which cached gateways record how they answered into the
+// returned CacheInfo.
+func WithCacheInfo(ctx context.Context) (context.Context, *CacheInfo) {
+	info := &CacheInfo{}
+	return context.WithValue(ctx, cacheInfoKey{}, info), info
+}
+
+func recordCacheInfo(ctx context.Context, info CacheInfo) {
+	if dest, ok := ctx.Value(cacheInfoKey{}).(*CacheInfo); ok {
+		*dest = info
+	}
+}
+
+type productPage struct {
+	Products  []entity.Product `json:"products"`
+	Total     int64            `json:"total"`
+	FetchedAt time.Time        `json:"fetched_at"`
+}
+
+type cachedProductGateway struct {
+	next  ProductGateway
+	cache cache.Cache
+	cfg   config.ProductConfig
+	group singleflight.Group
+}
+
+// NewCachedProductGateway caches the pages of next with stale-while-revalidate. Fresh pages are
+// served from the cache, stale pages are served right away and refreshed in the background, and
+// the last known page is served while the catalog is down.
+func NewCachedProductGateway(next ProductGateway, c cache.Cache, cfg config.ProductConfig) ProductGateway {
+	return &cachedProductGateway{next: next, cache: c, cfg: cfg}
+}
+
+func (g *cachedProductGateway) GetProducts(ctx context.Context, limit, skip int) ([]entity.Product, int64, error) {
+	key := fmt.Sprintf("product:page:%d:%d", limit, skip)
+
+	var page productPage
+	found, err := g.cache.Get(ctx, key, &page)
+	if err != nil {
+		logger.WarnCtx(ctx, "Product cache read failed, calling the catalog", zap.String("key", key), zap.Error(err))
+		found = false
+	}
+
+	if found {
+		age := time.Since(page.FetchedAt)
+		switch {
+		case age < g.cfg.FreshTTL:
+			recordCacheInfo(ctx, CacheInfo{Result: CacheHit, Age: age})
+			return page.Products, page.Total, nil
+		case age < g.cfg.FreshTTL+g.cfg.StaleTTL:
+			g.refreshInBackground(ctx, key, limit, skip)
+			recordCacheInfo(ctx, CacheInfo{Result: CacheStale, Age: age})
+			return page.Products, page.Total, nil
+		}
+	}
+
+	// The shared load must not be cancelled when only the first caller goes away
+	fetched, err := g.load(context.WithoutCancel(ctx), key, limit, skip)
+	if err != nil {
+		if !found {
+			return nil, 0, err
+		}
+		logger.WarnCtx(ctx, "Catalog unavailable, serving the last known page", zap.String("key", key), zap.Error(err))
+		recordCacheInfo(ctx, CacheInfo{Result: CacheStale, Age: time.Since(page.FetchedAt), UpstreamFailed: true})
+		return page.Products, page.Total, nil
+	}
+
+	recordCacheInfo(ctx, CacheInfo{Result: CacheMiss})
+	return fetched.Products, fetched.Total, nil
+}
+
+// load fetches a page and stores it. Concurrent loads of the same page share one upstream call.
+func (g *cachedProductGateway) load(ctx context.Context, key string, limit, skip int) (productPage, error) {
+	page, err, _ := g.group.Do(key, func() (any, error) {
+		products, total, err := g.next.GetProducts(ctx, limit, skip)
+		if err != nil {
+			return productPage{}, err
+		}
+
+		page := productPage{Products: products, Total: total, FetchedAt: time.Now()}
+		if err := g.cache.Set(ctx, key, page, g.retention(), productCacheTag); err != nil {
+			logger.WarnCtx(ctx, "Product cache write failed", zap.String("key", key), zap.Error(err))
+		}
+		return page, nil
+	})
+	return page.(productPage), err
+}
+
+func (g *cachedProductGateway) refreshInBackground(ctx context.Context, key string, limit, skip int) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), g.cfg.RefreshTimeout)
+	go func() {
+		defer cancel()
+		if _, err := g.load(ctx, key, limit, skip); err != nil {
+			logger.WarnCtx(ctx, "Background product refresh failed", zap.String("key", key), zap.Error(err))
+		}
+	}()
+}
+
+// retention is how long pages stay in Redis, long enough to outlive the stale window and to
+// cover catalog outages.
+func (g *cachedProductGateway) retention() time.Duration {
+	return max(g.cfg.MaxStale, g.cfg.FreshTTL+g.cfg.StaleTTL)
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-boilerplate/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// invalidationChannel carries the keys every replica must evict from its local caches.
+const invalidationChannel = "cache:invalidate"
+
+// LocalOptions configures the in-process layer of a namespace. Size 0 disables it.
+type LocalOptions struct {
+	// Maximum number of entries, the least recently used are evicted first
+	Size int
+	// How long an entry is served locally. It bounds staleness when an invalidation is missed.
+	TTL time.Duration
+	// CacheMisses also remembers keys absent in Redis, for lookups that usually find nothing
+	CacheMisses bool
+	// Broadcast publishes invalidations even without a local layer, for a process writing to a
+	// namespace that other replicas cache locally. Namespaces with a local layer always broadcast.
+	Broadcast bool
+}
+
+type invalidation struct {
+	Keys []string `json:"keys"`
+}
+
+// Bus hands out namespaced caches with an in-process LRU in front of Redis. Writes and
+// invalidations are broadcast over Redis pub/sub, and Run evicts them from the local layers
+// of this replica.
+type Bus struct {
+	rdb    *redis.Client
+	remote *redisCache
+
+	mu     sync.RWMutex
+	locals []*local
+}
+
+func NewBus(rdb *redis.Client) *Bus {
+	return &Bus{rdb: rdb, remote: &redisCache{rdb: rdb}}
+}
+
+// Namespace returns a cache whose reads are served from a local LRU when possible. All
+// namespaces share the Redis keyspace and tags, only the local layer is separate.
+func (b *Bus) Namespace(name string, opts LocalOptions) Cache {
+	if opts.Size <= 0 || opts.TTL <= 0 {
+		return &layered{name: name, bus: b, broadcasts: opts.Broadcast}
+	}
+
+	l := newLocal(opts.Size, opts.TTL)
+	b.mu.Lock()
+	b.locals = append(b.locals, l)
+	b.mu.Unlock()
+	return &layered{name: name, bus: b, local: l, cacheMisses: opts.CacheMisses, broadcasts: true}
+}
+
+// Run evicts broadcast keys from the local layers until ctx is cancelled.
+func (b *Bus) Run(ctx context.Context) {
+	sub := b.rdb.Subscribe(ctx, invalidationChannel)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				logger.Error("Invalid cache invalidation message", zap.Error(err))
+				continue
+			}
+			b.evict(inv.Keys)
+		}
+	}
+}
+
+func (b *Bus) evict(keys []string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range b.locals {
+		l.delete(keys...)
+	}
+}
+
+// broadcast evicts keys here right away and asks the other replicas to do the same. A failed
+// publish is logged, the other replicas catch up when their local entries expire.
+func (b *Bus) broadcast(ctx context.Context, namespace string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	b.evict(keys)
+
+	payload, _ := json.Marshal(invalidation{Keys: keys})
+	if err := b.rdb.Publish(ctx, invalidationChannel, payload).Err(); err != nil {
+		logger.ErrorCtx(ctx, "Failed to broadcast cache invalidation",
+			zap.String("namespace", namespace), zap.Strings("keys", keys), zap.Error(err))
+	}
+}
+
+type layered struct {
+	name        string
+	bus         *Bus
+	local       *local
+	cacheMisses bool
+	// broadcasts is false for namespaces no replica caches locally, there is nothing to evict
+	broadcasts bool
+}
+
+func (c *layered) Get(ctx context.Context, key string, dest any) (bool, error) {
+	if c.local != nil {
+		if value, ok := c.local.get(key, time.Now()); ok {
+			if value == nil {
+				return false, nil
+			}
+			if err := json.Unmarshal(value, dest); err != nil {
+				return false, fmt.Errorf("failed to decode cache key %s: %w", key, err)
+			}
+			return true, nil
+		}
+	}
+
+	var raw json.RawMessage
+	found, err := c.bus.remote.Get(ctx, key, &raw)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		if c.local != nil && c.cacheMisses {
+			c.local.set(key, nil, time.Now())
+		}
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, dest); err != nil {
+		return false, fmt.Errorf("failed to decode cache key %s: %w", key, err)
+	}
+	if c.local != nil {
+		c.local.set(key, raw, time.Now())
+	}
+	return true, nil
+}
+
+func (c *layered) Set(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error {
+	if err := c.bus.remote.Set(ctx, key, value, ttl, tags...); err != nil {
+		return err
+	}
+	// Other replicas may hold an older value or a cached miss
+	c.broadcast(ctx, []string{key})
+	return nil
+}
+
+func (c *layered) Delete(ctx context.Context, keys ...string) error {
+	if err := c.bus.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	c.broadcast(ctx, keys)
+	return nil
+}
+
+func (c *layered) Invalidate(ctx context.Context, tags ...string) error {
+	keys, err := c.bus.remote.invalidate(ctx, tags)
+	if err != nil {
+		return err
+	}
+	c.broadcast(ctx, keys)
+	return nil
+}
+
+func (c *layered) broadcast(ctx context.Context, keys []string) {
+	if c.broadcasts {
+		c.bus.broadcast(ctx, c.name, keys)
+	}
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tagPrefix namespaces the Redis sets that track the keys of each tag.
+const tagPrefix = "cache:tag:"
+
+// Cache stores JSON values in Redis. Values can be tagged on Set, and Invalidate removes every
+// key stored under a tag, so callers never need to know the exact keys to evict.
+type Cache interface {
+	// Get decodes the value at key into dest and reports whether it was found.
+	Get(ctx context.Context, key string, dest any) (bool, error)
+	Set(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error
+	Delete(ctx context.Context, keys ...string) error
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// setScript stores the value and adds the key to each tag set. A tag set lives as long as its
+// longest-lived key, so sets of keys that are never invalidated do not pile up.
+//
+// KEYS[1] is the value key, KEYS[2..] the tag sets. ARGV[1] is the value, ARGV[2] the TTL in ms.
+var setScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+for i = 2, #KEYS do
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if redis.call('PTTL', KEYS[i]) < ttl then
+		redis.call('PEXPIRE', KEYS[i], ttl)
+	end
+end
+return 1
+`)
+
+// invalidateScript deletes every key tracked by the tag sets in KEYS, then the sets themselves,
+// and returns the tracked keys. Keys are deleted in batches to stay below Lua's unpack limit.
+var invalidateScript = redis.NewScript(`
+local keys = {}
+for _, tag in ipairs(KEYS) do
+	local members = redis.call('SMEMBERS', tag)
+	for i = 1, #members, 1000 do
+		redis.call('DEL', unpack(members, i, math.min(i + 999, #members)))
+	end
+	for _, member in ipairs(members) do
+		table.insert(keys, member)
+	end
+	redis.call('DEL', tag)
+end
+return keys
+`)
+
+type redisCache struct {
+	rdb *redis.Client
+}
+
+func New(rdb *redis.Client) Cache {
+	return &redisCache{rdb: rdb}
+}
+
+func (c *redisCache) Get(ctx context.Context, key string, dest any) (bool, error) {
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get cache key %s: %w", key, err)
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, fmt.Errorf("failed to decode cache key %s: %w", key, err)
+	}
+	return true, nil
+}
+
+func (c *redisCache) Set(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache key %s: %w", key, err)
+	}
+
+	keys := append([]string{key}, tagKeys(tags)...)
+	if err := setScript.Run(ctx, c.rdb, keys, data, ttl.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("failed to set cache key %s: %w", key, err)
+	}
+	return nil
+}
+
+func (c *redisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := c.rdb.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache keys: %w", err)
+	}
+	return nil
+}
+
+func (c *redisCache) Invalidate(ctx context.Context, tags ...string) error {
+	_, err := c.invalidate(ctx, tags)
+	return err
+}
+
+// invalidate deletes the keys of tags and returns them, so layered caches can evict their copies.
+func (c *redisCache) invalidate(ctx context.Context, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	keys, err := invalidateScript.Run(ctx, c.rdb, tagKeys(tags)).StringSlice()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to invalidate cache tags %v: %w", tags, err)
+	}
+	return keys, nil
+}
+
+func tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagPrefix + tag
+	}
+	return keys
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// localEntry holds an encoded value. A nil value records that the key was absent in Redis.
+type localEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// local is a size-bounded in-process LRU with a fixed TTL per entry.
+type local struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+func newLocal(size int, ttl time.Duration) *local {
+	return &local{size: size, ttl: ttl, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (l *local) get(key string, now time.Time) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*localEntry)
+	if now.After(e.expiresAt) {
+		l.removeElement(el)
+		return nil, false
+	}
+	l.ll.MoveToFront(el)
+	return e.value, true
+}
+
+func (l *local) set(key string, value []byte, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.items[key]; ok {
+		e := el.Value.(*localEntry)
+		e.value, e.expiresAt = value, now.Add(l.ttl)
+		l.ll.MoveToFront(el)
+		return
+	}
+
+	l.items[key] = l.ll.PushFront(&localEntry{key: key, value: value, expiresAt: now.Add(l.ttl)})
+	for l.ll.Len() > l.size {
+		l.removeElement(l.ll.Back())
+	}
+}
+
+func (l *local) delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := l.items[key]; ok {
+			l.removeElement(el)
+		}
+	}
+}
+
+func (l *local) removeElement(el *list.Element) {
+	l.ll.Remove(el)
+	delete(l.items, el.Value.(*localEntry).key)
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.elastic.co/apm/v2"
+)
+
+// Results counted per cache name.
+const (
+	ResultHit         = "hit"
+	ResultMiss        = "miss"
+	ResultNegativeHit = "negative_hit"
+	// ResultError counts reads that fell back to the loader because Redis failed or was too slow
+	ResultError = "error"
+)
+
+// DefaultMetrics collects the results of every read-through cache unless Options.Metrics is set.
+// Register it with apm.DefaultTracer().RegisterMetricsGatherer to report it.
+var DefaultMetrics = NewMetrics()
+
+type metricKey struct {
+	name   string
+	result string
+}
+
+// Metrics counts read-through results per cache name. It implements apm.MetricsGatherer.
+type Metrics struct {
+	mu       sync.RWMutex
+	counters map[metricKey]*atomic.Uint64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{counters: make(map[metricKey]*atomic.Uint64)}
+}
+
+func (m *Metrics) inc(name, result string) {
+	key := metricKey{name: name, result: result}
+
+	m.mu.RLock()
+	counter, ok := m.counters[key]
+	m.mu.RUnlock()
+	if !ok {
+		m.mu.Lock()
+		if counter, ok = m.counters[key]; !ok {
+			counter = new(atomic.Uint64)
+			m.counters[key] = counter
+		}
+		m.mu.Unlock()
+	}
+	counter.Add(1)
+}
+
+// Count returns how many reads of the named cache ended with result.
+func (m *Metrics) Count(name, result string) uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if counter, ok := m.counters[metricKey{name: name, result: result}]; ok {
+		return counter.Load()
+	}
+	return 0
+}
+
+// GatherMetrics reports the counters as cache.requests, labelled by cache name and result.
+func (m *Metrics) GatherMetrics(ctx context.Context, am *apm.Metrics) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for key, counter := range m.counters {
+		am.Add("cache.requests", []apm.MetricLabel{
+			{Name: "cache", Value: key.name},
+			{Name: "result", Value: key.result},
+		}, float64(counter.Load()))
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"go-boilerplate/pkg/logger"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound is returned by ReadThrough.Get for keys negatively cached as not found.
+var ErrNotFound = errors.New("not found")
+
+type Options struct {
+	// TTL of loaded values. Zero disables caching, every read goes to the loader.
+	TTL time.Duration
+	// Jitter adds up to this fraction of TTL at random, so keys loaded together do not expire together
+	Jitter float64
+	// NegativeTTL caches not-found results for this long. Zero disables negative caching.
+	NegativeTTL time.Duration
+	// IsNotFound reports whether a loader error means the value does not exist
+	IsNotFound func(error) bool
+	// Timeout bounds each Redis call. Slower calls are abandoned and the loader is used instead.
+	Timeout time.Duration
+	// Metrics receives the results, DefaultMetrics when nil
+	Metrics *Metrics
+}
+
+// entry wraps cached values so not-found results can be cached too.
+type entry[T any] struct {
+	Value    T    `json:"value"`
+	NotFound bool `json:"not_found,omitempty"`
+}
+
+// ReadThrough caches the results of a loader, typically a repository method, under the given key.
+// Concurrent misses of the same key share one load. Redis failures never fail a read: the value
+// is loaded from the source instead.
+type ReadThrough[T any] struct {
+	cache Cache
+	name  string
+	opts  Options
+	group singleflight.Group
+}
+
+// NewReadThrough returns a read-through cache reported in metrics under name.
+func NewReadThrough[T any](c Cache, name string, opts Options) *ReadThrough[T] {
+	if opts.Metrics == nil {
+		opts.Metrics = DefaultMetrics
+	}
+	if opts.IsNotFound == nil {
+		opts.IsNotFound = func(err error) bool { return errors.Is(err, ErrNotFound) }
+	}
+	return &ReadThrough[T]{cache: c, name: name, opts: opts}
+}
+
+// Get returns the value at key, calling load on a miss and storing the result with tags.
+// Values are shared between concurrent callers and must not be modified.
+func (r *ReadThrough[T]) Get(ctx context.Context, key string, load func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	if r.opts.TTL <= 0 {
+		return load(ctx)
+	}
+
+	var cached entry[T]
+	found, err := r.withTimeout(ctx, func(ctx context.Context) (bool, error) {
+		return r.cache.Get(ctx, key, &cached)
+	})
+	switch {
+	case err != nil:
+		r.opts.Metrics.inc(r.name, ResultError)
+		logger.WarnCtx(ctx, "Cache read failed, loading from source", zap.String("key", key), zap.Error(err))
+	case found && cached.NotFound:
+		r.opts.Metrics.inc(r.name, ResultNegativeHit)
+		var zero T
+		return zero, ErrNotFound
+	case found:
+		r.opts.Metrics.inc(r.name, ResultHit)
+		return cached.Value, nil
+	default:
+		r.opts.Metrics.inc(r.name, ResultMiss)
+	}
+
+	// The shared load must not be cancelled when only the first caller goes away
+	shared := context.WithoutCancel(ctx)
+	value, err, _ := r.group.Do(key, func() (any, error) {
+		value, err := load(shared)
+		if err != nil {
+			if r.opts.NegativeTTL > 0 && r.opts.IsNotFound(err) {
+				r.store(shared, key, entry[T]{NotFound: true}, r.opts.NegativeTTL, tags)
+			}
+			return value, err
+		}
+		r.store(shared, key, entry[T]{Value: value}, r.jittered(r.opts.TTL), tags)
+		return value, nil
+	})
+	return value.(T), err
+}
+
+func (r *ReadThrough[T]) store(ctx context.Context, key string, e entry[T], ttl time.Duration, tags []string) {
+	_, err := r.withTimeout(ctx, func(ctx context.Context) (bool, error) {
+		return true, r.cache.Set(ctx, key, e, ttl, tags...)
+	})
+	if err != nil {
+		logger.WarnCtx(ctx, "Cache write failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (r *ReadThrough[T]) withTimeout(ctx context.Context, fn func(ctx context.Context) (bool, error)) (bool, error) {
+	if r.opts.Timeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+func (r *ReadThrough[T]) jittered(ttl time.Duration) time.Duration {
+	if r.opts.Jitter <= 0 {
+		return ttl
+	}
+	max := int64(float64(ttl) * r.opts.Jitter)
+	if max <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int64N(max))
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// LSN is a position in the Postgres write-ahead log.
+type LSN uint64
+
+// ParseLSN parses the X/Y text form Postgres uses for pg_lsn.
+func ParseLSN(s string) (LSN, error) {
+	hi, lo, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid LSN %q", s)
+	}
+	high, err := strconv.ParseUint(hi, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", s, err)
+	}
+	low, err := strconv.ParseUint(lo, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", s, err)
+	}
+	return LSN(high<<32 | low), nil
+}
+
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint64(l)>>32, uint64(l)&0xFFFFFFFF)
+}
+
+type sessionKey struct{}
+
+// Session tracks read-your-writes consistency for one request.
+type Session struct {
+	minLSN LSN
+	wrote  atomic.Bool
+}
+
+// WithSession starts a session in ctx. Reads through the slave with the returned ctx only go to
+// replicas that replayed minLSN, or to the master, so they see the earlier writes of the caller.
+func WithSession(ctx context.Context, minLSN LSN) (context.Context, *Session) {
+	session := &Session{minLSN: minLSN}
+	return context.WithValue(ctx, sessionKey{}, session), session
+}
+
+// Wrote reports whether a statement modifying data was sent to the master in the session.
+func (s *Session) Wrote() bool {
+	return s.wrote.Load()
+}
+
+func sessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionKey{}).(*Session)
+	return session
+}
+
+// readOnlyStatements are the statements, as normalized by NormalizeQuery, that never modify data.
+var readOnlyStatements = []string{"select ", "show ", "values", "explain "}
+
+// modifyingKeyword finds a data-modifying statement in a normalized WITH query.
+var modifyingKeyword = regexp.MustCompile(`\b(insert|update|delete|merge)\b`)
+
+// modifiesData reports whether sql may modify data. Statements that cannot be told apart are
+// assumed to write, which only costs a read from the master.
+func modifiesData(sql string) bool {
+	normalized := NormalizeQuery(sql)
+	if strings.HasPrefix(normalized, "with ") {
+		return modifyingKeyword.MatchString(normalized)
+	}
+	for _, prefix := range readOnlyStatements {
+		if strings.HasPrefix(normalized, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionQuerier records in its session when a statement modifying data goes through it.
+type sessionQuerier struct {
+	Querier
+	session *Session
+}
+
+// withSession returns q, recording writes in the session of ctx if any.
+func withSession(ctx context.Context, q Querier) Querier {
+	if session := sessionFromContext(ctx); session != nil {
+		return sessionQuerier{Querier: q, session: session}
+	}
+	return q
+}
+
+func (q sessionQuerier) mark(sql string) {
+	if !q.session.Wrote() && modifiesData(sql) {
+		q.session.wrote.Store(true)
+	}
+}
+
+func (q sessionQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.mark(sql)
+	return q.Querier.Query(ctx, sql, args...)
+}
+
+func (q sessionQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.mark(sql)
+	return q.Querier.QueryRow(ctx, sql, args...)
+}
+
+func (q sessionQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.mark(sql)
+	return q.Querier.Exec(ctx, sql, args...)
+}
+
+func (q sessionQuerier) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	q.session.wrote.Store(true)
+	return q.Querier.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+// MinLSN returns the position replicas must have replayed to serve reads with ctx.
+func MinLSN(ctx context.Context) LSN {
+	if session := sessionFromContext(ctx); session != nil {
+		return session.minLSN
+	}
+	return 0
+}
+
+// CurrentLSN returns the current write position of the master. Once a replica has replayed it,
+// the replica sees every transaction committed before the call.
+func (d *Database) CurrentLSN(ctx context.Context) (LSN, error) {
+	var text string
+	if err := d.Master.QueryRow(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&text); err != nil {
+		return 0, fmt.Errorf("failed to get current LSN: %w", err)
+	}
+	return ParseLSN(text)
+}
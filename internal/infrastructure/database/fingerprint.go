@@ -0,0 +1,145 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// operatorChars are grouped into one token, so >= and <> stay together.
+const operatorChars = "<>=!~+-*/%|&^@#"
+
+// valueList matches lists of normalized values, such as IN (?, ?, ?) or VALUES (?, ?), (?, ?).
+var (
+	valueList = regexp.MustCompile(`\(\?(?:, \?)*\)`)
+	tupleList = regexp.MustCompile(`\(\.\.\.\)(?:,\(\.\.\.\))+`)
+)
+
+// NormalizeQuery returns the shape of sql: comments are removed, literals and parameters become
+// ?, lists of values collapse to (...), whitespace is collapsed and keywords are lowercased.
+// Queries that only differ in their values normalize to the same string.
+func NormalizeQuery(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+	// space is whether a space separates the next token from the previous one, last is the
+	// previous character written
+	space := false
+	var last byte
+	emit := func(s string) {
+		if space && last != 0 && strings.IndexByte("([.:", last) < 0 && strings.IndexByte(")],.:", s[0]) < 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteString(s)
+		last = s[len(s)-1]
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+			space = true
+
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+			space = true
+
+		case c == '\'':
+			// '' inside a string is an escaped quote
+			i++
+			for i < len(sql) {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			emit("?")
+
+		case c == '"':
+			// Quoted identifiers are kept as they are
+			end := strings.IndexByte(sql[i+1:], '"')
+			if end < 0 {
+				end = len(sql) - i - 1
+			}
+			emit(sql[i : i+end+2])
+			i += end + 2
+
+		case c == '$' && i+1 < len(sql) && isDigit(sql[i+1]):
+			i++
+			for i < len(sql) && isDigit(sql[i]) {
+				i++
+			}
+			emit("?")
+
+		case isDigit(c):
+			for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.') {
+				i++
+			}
+			emit("?")
+
+		case isWordChar(c):
+			start := i
+			for i < len(sql) && (isWordChar(sql[i]) || isDigit(sql[i])) {
+				i++
+			}
+			emit(strings.ToLower(sql[start:i]))
+
+		case strings.IndexByte(operatorChars, c) >= 0:
+			start := i
+			for i < len(sql) && strings.IndexByte(operatorChars, sql[i]) >= 0 {
+				i++
+			}
+			space = true
+			emit(sql[start:i])
+			space = true
+
+		case c == ',':
+			emit(",")
+			space = true
+			i++
+
+		default:
+			// Brackets, dots and casts hug their neighbours
+			if c == '(' || c == '[' {
+				space = false
+			}
+			emit(string(c))
+			i++
+		}
+	}
+
+	normalized := valueList.ReplaceAllString(b.String(), "(...)")
+	return tupleList.ReplaceAllString(normalized, "(...)")
+}
+
+// Fingerprint identifies the shape of sql, see NormalizeQuery.
+func Fingerprint(sql string) string {
+	sum := sha256.Sum256([]byte(NormalizeQuery(sql)))
+	return hex.EncodeToString(sum[:8])
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
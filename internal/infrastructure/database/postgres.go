@@ -4,19 +4,27 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"time"
 
 	"go-boilerplate/internal/config"
+	"go-boilerplate/pkg/tenant"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// UnscopedTenant is the app.tenant_id of connections that may access every tenant.
+const UnscopedTenant = "*"
+
 type DBPool interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
 	Ping(ctx context.Context) error
 	Close()
 }
@@ -36,8 +44,32 @@ func (d *Database) Close() {
 }
 
 func Connect(cfg config.DatabaseConfig) *Database {
-	master := connectPool(cfg.Master, "Master")
-	slave := connectPool(cfg.Slave, "Slave")
+	tracer := NewQueryTracer(cfg.QueryLog)
+	master := connectPool(cfg.Master, "Master", cfg.RLSEnabled, tracer)
+
+	hosts := cfg.Replica.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{net.JoinHostPort(cfg.Slave.Host, cfg.Slave.Port)}
+	}
+	replicas := make([]Replica, 0, len(hosts))
+	for _, hostPort := range hosts {
+		host, port, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			log.Fatalf("Invalid replica address %q: %v", hostPort, err)
+		}
+		replicaCfg := cfg.Slave
+		replicaCfg.Host, replicaCfg.Port = host, port
+		replicas = append(replicas, Replica{Name: hostPort, Pool: newReplicaPool(replicaCfg, cfg.RLSEnabled, tracer)})
+	}
+
+	slave := NewReplicaSet(master, replicas, ReplicaOptions{
+		Balancer:      cfg.Replica.Balancer,
+		MaxLag:        cfg.Replica.MaxLag,
+		CheckInterval: cfg.Replica.CheckInterval,
+		CheckTimeout:  cfg.Replica.CheckTimeout,
+	})
+	slave.Check(context.Background())
+	slave.Start()
 
 	return &Database{
 		Master: master,
@@ -45,11 +77,14 @@ func Connect(cfg config.DatabaseConfig) *Database {
 	}
 }
 
-func connectPool(cfg config.DBConnectionConfig, name string) *pgxpool.Pool {
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+// DSN returns the connection string for cfg.
+func DSN(cfg config.DBConnectionConfig) string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
 		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name, cfg.SSLMode)
+}
 
-	poolConfig, err := pgxpool.ParseConfig(dsn)
+func newPoolConfig(cfg config.DBConnectionConfig, name string, rlsEnabled bool, tracer *QueryTracer) *pgxpool.Config {
+	poolConfig, err := pgxpool.ParseConfig(DSN(cfg))
 	if err != nil {
 		log.Fatalf("Failed to parse %s database config: %v", name, err)
 	}
@@ -59,9 +94,33 @@ func connectPool(cfg config.DBConnectionConfig, name string) *pgxpool.Pool {
 	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
 
 	// Add query tracer for logging SQL queries to ELK
-	poolConfig.ConnConfig.Tracer = &QueryTracer{}
+	poolConfig.ConnConfig.Tracer = tracer
+
+	if rlsEnabled {
+		poolConfig.PrepareConn = setTenant
+	} else {
+		// The policies deny connections without a tenant, so without RLS every connection is unscoped
+		poolConfig.ConnConfig.RuntimeParams["app.tenant_id"] = UnscopedTenant
+	}
+	return poolConfig
+}
+
+// newReplicaPool creates the pool without waiting for the replica, which may be down at startup.
+// The health checks eject it until it is reachable.
+func newReplicaPool(cfg config.DBConnectionConfig, rlsEnabled bool, tracer *QueryTracer) *pgxpool.Pool {
+	name := "Replica " + net.JoinHostPort(cfg.Host, cfg.Port)
+	db, err := pgxpool.NewWithConfig(context.Background(), newPoolConfig(cfg, name, rlsEnabled, tracer))
+	if err != nil {
+		log.Fatalf("Failed to create %s database pool: %v", name, err)
+	}
+	return db
+}
+
+func connectPool(cfg config.DBConnectionConfig, name string, rlsEnabled bool, tracer *QueryTracer) *pgxpool.Pool {
+	poolConfig := newPoolConfig(cfg, name, rlsEnabled, tracer)
 
 	var db *pgxpool.Pool
+	var err error
 	ctx := context.Background()
 
 	// Retry connection loop
@@ -84,3 +143,20 @@ func connectPool(cfg config.DBConnectionConfig, name string) *pgxpool.Pool {
 
 	return db
 }
+
+// setTenant sets app.tenant_id on a connection as it is acquired, from the tenant in the acquiring ctx.
+// System contexts set UnscopedTenant. Contexts with neither clear it, which the row-level security
+// policies deny, so a forgotten tenant fails closed.
+func setTenant(ctx context.Context, conn *pgx.Conn) (bool, error) {
+	id, err := tenant.Scope(ctx)
+	switch {
+	case err != nil:
+		id = ""
+	case id == "":
+		id = UnscopedTenant
+	}
+	if _, err := conn.Exec(ctx, "SELECT set_config('app.tenant_id', $1, false)", id); err != nil {
+		return false, fmt.Errorf("failed to set tenant on connection: %w", err)
+	}
+	return true, nil
+}
@@ -2,8 +2,11 @@ package database
 
 import (
 	"context"
+	"math/rand/v2"
+	"strings"
 	"time"
 
+	"go-boilerplate/internal/config"
 	"go-boilerplate/pkg/logger"
 
 	"github.com/jackc/pgx/v5"
@@ -11,11 +14,24 @@ import (
 	"go.uber.org/zap"
 )
 
-// QueryTracer implements pgx.QueryTracer to log all SQL queries and create APM spans
-type QueryTracer struct{}
+// QueryTracer implements pgx.QueryTracer to log SQL queries and create APM spans. Failed and slow
+// queries are always logged, other queries are sampled.
+type QueryTracer struct {
+	cfg config.QueryLogConfig
+}
+
+func NewQueryTracer(cfg config.QueryLogConfig) *QueryTracer {
+	return &QueryTracer{cfg: cfg}
+}
 
 type queryContextKey struct{}
 
+// explainKey marks the EXPLAIN run by the tracer itself, which is not traced.
+type explainKey struct{}
+
+// explainable are the statements EXPLAIN accepts, as normalized by NormalizeQuery.
+var explainable = []string{"select ", "insert ", "update ", "delete ", "with ", "values"}
+
 type queryContextValue struct {
 	startTime time.Time
 	sql       string
@@ -25,6 +41,10 @@ type queryContextValue struct {
 
 // TraceQueryStart is called at the beginning of Query, QueryRow, and Exec calls
 func (t *QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if ctx.Value(explainKey{}) != nil {
+		return ctx
+	}
+
 	// Start APM span for the SQL query
 	span, ctx := apm.StartSpan(ctx, "SQL", "db.postgresql.query")
 	if span != nil && !span.Dropped() {
@@ -60,24 +80,67 @@ func (t *QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pg
 	}
 
 	duration := time.Since(queryCtx.startTime)
+	slow := duration >= t.cfg.SlowThreshold
+	if data.Err == nil && !slow && rand.Float64()*100 >= t.cfg.SamplePercent {
+		return
+	}
 
 	fields := []zap.Field{
 		zap.String("type", "sql_query"),
 		zap.String("sql", queryCtx.sql),
-		zap.Any("args", queryCtx.args),
+		zap.String("fingerprint", Fingerprint(queryCtx.sql)),
 		zap.Duration("duration", duration),
 		zap.Int64("duration_ms", duration.Milliseconds()),
 		zap.String("command_tag", data.CommandTag.String()),
 	}
+	if t.cfg.LogArgs {
+		fields = append(fields, zap.Any("args", queryCtx.args))
+	}
 
-	// Add trace context fields for log-trace correlation
-	fields = append(fields, logger.TraceFields(ctx)...)
-
-	if data.Err != nil {
+	switch {
+	case data.Err != nil:
 		fields = append(fields, zap.Error(data.Err))
-		logger.Error("SQL query failed", fields...)
-	} else {
-		logger.Info("SQL query executed", fields...)
+		logger.ErrorCtx(ctx, "SQL query failed", fields...)
+	case slow:
+		// Normalized so slow queries can be aggregated by shape
+		normalized := NormalizeQuery(queryCtx.sql)
+		fields = append(fields, zap.String("sql_normalized", normalized))
+		// Inside a transaction a failed or timed out EXPLAIN would abort the caller's transaction
+		if t.cfg.Explain && conn != nil && conn.PgConn().TxStatus() == 'I' && isExplainable(normalized) {
+			if plan, err := t.explain(conn, queryCtx); err != nil {
+				fields = append(fields, zap.NamedError("explain_error", err))
+			} else {
+				fields = append(fields, zap.String("plan", plan))
+			}
+		}
+		logger.WarnCtx(ctx, "Slow SQL query", fields...)
+	default:
+		logger.InfoCtx(ctx, "SQL query executed", fields...)
+	}
+}
+
+// explain returns the plan of the query, run on the idle connection that executed it. Without
+// ANALYZE the statement is planned but not executed.
+func (t *QueryTracer) explain(conn *pgx.Conn, query *queryContextValue) (string, error) {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), explainKey{}, true), t.cfg.ExplainTimeout)
+	defer cancel()
+
+	rows, err := conn.Query(ctx, "EXPLAIN "+query.sql, query.args...)
+	if err != nil {
+		return "", err
+	}
+	lines, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", err
 	}
+	return strings.Join(lines, "\n"), nil
 }
 
+func isExplainable(normalized string) bool {
+	for _, prefix := range explainable {
+		if strings.HasPrefix(normalized, prefix) {
+			return true
+		}
+	}
+	return false
+}
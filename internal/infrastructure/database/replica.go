@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-boilerplate/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Balancers selectable in ReplicaOptions.
+const (
+	BalancerRoundRobin       = "round_robin"
+	BalancerLeastConnections = "least_connections"
+)
+
+// replicationLagQuery returns the replay lag in seconds and the replayed LSN. A replica that
+// replayed everything it received is not lagging even when the last replayed transaction is old,
+// and a server that is not in recovery has no lag at all.
+const replicationLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8, (CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END)::text`
+
+type Replica struct {
+	Name string
+	Pool DBPool
+}
+
+type ReplicaOptions struct {
+	// Balancer is round_robin or least_connections
+	Balancer string
+	// MaxLag ejects replicas replaying further behind the master
+	MaxLag time.Duration
+	// CheckInterval is how often replicas are checked, CheckTimeout bounds each check
+	CheckInterval time.Duration
+	CheckTimeout  time.Duration
+}
+
+// ReplicaStatus is the outcome of the last health check of a replica.
+type ReplicaStatus struct {
+	Name    string
+	Healthy bool
+	Lag     time.Duration
+	Err     error
+}
+
+type replica struct {
+	Replica
+	healthy atomic.Bool
+	// replayed is the LSN the replica had replayed at the last check
+	replayed atomic.Uint64
+
+	mu     sync.Mutex
+	status ReplicaStatus
+}
+
+// ReplicaSet is a DBPool spreading reads over replicas. Replicas that are down or lag more than
+// MaxLag are ejected by the background checks and return once healthy, and reads go to the
+// master while no replica is healthy.
+type ReplicaSet struct {
+	master   DBPool
+	replicas []*replica
+	opts     ReplicaOptions
+	next     atomic.Uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReplicaSet returns a set in which every replica is healthy until checked. Call Check for
+// an accurate state and Start to keep it up to date.
+func NewReplicaSet(master DBPool, replicas []Replica, opts ReplicaOptions) *ReplicaSet {
+	switch opts.Balancer {
+	case BalancerRoundRobin, BalancerLeastConnections:
+	default:
+		log.Fatalf("Unknown replica balancer %q", opts.Balancer)
+	}
+
+	s := &ReplicaSet{master: master, opts: opts, stop: make(chan struct{})}
+	for _, r := range replicas {
+		member := &replica{Replica: r, status: ReplicaStatus{Name: r.Name, Healthy: true}}
+		member.healthy.Store(true)
+		s.replicas = append(s.replicas, member)
+	}
+	return s
+}
+
+// Start checks the replicas every CheckInterval until Close.
+func (s *ReplicaSet) Start() {
+	go func() {
+		ticker := time.NewTicker(s.opts.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.Check(context.Background())
+			}
+		}
+	}()
+}
+
+// Check pings every replica and measures its replication lag, ejecting or restoring it.
+func (s *ReplicaSet) Check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.check(ctx, r)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *ReplicaSet) check(ctx context.Context, r *replica) {
+	if s.opts.CheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.CheckTimeout)
+		defer cancel()
+	}
+
+	status := ReplicaStatus{Name: r.Name}
+	var seconds float64
+	var replayed *string
+	if err := r.Pool.QueryRow(ctx, replicationLagQuery).Scan(&seconds, &replayed); err != nil {
+		status.Err = fmt.Errorf("failed to check replica: %w", err)
+	} else {
+		// Nothing replayed yet leaves zero, which no session waiting for a write accepts
+		var lsn LSN
+		if replayed != nil {
+			if lsn, err = ParseLSN(*replayed); err != nil {
+				status.Err = err
+			}
+		}
+		r.replayed.Store(uint64(lsn))
+
+		status.Lag = time.Duration(seconds * float64(time.Second))
+		if status.Err == nil && s.opts.MaxLag > 0 && status.Lag > s.opts.MaxLag {
+			status.Err = fmt.Errorf("replication lag %s exceeds %s", status.Lag.Round(time.Millisecond), s.opts.MaxLag)
+		}
+	}
+	status.Healthy = status.Err == nil
+
+	r.mu.Lock()
+	r.status = status
+	r.mu.Unlock()
+	if r.healthy.Swap(status.Healthy) == status.Healthy {
+		return
+	}
+	if status.Healthy {
+		logger.InfoCtx(ctx, "Database replica restored", zap.String("replica", r.Name))
+	} else {
+		logger.WarnCtx(ctx, "Database replica ejected", zap.String("replica", r.Name), zap.Error(status.Err))
+	}
+}
+
+// Status returns the result of the last check of every replica.
+func (s *ReplicaSet) Status() []ReplicaStatus {
+	statuses := make([]ReplicaStatus, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		r.mu.Lock()
+		statuses = append(statuses, r.status)
+		r.mu.Unlock()
+	}
+	return statuses
+}
+
+// pick returns the pool of a healthy replica that replayed the writes the session in ctx waits
+// for, or the master when there is none.
+func (s *ReplicaSet) pick(ctx context.Context) DBPool {
+	n := len(s.replicas)
+	if n == 0 {
+		return s.master
+	}
+	required := uint64(MinLSN(ctx))
+	// Starting from the next replica in turn also spreads least_connections ties
+	start := int(s.next.Add(1) % uint64(n))
+
+	var chosen *replica
+	least := int32(-1)
+	for i := 0; i < n; i++ {
+		r := s.replicas[(start+i)%n]
+		if !r.healthy.Load() || r.replayed.Load() < required {
+			continue
+		}
+		if s.opts.Balancer == BalancerRoundRobin {
+			return r.Pool
+		}
+		if conns := acquiredConns(r.Pool); least < 0 || conns < least {
+			chosen, least = r, conns
+		}
+	}
+	if chosen == nil {
+		return s.master
+	}
+	return chosen.Pool
+}
+
+// acquiredConns returns the connections in use in pool, zero when it cannot tell.
+func acquiredConns(pool DBPool) int32 {
+	if p, ok := pool.(*pgxpool.Pool); ok {
+		return p.Stat().AcquiredConns()
+	}
+	return 0
+}
+
+func (s *ReplicaSet) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return s.pick(ctx).Query(ctx, sql, args...)
+}
+
+func (s *ReplicaSet) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return s.pick(ctx).QueryRow(ctx, sql, args...)
+}
+
+func (s *ReplicaSet) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return s.pick(ctx).Exec(ctx, sql, args...)
+}
+
+func (s *ReplicaSet) Begin(ctx context.Context) (pgx.Tx, error) {
+	return s.pick(ctx).Begin(ctx)
+}
+
+func (s *ReplicaSet) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return s.pick(ctx).BeginTx(ctx, txOptions)
+}
+
+func (s *ReplicaSet) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return s.pick(ctx).CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+// Ping reports whether reads can be served, by a replica or by the master.
+func (s *ReplicaSet) Ping(ctx context.Context) error {
+	return s.pick(ctx).Ping(ctx)
+}
+
+// Close stops the checks and closes the replica pools. The master is left to its owner.
+func (s *ReplicaSet) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	for _, r := range s.replicas {
+		r.Pool.Close()
+	}
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is the set of query methods shared by pools and transactions.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// Transactor runs a function inside a database transaction.
+type Transactor interface {
+	WithTx(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error
+}
+
+// TxOptions configures a transaction. The zero value is a read-write transaction on the master
+// with the server's default isolation level and no retries.
+type TxOptions struct {
+	// IsoLevel such as pgx.Serializable, the server default when empty
+	IsoLevel pgx.TxIsoLevel
+	// ReadOnly runs the transaction on the slave. Writes in it fail.
+	ReadOnly bool
+	// MaxRetries re-runs the whole transaction on serialization failures and deadlocks, so fn
+	// must be safe to run again. Nested calls are never retried on their own, the outermost
+	// transaction is.
+	MaxRetries int
+}
+
+// retryBackoff is the base delay between retries, multiplied by the attempt and jittered.
+const retryBackoff = 10 * time.Millisecond
+
+// Postgres error codes worth retrying the whole transaction for.
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+type txKey struct{}
+
+type txState struct {
+	tx       pgx.Tx
+	readOnly bool
+}
+
+// WithTx runs fn in a transaction. Repositories called with the ctx passed to fn join the
+// transaction through Writer and Reader. The transaction is committed when fn returns nil and
+// rolled back otherwise.
+//
+// Nested calls run in a savepoint of the outer transaction: an error rolls back only the work
+// of the nested fn, and the outer fn decides whether to carry on. A read-write call cannot be
+// nested in a read-only transaction.
+func (d *Database) WithTx(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	if outer, ok := txFromContext(ctx); ok {
+		if outer.readOnly && !opts.ReadOnly {
+			return errors.New("cannot start a read-write transaction inside a read-only one")
+		}
+		// Begin on a transaction creates a savepoint, Commit and Rollback release or roll back to it
+		savepoint, err := outer.tx.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		return run(ctx, savepoint, outer.readOnly, fn, "savepoint")
+	}
+
+	pool := d.Master
+	txOpts := pgx.TxOptions{IsoLevel: opts.IsoLevel}
+	if opts.ReadOnly {
+		pool = d.Slave
+		txOpts.AccessMode = pgx.ReadOnly
+	}
+
+	for attempt := 0; ; attempt++ {
+		tx, err := pool.BeginTx(ctx, txOpts)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+
+		err = run(ctx, tx, opts.ReadOnly, fn, "transaction")
+		if err == nil || attempt >= opts.MaxRetries || !isRetryable(err) {
+			return err
+		}
+
+		delay := time.Duration(attempt+1) * retryBackoff
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay/2 + rand.N(delay)):
+		}
+	}
+}
+
+// run calls fn with tx in the context, then commits tx or rolls it back. kind names tx in errors.
+func run(ctx context.Context, tx pgx.Tx, readOnly bool, fn func(ctx context.Context) error, kind string) error {
+	if err := fn(context.WithValue(ctx, txKey{}, txState{tx: tx, readOnly: readOnly})); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("%w (%s rollback failed: %v)", err, kind, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit %s: %w", kind, err)
+	}
+	return nil
+}
+
+// isRetryable reports whether err is a serialization failure or deadlock, after which the
+// transaction can succeed when run again.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
+
+// Writer returns the transaction in ctx, or the master pool. Statements modifying data through it
+// are recorded in the consistency session of ctx.
+func (d *Database) Writer(ctx context.Context) Querier {
+	if state, ok := txFromContext(ctx); ok {
+		return withSession(ctx, state.tx)
+	}
+	return withSession(ctx, d.Master)
+}
+
+// Reader returns the transaction in ctx so reads see uncommitted writes, or the slave pool.
+func (d *Database) Reader(ctx context.Context) Querier {
+	if state, ok := txFromContext(ctx); ok {
+		return state.tx
+	}
+	return d.Slave
+}
+
+func txFromContext(ctx context.Context) (txState, bool) {
+	state, ok := ctx.Value(txKey{}).(txState)
+	return state, ok
+}
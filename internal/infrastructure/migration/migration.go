@@ -0,0 +1,340 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/migrations"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// lockKey identifies the advisory lock held while migrating, any constant shared by all replicas.
+const lockKey = 7_146_245_368_712_113_153
+
+// The schema_migrations table of golang-migrate is kept, so databases migrated with its CLI
+// carry on where they stopped. It holds at most one row, absent before the first migration.
+const (
+	createTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`
+	versionQuery     = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	setVersionQuery  = `WITH cleared AS (DELETE FROM schema_migrations) INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`
+	clearQuery       = `DELETE FROM schema_migrations`
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a pair of <version>_<name>.up.sql and .down.sql files.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is the version of the database and the migrations not applied yet.
+type Status struct {
+	// Version is the last applied migration, zero before the first
+	Version uint64
+	// Dirty means the migration at Version failed halfway, it must be fixed by hand and forced
+	Dirty   bool
+	Pending []Migration
+}
+
+// Conn is the single connection migrations run on, the advisory lock belongs to it.
+type Conn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Load reads the migrations in fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	hasUp := make(map[uint64]bool)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up, hasUp[version] = string(content), true
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if !hasUp[m.Version] {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+func compare(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Migrator applies migrations. Every method holding the advisory lock waits for the replicas
+// migrating at the same time, then sees the version they left.
+type Migrator struct {
+	conn       Conn
+	migrations []Migration
+}
+
+func New(conn Conn, migrations []Migration) *Migrator {
+	return &Migrator{conn: conn, migrations: migrations}
+}
+
+// Connect opens the single connection migrations need, outside the pools.
+func Connect(ctx context.Context, cfg config.DBConnectionConfig) (*pgx.Conn, error) {
+	connConfig, err := pgx.ParseConfig(database.DSN(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database config: %w", err)
+	}
+	// Migrations may move data across tenants, past the row-level security policies
+	connConfig.RuntimeParams["app.tenant_id"] = database.UnscopedTenant
+
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect for migrations: %w", err)
+	}
+	return conn, nil
+}
+
+// UpEmbedded applies the migrations embedded in the binary to the database of cfg.
+func UpEmbedded(ctx context.Context, cfg config.DBConnectionConfig) ([]Migration, error) {
+	loaded, err := Load(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := Connect(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+	return New(conn, loaded).Up(ctx)
+}
+
+// Up applies every pending migration and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(version uint64) error {
+		for _, migration := range m.migrations {
+			if migration.Version <= version {
+				continue
+			}
+			if err := m.apply(ctx, migration.Version, migration.Version, migration.Up); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last n applied migrations and returns them.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(version uint64) error {
+		index, err := m.index(version)
+		if err != nil {
+			return err
+		}
+		for ; index >= 0 && len(reverted) < n; index-- {
+			migration := m.migrations[index]
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			var previous uint64
+			if index > 0 {
+				previous = m.migrations[index-1].Version
+			}
+			if err := m.apply(ctx, migration.Version, previous, migration.Down); err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status returns the version of the database without taking the lock.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	if _, err := m.conn.Exec(ctx, createTableQuery); err != nil {
+		return Status{}, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	version, dirty, err := m.version(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Version: version, Dirty: dirty}
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Force sets the version without running any migration and clears the dirty flag, once a
+// failed migration has been fixed by hand. Zero means no migration is applied.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 {
+		if _, err := m.index(version); err != nil {
+			return err
+		}
+	}
+	return m.withLock(ctx, func() error {
+		return m.setVersion(ctx, version, false)
+	})
+}
+
+// locked runs fn under the lock with the current version, refusing dirty databases.
+func (m *Migrator) locked(ctx context.Context, fn func(version uint64) error) error {
+	return m.withLock(ctx, func() error {
+		version, dirty, err := m.version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("database is dirty at version %d, fix it by hand then force a version", version)
+		}
+		return fn(version)
+	})
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if _, err := m.conn.Exec(ctx, "SELECT pg_advisory_lock($1)", int64(lockKey)); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	// Released even when ctx is done, or the lock would be held until the connection closes
+	defer m.conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", int64(lockKey))
+
+	if _, err := m.conn.Exec(ctx, createTableQuery); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return fn()
+}
+
+// apply runs sql, leaving the database dirty at version if it fails and at next otherwise.
+// Like golang-migrate, sql is sent as is without an explicit transaction. Postgres still runs a file
+// of several statements in one implicit transaction, so CREATE INDEX CONCURRENTLY must be alone in
+// its migration file.
+func (m *Migrator) apply(ctx context.Context, version, next uint64, sql string) error {
+	if err := m.setVersion(ctx, version, true); err != nil {
+		return err
+	}
+	// No arguments, so pgx uses the simple protocol that accepts several statements
+	if _, err := m.conn.Exec(ctx, sql); err != nil {
+		return err
+	}
+	return m.setVersion(ctx, next, false)
+}
+
+func (m *Migrator) version(ctx context.Context) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := m.conn.QueryRow(ctx, versionQuery).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return uint64(version), dirty, nil
+}
+
+func (m *Migrator) setVersion(ctx context.Context, version uint64, dirty bool) error {
+	var err error
+	if version == 0 {
+		_, err = m.conn.Exec(ctx, clearQuery)
+	} else {
+		_, err = m.conn.Exec(ctx, setVersionQuery, int64(version), dirty)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set migration version: %w", err)
+	}
+	return nil
+}
+
+// index returns the position of the applied version, -1 for zero.
+func (m *Migrator) index(version uint64) (int, error) {
+	if version == 0 {
+		return -1, nil
+	}
+	index := slices.IndexFunc(m.migrations, func(migration Migration) bool { return migration.Version == version })
+	if index < 0 {
+		return 0, fmt.Errorf("no migration file for version %d", version)
+	}
+	return index, nil
+}
+
+// Create adds empty up and down files for the next version to dir and returns their paths.
+func Create(dir, name string) (string, string, error) {
+	name = strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
+	if name == "" {
+		return "", "", errors.New("migration name is required")
+	}
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	var version uint64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, name))
+	up, down := base+".up.sql", base+".down.sql"
+	for _, path := range []string{up, down} {
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			return "", "", fmt.Errorf("failed to create %s: %w", path, err)
+		}
+	}
+	return up, down, nil
+}
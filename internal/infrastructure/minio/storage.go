@@ -0,0 +1,95 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Storage is the subset of object storage operations used by the application.
+type Storage interface {
+	Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	PresignedGetURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+type storage struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewStorage(client *minio.Client, bucket string) Storage {
+	return &storage{client: client, bucket: bucket}
+}
+
+// EnsureBucket creates the bucket if it does not exist yet.
+func EnsureBucket(ctx context.Context, client *minio.Client, bucket string) error {
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket %s: %w", bucket, err)
+	}
+	if exists {
+		return nil
+	}
+	if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+	}
+	return nil
+}
+
+func (s *storage) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *storage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object %s: %w", key, err)
+	}
+	return obj, nil
+}
+
+// PresignedGetURL returns a time-limited download link for the object.
+func (s *storage) PresignedGetURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object %s: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// DeletePrefix removes every object whose key starts with prefix.
+func (s *storage) DeletePrefix(ctx context.Context, prefix string) error {
+	var found []minio.ObjectInfo
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return fmt.Errorf("failed to list objects %s: %w", prefix, obj.Err)
+		}
+		found = append(found, obj)
+	}
+
+	objects := make(chan minio.ObjectInfo, len(found))
+	for _, obj := range found {
+		objects <- obj
+	}
+	close(objects)
+
+	// The error channel is drained fully so the removal goroutines can exit
+	var err error
+	for res := range s.client.RemoveObjects(ctx, s.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if err == nil {
+			err = fmt.Errorf("failed to delete object %s: %w", res.ObjectName, res.Err)
+		}
+	}
+	return err
+}
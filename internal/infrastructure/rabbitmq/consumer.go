@@ -0,0 +1,156 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-boilerplate/pkg/logger"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// DeadLetterSuffix names the queue holding the messages of a queue that could not be processed.
+	DeadLetterSuffix = ".dead"
+	// RetrySuffix names the queue holding failed messages until their backoff expires, they are then
+	// dead-lettered back to the queue they came from.
+	RetrySuffix = ".retry"
+	// maxRetries is how many times a failed message is retried before it is dead-lettered.
+	maxRetries = 5
+	// retryBackoff is the wait before the first retry, doubled on each following one.
+	retryBackoff = time.Second
+	retryHeader  = "x-retries"
+)
+
+// HandlerFunc processes a single message body. Errors are retried unless marked with Permanent.
+type HandlerFunc func(ctx context.Context, body []byte) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error that retrying cannot fix, such as an invalid message. The
+// message is dead-lettered right away.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// declareQueue declares a durable queue, the queue its rejected messages are dead-lettered to, and the
+// queue its failed messages wait in before being retried.
+func declareQueue(ch *amqp.Channel, queue string) error {
+	if _, err := ch.QueueDeclare(queue+DeadLetterSuffix, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queue+DeadLetterSuffix, err)
+	}
+	_, err := ch.QueueDeclare(queue+RetrySuffix, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": queue,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queue+RetrySuffix, err)
+	}
+	_, err = ch.QueueDeclare(queue, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": queue + DeadLetterSuffix,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queue, err)
+	}
+	return nil
+}
+
+// Consume declares a durable queue and dispatches its messages to handler until ctx is cancelled.
+// Messages are acknowledged on success. Failed messages are moved to the retry queue, which returns
+// them after a backoff, up to maxRetries times; they are then rejected to the dead letter queue.
+// Permanent failures are rejected at once.
+func Consume(ctx context.Context, conn *amqp.Connection, queue string, prefetch int, handler HandlerFunc) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := declareQueue(ch, queue); err != nil {
+		return err
+	}
+
+	if err := ch.Qos(prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set qos: %w", err)
+	}
+
+	deliveries, err := ch.ConsumeWithContext(ctx, queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", queue, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return fmt.Errorf("delivery channel for %s closed", queue)
+			}
+			if err := handler(ctx, d.Body); err != nil {
+				handleFailure(ctx, ch, queue, d, err)
+				continue
+			}
+			d.Ack(false)
+		}
+	}
+}
+
+func handleFailure(ctx context.Context, ch *amqp.Channel, queue string, d amqp.Delivery, err error) {
+	retries := retryCount(d)
+	fields := []zap.Field{zap.String("queue", queue), zap.Int("retries", retries), zap.Error(err)}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || retries >= maxRetries {
+		logger.ErrorCtx(ctx, "Message dead-lettered", fields...)
+		d.Nack(false, false)
+		return
+	}
+
+	logger.WarnCtx(ctx, "Message failed, retrying", fields...)
+
+	// The retry queue has no consumer, the message expires there and is dead-lettered back to queue.
+	// An expired message only leaves once it reaches the head of the retry queue, so a short backoff
+	// can wait for a longer one ahead of it.
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[retryHeader] = int32(retries + 1)
+	err = ch.PublishWithContext(ctx, "", queue+RetrySuffix, false, false, amqp.Publishing{
+		Headers:      headers,
+		Expiration:   strconv.FormatInt((retryBackoff << retries).Milliseconds(), 10),
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Body:         d.Body,
+	})
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish retry, requeueing", append(fields, zap.NamedError("publish_error", err))...)
+		d.Nack(false, true)
+		return
+	}
+	d.Ack(false)
+}
+
+// retryCount returns how many times the message was retried, from the header set on each retry.
+func retryCount(d amqp.Delivery) int {
+	switch n := d.Headers[retryHeader].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// EventPublisher publishes messages to a durable topic exchange and waits for the broker to
+// confirm each one, so a nil error means the broker has taken responsibility for the message.
+type EventPublisher interface {
+	PublishEvent(ctx context.Context, routingKey, messageID string, body []byte) error
+}
+
+type eventPublisher struct {
+	conn     *amqp.Connection
+	exchange string
+
+	mu      sync.Mutex
+	channel *amqp.Channel
+}
+
+func NewEventPublisher(conn *amqp.Connection, exchange string) EventPublisher {
+	return &eventPublisher{conn: conn, exchange: exchange}
+}
+
+// PublishEvent sends body with messageID, which consumers use to drop the duplicates a relay
+// retrying after a lost confirm produces.
+func (p *eventPublisher) PublishEvent(ctx context.Context, routingKey, messageID string, body []byte) error {
+	// amqp channels are not safe for concurrent publishing
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.getChannel()
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, p.exchange, routingKey, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    messageID,
+		Type:         routingKey,
+		Timestamp:    time.Now(),
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", p.exchange, err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		// The confirm may still arrive, a new channel keeps it from being taken for a later message
+		p.closeChannel()
+		return fmt.Errorf("failed to confirm publish to %s: %w", p.exchange, err)
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected message %s on %s", messageID, p.exchange)
+	}
+	return nil
+}
+
+func (p *eventPublisher) getChannel() (*amqp.Channel, error) {
+	if p.channel != nil && !p.channel.IsClosed() {
+		return p.channel, nil
+	}
+
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	if err := ch.ExchangeDeclare(p.exchange, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", p.exchange, err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	p.channel = ch
+	return ch, nil
+}
+
+func (p *eventPublisher) closeChannel() {
+	if p.channel != nil {
+		p.channel.Close()
+		p.channel = nil
+	}
+}
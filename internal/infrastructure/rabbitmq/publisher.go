@@ -0,0 +1,74 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Publisher sends JSON messages to a durable queue, declared with its dead letter and retry queues.
+type Publisher interface {
+	Publish(ctx context.Context, queue string, payload any) error
+}
+
+type publisher struct {
+	conn *amqp.Connection
+
+	mu      sync.Mutex
+	channel *amqp.Channel
+	queues  map[string]bool
+}
+
+func NewPublisher(conn *amqp.Connection) Publisher {
+	return &publisher{conn: conn, queues: make(map[string]bool)}
+}
+
+func (p *publisher) Publish(ctx context.Context, queue string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	// amqp channels are not safe for concurrent publishing
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.getChannel()
+	if err != nil {
+		return err
+	}
+
+	if !p.queues[queue] {
+		if err := declareQueue(ch, queue); err != nil {
+			return err
+		}
+		p.queues[queue] = true
+	}
+
+	err = ch.PublishWithContext(ctx, "", queue, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", queue, err)
+	}
+	return nil
+}
+
+func (p *publisher) getChannel() (*amqp.Channel, error) {
+	if p.channel != nil && !p.channel.IsClosed() {
+		return p.channel, nil
+	}
+
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	p.channel = ch
+	p.queues = make(map[string]bool)
+	return ch, nil
+}
@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go-boilerplate/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Failure modes applied while Redis is unavailable.
+const (
+	// FailureModeOpen allows every request, with a zero Result.Limit
+	FailureModeOpen = "open"
+	// FailureModeClosed rejects every request with ErrUnavailable
+	FailureModeClosed = "closed"
+	// FailureModeLocal limits each replica with an in-process token bucket
+	FailureModeLocal = "local"
+)
+
+// ErrUnavailable is returned in closed mode while the primary limiter cannot be reached.
+var ErrUnavailable = errors.New("rate limiter unavailable")
+
+type FallbackOptions struct {
+	// Mode is open, closed or local
+	Mode string
+	// Replicas sharing the limits, local mode divides every limit by it
+	Replicas int
+	// Timeout bounds each call to the primary limiter, slower calls count as failures
+	Timeout time.Duration
+	// Threshold consecutive failures open the circuit: the primary limiter is skipped for
+	// Cooldown, then a single call probes whether it is back
+	Threshold int
+	Cooldown  time.Duration
+}
+
+// breaker is a consecutive-failure circuit breaker. While open, calls are not attempted at all,
+// so an outage does not cost a timeout on every request.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a call may be attempted. After the cooldown a single call is let
+// through to probe, the others keep falling back until it reports.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || now.Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// report records the outcome of an allowed call and returns whether the circuit changed state.
+func (b *breaker) report(err error, now time.Time) (opened, closed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := b.failures >= b.threshold
+	b.probing = false
+
+	if err == nil {
+		b.failures = 0
+		return false, wasOpen
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+		return !wasOpen, false
+	}
+	return false, false
+}
+
+// abandon releases a probe that did not complete.
+func (b *breaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+type fallbackLimiter struct {
+	primary Limiter
+	local   Limiter
+	opts    FallbackOptions
+	breaker *breaker
+}
+
+// WithFallback wraps primary, typically Redis backed, so its failures are handled according to
+// opts.Mode instead of being returned, and a failing primary is skipped by a circuit breaker.
+func WithFallback(primary Limiter, opts FallbackOptions) Limiter {
+	l := &fallbackLimiter{
+		primary: primary,
+		opts:    opts,
+		breaker: &breaker{threshold: max(opts.Threshold, 1), cooldown: opts.Cooldown},
+	}
+	switch opts.Mode {
+	case FailureModeOpen, FailureModeClosed:
+	case FailureModeLocal:
+		l.local = NewLocal(opts.Replicas)
+	default:
+		log.Fatalf("Unknown rate limit failure mode %q", opts.Mode)
+	}
+	return l
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if !l.breaker.allow(time.Now()) {
+		return l.fallback(ctx, key, limit)
+	}
+
+	callCtx := ctx
+	if l.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, l.opts.Timeout)
+		defer cancel()
+	}
+	result, err := l.primary.Allow(callCtx, key, limit)
+	if err != nil && ctx.Err() != nil {
+		// The caller went away, which says nothing about the health of the primary limiter
+		l.breaker.abandon()
+		return l.fallback(ctx, key, limit)
+	}
+
+	opened, closed := l.breaker.report(err, time.Now())
+	switch {
+	case opened:
+		logger.ErrorCtx(ctx, "Rate limiter circuit opened, falling back",
+			zap.String("mode", l.opts.Mode), zap.Duration("cooldown", l.opts.Cooldown), zap.Error(err))
+	case closed:
+		logger.InfoCtx(ctx, "Rate limiter circuit closed")
+	case err != nil:
+		logger.WarnCtx(ctx, "Rate limiter call failed, falling back", zap.String("mode", l.opts.Mode), zap.Error(err))
+	}
+	if err != nil {
+		return l.fallback(ctx, key, limit)
+	}
+	return result, nil
+}
+
+func (l *fallbackLimiter) fallback(ctx context.Context, key string, limit Limit) (Result, error) {
+	switch l.opts.Mode {
+	case FailureModeLocal:
+		return l.local.Allow(ctx, key, limit)
+	case FailureModeClosed:
+		return Result{}, ErrUnavailable
+	default:
+		return Result{Allowed: true}, nil
+	}
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that refilled completely are dropped.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	last      time.Time
+	capacity  float64
+	perSecond float64
+}
+
+func (b *bucket) refill(now time.Time) float64 {
+	return math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.perSecond)
+}
+
+type localLimiter struct {
+	replicas int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLocal returns an in-process token bucket limiter. Each replica only sees its own traffic,
+// so every limit is divided by replicas to approximate the global limit.
+func NewLocal(replicas int) Limiter {
+	return &localLimiter{replicas: max(replicas, 1), buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+func (l *localLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	capacity := limit.Burst
+	if capacity <= 0 {
+		capacity = limit.Rate
+	}
+	capacity = max(int(math.Ceil(float64(capacity)/float64(l.replicas))), 1)
+	perSecond := float64(limit.Rate) / float64(l.replicas) / limit.Period.Seconds()
+
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(capacity), last: now}
+		l.buckets[key] = b
+	}
+	b.capacity, b.perSecond = float64(capacity), perSecond
+	b.tokens, b.last = b.refill(now), now
+
+	result := Result{Limit: capacity}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / perSecond)
+	}
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = secondsToDuration((float64(capacity) - b.tokens) / perSecond)
+	return result, nil
+}
+
+// sweep drops the buckets that refilled completely, they are the same as absent ones.
+func (l *localLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.refill(now) >= b.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
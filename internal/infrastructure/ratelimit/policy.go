@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Identities a policy can count requests by.
+const (
+	IdentityIP     = "ip"
+	IdentityUser   = "user"
+	IdentityAPIKey = "api_key"
+	IdentityTenant = "tenant"
+)
+
+// DefaultPolicyName is applied to routes no policy matches.
+const DefaultPolicyName = "default"
+
+// PlanLimit overrides the limits of a policy for the identities on a plan.
+type PlanLimit struct {
+	Limit         int `json:"limit"`
+	WindowSeconds int `json:"window_seconds"`
+	Burst         int `json:"burst"`
+}
+
+// Policy gives the matching routes a quota per identity.
+type Policy struct {
+	Name string `json:"name"`
+	// Routes are gin route patterns, optionally prefixed by a method ("POST /api/v1/auth/login").
+	// A trailing "/*" matches every route below the prefix.
+	Routes []string `json:"routes"`
+	// Identity is ip, user, api_key or tenant. Requests without it are counted by IP.
+	Identity      string               `json:"identity"`
+	Limit         int                  `json:"limit"`
+	WindowSeconds int                  `json:"window_seconds"`
+	Burst         int                  `json:"burst"`
+	Plans         map[string]PlanLimit `json:"plans"`
+}
+
+// Policies is the declarative rate limit configuration.
+type Policies struct {
+	// Policies are matched in order, the first matching route wins
+	Policies []Policy `json:"policies"`
+	// PlanMembers assigns identities to plans, as "<identity>:<value>" like "tenant:<id>"
+	PlanMembers map[string][]string `json:"plan_members"`
+	// Exempt identities are never limited, as "<identity>:<value>" like "ip:10.0.0.5"
+	Exempt []string `json:"exempt"`
+
+	// Applied to routes no policy matches
+	fallback Policy
+	plans    map[string]string
+	exempt   map[string]bool
+}
+
+// LoadPolicies reads the policies in the JSON file at path, or only fallback when path is empty.
+// Fallback is applied to the routes no policy matches.
+func LoadPolicies(path string, fallback Policy) (*Policies, error) {
+	p := &Policies{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rate limit policies: %w", err)
+		}
+		if err := json.Unmarshal(data, p); err != nil {
+			return nil, fmt.Errorf("failed to parse rate limit policies %s: %w", path, err)
+		}
+	}
+	if err := p.init(fallback); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Policies) init(fallback Policy) error {
+	if fallback.Name == "" {
+		fallback.Name = DefaultPolicyName
+	}
+	if fallback.Identity == "" {
+		fallback.Identity = IdentityIP
+	}
+	p.fallback = fallback
+
+	for _, policy := range append([]Policy{fallback}, p.Policies...) {
+		if policy.Name == "" {
+			return fmt.Errorf("rate limit policy for %v has no name", policy.Routes)
+		}
+		switch policy.Identity {
+		case IdentityIP, IdentityUser, IdentityAPIKey, IdentityTenant:
+		default:
+			return fmt.Errorf("rate limit policy %s has unknown identity %q", policy.Name, policy.Identity)
+		}
+		if policy.Limit <= 0 || policy.WindowSeconds <= 0 {
+			return fmt.Errorf("rate limit policy %s needs a positive limit and window", policy.Name)
+		}
+	}
+
+	p.plans = make(map[string]string)
+	for plan, members := range p.PlanMembers {
+		for _, member := range members {
+			p.plans[member] = plan
+		}
+	}
+	p.exempt = make(map[string]bool, len(p.Exempt))
+	for _, identity := range p.Exempt {
+		p.exempt[identity] = true
+	}
+	return nil
+}
+
+// Match returns the policy of the route, the fallback when none matches.
+func (p *Policies) Match(method, route string) Policy {
+	for _, policy := range p.Policies {
+		for _, pattern := range policy.Routes {
+			if routeMatches(pattern, method, route) {
+				return policy
+			}
+		}
+	}
+	return p.fallback
+}
+
+func routeMatches(pattern, method, route string) bool {
+	if m, path, ok := strings.Cut(pattern, " "); ok {
+		if !strings.EqualFold(m, method) {
+			return false
+		}
+		pattern = path
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return route == prefix || strings.HasPrefix(route, prefix+"/")
+	}
+	return route == pattern
+}
+
+// IsKnown reports whether identity, as "<identity>:<value>", is a plan member or exempt.
+func (p *Policies) IsKnown(identity string) bool {
+	return p.exempt[identity] || p.plans[identity] != ""
+}
+
+// IsExempt reports whether any of the identities of a request, as "<identity>:<value>", is
+// never limited.
+func (p *Policies) IsExempt(identities ...string) bool {
+	for _, identity := range identities {
+		if p.exempt[identity] {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitFor returns the limit of policy for a request, with the overrides of the plan of the
+// first of its identities on one. The plan is empty when none is.
+func (p *Policies) LimitFor(policy Policy, identities ...string) (Limit, string) {
+	var plan string
+	for _, identity := range identities {
+		if plan = p.plans[identity]; plan != "" {
+			break
+		}
+	}
+
+	limit, window, burst := policy.Limit, policy.WindowSeconds, policy.Burst
+	if override, ok := policy.Plans[plan]; ok {
+		if override.Limit > 0 {
+			limit = override.Limit
+		}
+		if override.WindowSeconds > 0 {
+			window = override.WindowSeconds
+		}
+		if override.Burst > 0 {
+			burst = override.Burst
+		}
+	}
+	return Limit{Rate: limit, Period: time.Duration(window) * time.Second, Burst: burst}, plan
+}
@@ -0,0 +1,179 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Algorithms selectable with New.
+const (
+	AlgorithmSlidingWindow = "sliding_window"
+	AlgorithmTokenBucket   = "token_bucket"
+)
+
+// keyPrefix namespaces limiter state. Each algorithm adds its own prefix, so switching
+// algorithms never reads state of the wrong Redis type.
+const keyPrefix = "rate_limit:"
+
+// Limit allows Rate requests per Period.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	// Burst is the token bucket capacity, Rate when zero. The sliding window ignores it.
+	Burst int
+}
+
+// Result is the decision for one request.
+type Result struct {
+	Allowed bool
+	// Limit is the most requests that can be made at once, zero when no limit was applied
+	Limit     int
+	Remaining int
+	// ResetAfter is the time until the whole quota is available again
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next request can be allowed, zero when this one was
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether the request identified by key fits in limit. The Redis limiters run
+// each decision as a single Lua script, so their state is always updated and expired atomically.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// New returns the limiter implementing algorithm.
+func New(rdb *redis.Client, algorithm string) Limiter {
+	switch algorithm {
+	case AlgorithmSlidingWindow:
+		return NewSlidingWindow(rdb)
+	case AlgorithmTokenBucket:
+		return NewTokenBucket(rdb)
+	}
+	log.Fatalf("Unknown rate limit algorithm %q", algorithm)
+	return nil
+}
+
+// nowMillis is shared by the scripts. Redis time is used, so replicas with skewed clocks agree.
+const nowMillis = `
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+`
+
+// slidingWindowScript keeps the timestamps of the allowed requests of the last window in a
+// sorted set, so the quota never doubles at window boundaries.
+//
+// KEYS[1] is the set. ARGV[1] is the limit, ARGV[2] the window in ms and ARGV[3] a unique member
+// for this request. Returns allowed, remaining, reset and retry after in ms.
+var slidingWindowScript = redis.NewScript(nowMillis + `
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+if count == 0 then
+	return {allowed, limit, 0, 0}
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local oldest = tonumber(redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')[2])
+local newest = tonumber(redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')[2])
+local retry = 0
+if allowed == 0 then
+	retry = oldest + window - now
+end
+return {allowed, limit - count, newest + window - now, retry}
+`)
+
+type slidingWindow struct {
+	rdb *redis.Client
+}
+
+// NewSlidingWindow returns a sliding-window-log limiter. It counts exactly the requests of the
+// last Period, at the cost of one sorted set entry per allowed request.
+func NewSlidingWindow(rdb *redis.Client) Limiter {
+	return &slidingWindow{rdb: rdb}
+}
+
+func (l *slidingWindow) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	member := strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+	values, err := slidingWindowScript.Run(ctx, l.rdb, []string{keyPrefix + "sw:" + key},
+		limit.Rate, limit.Period.Milliseconds(), member).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run sliding window for %s: %w", key, err)
+	}
+	return newResult(values, limit.Rate), nil
+}
+
+// tokenBucketScript refills the bucket for the time elapsed since the last request and takes
+// one token. The bucket expires once it would be full again, which is the same as absent.
+//
+// KEYS[1] is the bucket hash. ARGV[1] is the capacity, ARGV[2] the rate and ARGV[3] the period
+// in ms. Returns allowed, remaining, reset and retry after in ms.
+var tokenBucketScript = redis.NewScript(nowMillis + `
+local capacity = tonumber(ARGV[1])
+local per_ms = tonumber(ARGV[2]) / tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * per_ms)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / per_ms)
+end
+
+local reset = math.ceil((capacity - tokens) / per_ms)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.max(reset, 1))
+return {allowed, math.floor(tokens), reset, retry}
+`)
+
+type tokenBucket struct {
+	rdb *redis.Client
+}
+
+// NewTokenBucket returns a token bucket limiter. It allows bursts of up to Burst requests and
+// refills at Rate per Period, with constant memory per key.
+func NewTokenBucket(rdb *redis.Client) Limiter {
+	return &tokenBucket{rdb: rdb}
+}
+
+func (l *tokenBucket) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	capacity := limit.Burst
+	if capacity <= 0 {
+		capacity = limit.Rate
+	}
+	values, err := tokenBucketScript.Run(ctx, l.rdb, []string{keyPrefix + "tb:" + key},
+		capacity, limit.Rate, limit.Period.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run token bucket for %s: %w", key, err)
+	}
+	return newResult(values, capacity), nil
+}
+
+func newResult(values []int64, limit int) Result {
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  int(values[1]),
+		ResetAfter: time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
+	}
+}
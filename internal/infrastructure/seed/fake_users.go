@@ -0,0 +1,91 @@
+package seed
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/pkg/tenant"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserBatch is the number of users inserted per COPY.
+const fakeUserBatch = 1000
+
+var (
+	firstNames = []string{
+		"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth",
+		"William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+		"Daniel", "Nancy", "Matthew", "Lisa", "Anthony", "Betty", "Mark", "Sandra", "Andrew", "Ashley",
+		"Budi", "Siti", "Agus", "Dewi", "Ahmad", "Putri", "Hiroshi", "Yuki", "Carlos", "Lucia",
+	}
+	lastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+		"Lee", "Perez", "Thompson", "White", "Harris", "Sanchez", "Clark", "Ramirez", "Lewis", "Robinson",
+		"Santoso", "Wijaya", "Hidayat", "Tanaka", "Suzuki", "Silva", "Rossi", "Muller", "Dubois", "Kowalski",
+	}
+)
+
+// UserCreator inserts users in bulk, implemented by repository.UserRepository.
+type UserCreator interface {
+	BulkCreate(ctx context.Context, users []entity.User) (map[string]bool, error)
+}
+
+type FakeUserOptions struct {
+	Count    int
+	Password string
+	// OrganizationID the users join
+	OrganizationID string
+}
+
+// FakeUsers returns a seed creating users with realistic names, for load tests. They all share
+// opts.Password, hashed with bcrypt once since hashing every user would take minutes. Emails have
+// a random part, so the seed can run again to add more users.
+func FakeUsers(name string, users UserCreator, opts FakeUserOptions) Seed {
+	return Seed{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			hashed, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return fmt.Errorf("failed to hash password: %w", err)
+			}
+
+			ctx = tenant.WithID(ctx, opts.OrganizationID)
+			for created := 0; created < opts.Count; {
+				batch := make([]entity.User, min(fakeUserBatch, opts.Count-created))
+				for i := range batch {
+					batch[i] = fakeUser(string(hashed))
+				}
+				if _, err := users.BulkCreate(ctx, batch); err != nil {
+					return err
+				}
+				created += len(batch)
+			}
+			return nil
+		},
+	}
+}
+
+func fakeUser(hashedPassword string) entity.User {
+	first := firstNames[rand.IntN(len(firstNames))]
+	last := lastNames[rand.IntN(len(lastNames))]
+
+	suffix := make([]byte, 4)
+	for i := range suffix {
+		suffix[i] = byte(rand.Uint32())
+	}
+	email := fmt.Sprintf("%s.%s.%s@example.com", strings.ToLower(first), strings.ToLower(last), hex.EncodeToString(suffix))
+
+	return entity.User{
+		Email:     email,
+		FirstName: first,
+		LastName:  last,
+		Password:  hashedPassword,
+		Role:      entity.RoleUser,
+	}
+}
@@ -0,0 +1,115 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+
+	"go-boilerplate/internal/infrastructure/database"
+)
+
+// Environments with their own seed sets.
+const (
+	EnvironmentDev     = "dev"
+	EnvironmentStaging = "staging"
+	EnvironmentTest    = "test"
+)
+
+// commonDir holds the SQL seeds of every environment, run before those of the environment.
+const commonDir = "common"
+
+// Seed is one unit of seed data. Run gets a ctx carrying the transaction the seed runs in, so
+// repositories called with it join the transaction.
+type Seed struct {
+	// Name identifies the seed in seed_history, the file name for SQL seeds
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Validate reports whether env is a known environment.
+func Validate(env string) error {
+	if !slices.Contains([]string{EnvironmentDev, EnvironmentStaging, EnvironmentTest}, env) {
+		return fmt.Errorf("unknown seed environment %q", env)
+	}
+	return nil
+}
+
+// LoadSQL returns the .sql files of the common and env directories of fsys as seeds executed on
+// the master, common first and each directory in file name order.
+func LoadSQL(fsys fs.FS, env string, db *database.Database) ([]Seed, error) {
+	var seeds []Seed
+	for _, dir := range []string{commonDir, env} {
+		entries, err := fs.ReadDir(fsys, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read seeders in %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+				continue
+			}
+			content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read seeder %s: %w", entry.Name(), err)
+			}
+			query := string(content)
+			seeds = append(seeds, Seed{
+				Name: path.Join(dir, entry.Name()),
+				Run: func(ctx context.Context) error {
+					_, err := db.Writer(ctx).Exec(ctx, query)
+					return err
+				},
+			})
+		}
+	}
+	return seeds, nil
+}
+
+type Runner struct {
+	db *database.Database
+}
+
+func NewRunner(db *database.Database) *Runner {
+	return &Runner{db: db}
+}
+
+// Run applies the seeds of env that are not in seed_history yet, in order, and returns their
+// names. Each seed runs in its own transaction with its history entry, so a failing seed leaves
+// nothing behind and is tried again on the next run. Run stops at the first failure, as later
+// seeds may depend on it.
+func (r *Runner) Run(ctx context.Context, env string, seeds []Seed) ([]string, error) {
+	if err := Validate(env); err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, seed := range seeds {
+		ran := false
+		err := r.db.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+			// Claiming the entry first makes a concurrent run wait on it, then skip the seed
+			tag, err := r.db.Writer(ctx).Exec(ctx,
+				`INSERT INTO seed_history (environment, name) VALUES ($1, $2) ON CONFLICT DO NOTHING`, env, seed.Name)
+			if err != nil {
+				return fmt.Errorf("failed to record seed: %w", err)
+			}
+			if tag.RowsAffected() == 0 {
+				return nil
+			}
+			ran = true
+			return seed.Run(ctx)
+		})
+		if err != nil {
+			return applied, fmt.Errorf("seed %s failed: %w", seed.Name, err)
+		}
+		if ran {
+			applied = append(applied, seed.Name)
+		}
+	}
+	return applied, nil
+}
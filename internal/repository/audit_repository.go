@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/pkg/tenant"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AuditRepository scopes reads and redaction to the tenant in ctx. Create records the tenant set on the event.
+type AuditRepository interface {
+	Create(ctx context.Context, event *entity.AuditEvent) error
+	List(ctx context.Context, req dto.ListAuditEventsRequest) ([]entity.AuditEvent, int64, error)
+	ListByUser(ctx context.Context, userID string) ([]entity.AuditEvent, error)
+	RedactUser(ctx context.Context, userID string) error
+}
+
+type auditRepository struct {
+	db *database.Database
+}
+
+func NewAuditRepository(db *database.Database) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create writes an audit event, joining the transaction in ctx if there is one.
+func (r *auditRepository) Create(ctx context.Context, event *entity.AuditEvent) error {
+	ctx, span := tracer.StartSpan(ctx, "AuditRepository.Create", "repository")
+	defer span.End()
+
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit changes: %w", err)
+	}
+
+	query := `INSERT INTO audit_events (actor_id, action, resource_type, resource_id, changes, ip, user_agent, trace_id, tenant_id)
+              VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::uuid) RETURNING id, created_at`
+
+	// Master for Create
+	err = r.db.Writer(ctx).QueryRow(ctx, query,
+		event.ActorID, event.Action, event.ResourceType, event.ResourceID,
+		changes, event.IP, event.UserAgent, event.TraceID, event.TenantID,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+	return nil
+}
+
+func (r *auditRepository) List(ctx context.Context, req dto.ListAuditEventsRequest) ([]entity.AuditEvent, int64, error) {
+	ctx, span := tracer.StartSpan(ctx, "AuditRepository.List", "repository")
+	defer span.End()
+
+	var conditions []string
+	var args []any
+	addFilter := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	tenantID, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tenantID != "" {
+		addFilter(tenantIDClause, tenantID)
+	}
+	if req.ActorID != "" {
+		addFilter("actor_id::text = $%d", req.ActorID)
+	}
+	if req.Action != "" {
+		addFilter("action = $%d", req.Action)
+	}
+	if req.ResourceType != "" {
+		addFilter("resource_type = $%d", req.ResourceType)
+	}
+	if req.ResourceID != "" {
+		addFilter("resource_id = $%d", req.ResourceID)
+	}
+	if !req.From.IsZero() {
+		addFilter("created_at >= $%d", req.From)
+	}
+	if !req.To.IsZero() {
+		addFilter("created_at < $%d", req.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total
+	var total int64
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM audit_events %s`, where)
+
+	// Slave for Read
+	if err := r.db.Reader(ctx).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	query := fmt.Sprintf(`SELECT id, COALESCE(tenant_id::text, ''), COALESCE(actor_id::text, ''), action, resource_type,
+                                 resource_id, changes, ip, user_agent, trace_id, created_at
+                          FROM audit_events %s
+                          ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	args = append(args, req.Limit, offset)
+
+	// Slave for Read
+	rows, err := r.db.Reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events, err := scanAuditEvents(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// ListByUser returns every event the user performed or that targeted the user, oldest first.
+func (r *auditRepository) ListByUser(ctx context.Context, userID string) ([]entity.AuditEvent, error) {
+	ctx, span := tracer.StartSpan(ctx, "AuditRepository.ListByUser", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, tenantIDClause, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT id, COALESCE(tenant_id::text, ''), COALESCE(actor_id::text, ''), action, resource_type,
+                     resource_id, changes, ip, user_agent, trace_id, created_at
+              FROM audit_events
+              WHERE (actor_id::text = $1 OR (resource_type = $2 AND resource_id = $1))%s
+              ORDER BY created_at`, scope)
+
+	// Slave for Read
+	rows, err := r.db.Reader(ctx).Query(ctx, query, append([]any{userID, entity.AuditResourceUser}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events by user: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAuditEvents(rows)
+}
+
+// RedactUser removes the user's personal data from the audit trail while keeping the events themselves.
+func (r *auditRepository) RedactUser(ctx context.Context, userID string) error {
+	ctx, span := tracer.StartSpan(ctx, "AuditRepository.RedactUser", "repository")
+	defer span.End()
+
+	actorScope, actorArgs, err := tenantCondition(ctx, tenantIDClause, 2)
+	if err != nil {
+		return err
+	}
+	resourceScope, resourceArgs, err := tenantCondition(ctx, tenantIDClause, 3)
+	if err != nil {
+		return err
+	}
+
+	// Master for Update
+	if _, err := r.db.Writer(ctx).Exec(ctx,
+		fmt.Sprintf(`UPDATE audit_events SET ip = '', user_agent = '' WHERE actor_id::text = $1%s`, actorScope),
+		append([]any{userID}, actorArgs...)...); err != nil {
+		return fmt.Errorf("failed to redact audit actor: %w", err)
+	}
+	if _, err := r.db.Writer(ctx).Exec(ctx,
+		fmt.Sprintf(`UPDATE audit_events SET changes = '{}' WHERE resource_type = $1 AND resource_id = $2%s`, resourceScope),
+		append([]any{entity.AuditResourceUser, userID}, resourceArgs...)...); err != nil {
+		return fmt.Errorf("failed to redact audit changes: %w", err)
+	}
+	return nil
+}
+
+func scanAuditEvents(rows pgx.Rows) ([]entity.AuditEvent, error) {
+	events := []entity.AuditEvent{}
+	for rows.Next() {
+		var event entity.AuditEvent
+		var changes []byte
+		if err := rows.Scan(&event.ID, &event.TenantID, &event.ActorID, &event.Action, &event.ResourceType, &event.ResourceID,
+			&changes, &event.IP, &event.UserAgent, &event.TraceID, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if err := json.Unmarshal(changes, &event.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode audit changes: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrInvitationExists is returned by Create when the address already has a pending invitation.
+var ErrInvitationExists = errors.New("pending invitation already exists")
+
+// invitationTenantClause restricts invitations to the tenant's organization
+const invitationTenantClause = `organization_id = $%d`
+
+// InvitationRepository scopes every method to the tenant in ctx. Accepting an invitation
+// happens before the invitee has a tenant, so that path loads it with a system context.
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *entity.Invitation) error
+	GetByID(ctx context.Context, id string) (*entity.Invitation, error)
+	ListPending(ctx context.Context) ([]entity.Invitation, error)
+	UpdateToken(ctx context.Context, invitation *entity.Invitation) error
+	UpdateStatus(ctx context.Context, id string, status entity.InvitationStatus) error
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+type invitationRepository struct {
+	db *database.Database
+}
+
+func NewInvitationRepository(db *database.Database) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+const invitationColumns = `id, organization_id, email, role, status, token_hash, COALESCE(invited_by::text, ''),
+                     expires_at, accepted_at, created_at, updated_at`
+
+func scanInvitation(row pgx.Row, inv *entity.Invitation) error {
+	return row.Scan(
+		&inv.ID, &inv.OrganizationID, &inv.Email, &inv.Role, &inv.Status, &inv.TokenHash, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.AcceptedAt, &inv.CreatedAt, &inv.UpdatedAt,
+	)
+}
+
+func (r *invitationRepository) Create(ctx context.Context, inv *entity.Invitation) error {
+	ctx, span := tracer.StartSpan(ctx, "InvitationRepository.Create", "repository")
+	defer span.End()
+
+	if inv.Status == "" {
+		inv.Status = entity.InvitationStatusPending
+	}
+
+	query := `INSERT INTO invitations (organization_id, email, role, status, token_hash, invited_by, expires_at)
+              VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7) RETURNING id, created_at, updated_at`
+
+	// Master for Create
+	err := r.db.Writer(ctx).QueryRow(ctx, query,
+		inv.OrganizationID, inv.Email, inv.Role, inv.Status, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt,
+	).Scan(&inv.ID, &inv.CreatedAt, &inv.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrInvitationExists
+		}
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *invitationRepository) GetByID(ctx context.Context, id string) (*entity.Invitation, error) {
+	ctx, span := tracer.StartSpan(ctx, "InvitationRepository.GetByID", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, invitationTenantClause, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM invitations WHERE id = $1%s`, invitationColumns, scope)
+
+	var inv entity.Invitation
+	// Master so a resent token is visible right away
+	err = scanInvitation(r.db.Writer(ctx).QueryRow(ctx, query, append([]any{id}, scopeArgs...)...), &inv)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("invitation not found")
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return &inv, nil
+}
+
+// ListPending returns pending invitations of the tenant, newest first. Expired ones are included
+// so they can be resent.
+func (r *invitationRepository) ListPending(ctx context.Context) ([]entity.Invitation, error) {
+	ctx, span := tracer.StartSpan(ctx, "InvitationRepository.ListPending", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, invitationTenantClause, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM invitations WHERE status = $1%s ORDER BY created_at DESC`, invitationColumns, scope)
+
+	// Slave for Read
+	rows, err := r.db.Reader(ctx).Query(ctx, query, append([]any{entity.InvitationStatusPending}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []entity.Invitation{}
+	for rows.Next() {
+		var inv entity.Invitation
+		if err := scanInvitation(rows, &inv); err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// UpdateToken stores a freshly issued token hash and expiry on a pending invitation.
+func (r *invitationRepository) UpdateToken(ctx context.Context, inv *entity.Invitation) error {
+	ctx, span := tracer.StartSpan(ctx, "InvitationRepository.UpdateToken", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, invitationTenantClause, 5)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE invitations SET token_hash = $1, expires_at = $2, updated_at = CURRENT_TIMESTAMP
+              WHERE id = $3 AND status = $4%s RETURNING updated_at`, scope)
+
+	// Master for Update
+	args := append([]any{inv.TokenHash, inv.ExpiresAt, inv.ID, entity.InvitationStatusPending}, scopeArgs...)
+	if err := r.db.Writer(ctx).QueryRow(ctx, query, args...).Scan(&inv.UpdatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("invitation not found or no longer pending")
+		}
+		return fmt.Errorf("failed to update invitation token: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus moves a pending invitation to status. Accepted invitations get accepted_at set.
+func (r *invitationRepository) UpdateStatus(ctx context.Context, id string, status entity.InvitationStatus) error {
+	ctx, span := tracer.StartSpan(ctx, "InvitationRepository.UpdateStatus", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, invitationTenantClause, 4)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE invitations SET status = $1, updated_at = CURRENT_TIMESTAMP,
+                  accepted_at = CASE WHEN $1 = 'accepted' THEN CURRENT_TIMESTAMP ELSE accepted_at END
+              WHERE id = $2 AND status = $3%s`, scope)
+
+	// Master for Update
+	args := append([]any{status, id, entity.InvitationStatusPending}, scopeArgs...)
+	tag, err := r.db.Writer(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("invitation not found or no longer pending")
+	}
+	return nil
+}
+
+// DeleteByUser deletes every invitation, in any status, sent to the email address of the user.
+// It must run before the address is anonymized.
+func (r *invitationRepository) DeleteByUser(ctx context.Context, userID string) error {
+	ctx, span := tracer.StartSpan(ctx, "InvitationRepository.DeleteByUser", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, invitationTenantClause, 2)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`DELETE FROM invitations
+              WHERE lower(email) = (SELECT lower(email) FROM users WHERE id = $1)%s`, scope)
+
+	// Master for Delete
+	if _, err := r.db.Writer(ctx).Exec(ctx, query, append([]any{userID}, scopeArgs...)...); err != nil {
+		return fmt.Errorf("failed to delete invitations: %w", err)
+	}
+	return nil
+}
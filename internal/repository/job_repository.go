@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// JobRepository scopes Create, GetByID and ListByCreator to the tenant in ctx. The remaining methods
+// act on a job ID that was obtained through GetByID.
+type JobRepository interface {
+	Create(ctx context.Context, job *entity.Job) error
+	GetByID(ctx context.Context, id string) (*entity.Job, error)
+	ListByCreator(ctx context.Context, userID string) ([]entity.Job, error)
+	Claim(ctx context.Context, id string) (bool, error)
+	ReclaimStale(ctx context.Context, id string, staleBefore time.Time) (bool, error)
+	ListStale(ctx context.Context, jobType entity.JobType, staleBefore time.Time) ([]string, error)
+	UpdateStatus(ctx context.Context, id string, status entity.JobStatus, errMessage string) error
+	UpdateProgress(ctx context.Context, job *entity.Job) error
+	AddErrors(ctx context.Context, jobID string, jobErrors []entity.JobError) error
+	ListErrors(ctx context.Context, jobID string, limit int) ([]entity.JobError, error)
+}
+
+type jobRepository struct {
+	db *database.Database
+}
+
+func NewJobRepository(db *database.Database) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *entity.Job) error {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.Create", "repository")
+	defer span.End()
+
+	if job.Status == "" {
+		job.Status = entity.JobStatusPending
+	}
+
+	tenantID, err := tenantArg(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO jobs (type, status, object_key, created_by, tenant_id)
+              VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at`
+
+	// Master for Create
+	err = r.db.Writer(ctx).QueryRow(ctx, query, job.Type, job.Status, job.ObjectKey, job.CreatedBy, tenantID).
+		Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	if tenantID != nil {
+		job.TenantID = tenantID.(string)
+	}
+	return nil
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id string) (*entity.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.GetByID", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, tenantIDClause, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT id, COALESCE(tenant_id::text, ''), type, status, object_key, total_rows, processed_rows,
+                     succeeded_rows, failed_rows, error, COALESCE(created_by::text, ''), created_at, updated_at, completed_at
+              FROM jobs WHERE id = $1%s`, scope)
+
+	var job entity.Job
+	// Master so workers and pollers always see the latest progress
+	err = r.db.Writer(ctx).QueryRow(ctx, query, append([]any{id}, scopeArgs...)...).Scan(
+		&job.ID, &job.TenantID, &job.Type, &job.Status, &job.ObjectKey, &job.TotalRows, &job.ProcessedRows,
+		&job.SucceededRows, &job.FailedRows, &job.Error, &job.CreatedBy, &job.CreatedAt,
+		&job.UpdatedAt, &job.CompletedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("job not found")
+		}
+		return nil, fmt.Errorf("failed to get job by id: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) ListByCreator(ctx context.Context, userID string) ([]entity.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.ListByCreator", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, tenantIDClause, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT id, COALESCE(tenant_id::text, ''), type, status, object_key, total_rows, processed_rows,
+                     succeeded_rows, failed_rows, error, COALESCE(created_by::text, ''), created_at, updated_at, completed_at
+              FROM jobs WHERE created_by = $1%s ORDER BY created_at`, scope)
+
+	// Slave for Read
+	rows, err := r.db.Reader(ctx).Query(ctx, query, append([]any{userID}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs by creator: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []entity.Job{}
+	for rows.Next() {
+		var job entity.Job
+		if err := rows.Scan(
+			&job.ID, &job.TenantID, &job.Type, &job.Status, &job.ObjectKey, &job.TotalRows, &job.ProcessedRows,
+			&job.SucceededRows, &job.FailedRows, &job.Error, &job.CreatedBy, &job.CreatedAt,
+			&job.UpdatedAt, &job.CompletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Claim moves a pending job to processing and reports whether it did. When several workers get the
+// same job, only one of them claims it.
+func (r *jobRepository) Claim(ctx context.Context, id string) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.Claim", "repository")
+	defer span.End()
+
+	query := `UPDATE jobs SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = $3`
+
+	// Master for Update
+	tag, err := r.db.Writer(ctx).Exec(ctx, query, entity.JobStatusProcessing, id, entity.JobStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ReclaimStale takes over a processing job whose worker stopped reporting progress before staleBefore,
+// and reports whether it did. The progress and errors of the abandoned run are cleared so the job
+// starts over.
+func (r *jobRepository) ReclaimStale(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.ReclaimStale", "repository")
+	defer span.End()
+
+	query := `WITH reclaimed AS (
+                  UPDATE jobs SET total_rows = 0, processed_rows = 0, succeeded_rows = 0, failed_rows = 0,
+                         updated_at = CURRENT_TIMESTAMP
+                  WHERE id = $1 AND status = $2 AND updated_at < $3
+                  RETURNING id
+              ), cleared AS (
+                  DELETE FROM job_errors WHERE job_id IN (SELECT id FROM reclaimed)
+              )
+              SELECT count(*) FROM reclaimed`
+
+	var count int
+	// Master for Update
+	if err := r.db.Writer(ctx).QueryRow(ctx, query, id, entity.JobStatusProcessing, staleBefore).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to reclaim job: %w", err)
+	}
+	return count == 1, nil
+}
+
+// ListStale returns the processing jobs of a type whose progress was last reported before staleBefore.
+func (r *jobRepository) ListStale(ctx context.Context, jobType entity.JobType, staleBefore time.Time) ([]string, error) {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.ListStale", "repository")
+	defer span.End()
+
+	query := `SELECT id FROM jobs WHERE type = $1 AND status = $2 AND updated_at < $3 ORDER BY updated_at`
+
+	// Master, a lagging replica would report jobs that already made progress
+	rows, err := r.db.Writer(ctx).Query(ctx, query, jobType, entity.JobStatusProcessing, staleBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan job id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stale jobs: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (r *jobRepository) UpdateStatus(ctx context.Context, id string, status entity.JobStatus, errMessage string) error {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.UpdateStatus", "repository")
+	defer span.End()
+
+	query := `UPDATE jobs SET status = $1, error = $2, updated_at = CURRENT_TIMESTAMP,
+                     completed_at = CASE WHEN $1 IN ('completed', 'failed') THEN CURRENT_TIMESTAMP ELSE completed_at END
+              WHERE id = $3`
+
+	// Master for Update
+	tag, err := r.db.Writer(ctx).Exec(ctx, query, status, errMessage, id)
+	if err != nil {
+		return fmt.Errorf("failed to update job status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job not found")
+	}
+	return nil
+}
+
+func (r *jobRepository) UpdateProgress(ctx context.Context, job *entity.Job) error {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.UpdateProgress", "repository")
+	defer span.End()
+
+	query := `UPDATE jobs SET total_rows = $1, processed_rows = $2, succeeded_rows = $3, failed_rows = $4,
+                     updated_at = CURRENT_TIMESTAMP
+              WHERE id = $5`
+
+	// Master for Update
+	_, err := r.db.Writer(ctx).Exec(ctx, query, job.TotalRows, job.ProcessedRows, job.SucceededRows, job.FailedRows, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update job progress: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) AddErrors(ctx context.Context, jobID string, jobErrors []entity.JobError) error {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.AddErrors", "repository")
+	defer span.End()
+
+	if len(jobErrors) == 0 {
+		return nil
+	}
+
+	rows := make([][]any, len(jobErrors))
+	for i, e := range jobErrors {
+		rows[i] = []any{jobID, e.Row, e.Email, e.Message}
+	}
+
+	// Master for Create
+	_, err := r.db.Writer(ctx).CopyFrom(ctx,
+		pgx.Identifier{"job_errors"},
+		[]string{"job_id", "row_number", "email", "message"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add job errors: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) ListErrors(ctx context.Context, jobID string, limit int) ([]entity.JobError, error) {
+	ctx, span := tracer.StartSpan(ctx, "JobRepository.ListErrors", "repository")
+	defer span.End()
+
+	query := `SELECT row_number, email, message FROM job_errors
+              WHERE job_id = $1 ORDER BY row_number LIMIT $2`
+
+	rows, err := r.db.Writer(ctx).Query(ctx, query, jobID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job errors: %w", err)
+	}
+	defer rows.Close()
+
+	jobErrors := []entity.JobError{}
+	for rows.Next() {
+		e := entity.JobError{JobID: jobID}
+		if err := rows.Scan(&e.Row, &e.Email, &e.Message); err != nil {
+			return nil, fmt.Errorf("failed to scan job error: %w", err)
+		}
+		jobErrors = append(jobErrors, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job errors: %w", err)
+	}
+
+	return jobErrors, nil
+}
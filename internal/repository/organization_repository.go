@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/pkg/tracer"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OrganizationRepository resolves tenants themselves, so unlike tenant-scoped repositories
+// it is keyed by explicit organization and user IDs rather than the tenant in ctx.
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *entity.Organization) error
+	AddMember(ctx context.Context, membership *entity.Membership) error
+	GetMembership(ctx context.Context, organizationID, userID string) (*entity.Membership, error)
+	ListMemberships(ctx context.Context, userID string) ([]entity.Membership, error)
+	ListMembers(ctx context.Context, organizationID string) ([]entity.Membership, error)
+	RemoveMember(ctx context.Context, organizationID, userID string) error
+	RemoveUser(ctx context.Context, userID string) error
+}
+
+type organizationRepository struct {
+	db *database.Database
+}
+
+func NewOrganizationRepository(db *database.Database) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *entity.Organization) error {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationRepository.Create", "repository")
+	defer span.End()
+
+	query := `INSERT INTO organizations (name) VALUES ($1) RETURNING id, created_at, updated_at`
+
+	// Master for Create
+	err := r.db.Writer(ctx).QueryRow(ctx, query, org.Name).Scan(&org.ID, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepository) AddMember(ctx context.Context, membership *entity.Membership) error {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationRepository.AddMember", "repository")
+	defer span.End()
+
+	query := `INSERT INTO memberships (organization_id, user_id, role) VALUES ($1, $2, $3) RETURNING created_at`
+
+	// Master for Create
+	err := r.db.Writer(ctx).QueryRow(ctx, query, membership.OrganizationID, membership.UserID, membership.Role).
+		Scan(&membership.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add member: %w", err)
+	}
+	return nil
+}
+
+func (r *organizationRepository) GetMembership(ctx context.Context, organizationID, userID string) (*entity.Membership, error) {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationRepository.GetMembership", "repository")
+	defer span.End()
+
+	query := `SELECT m.organization_id, o.name, m.user_id, m.role, m.created_at
+              FROM memberships m JOIN organizations o ON o.id = m.organization_id
+              WHERE m.organization_id = $1 AND m.user_id = $2`
+
+	var m entity.Membership
+	// Master so a membership is visible right after it is created
+	err := r.db.Writer(ctx).QueryRow(ctx, query, organizationID, userID).
+		Scan(&m.OrganizationID, &m.OrganizationName, &m.UserID, &m.Role, &m.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("membership not found")
+		}
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	return &m, nil
+}
+
+// ListMemberships returns the organizations a user belongs to, oldest membership first.
+func (r *organizationRepository) ListMemberships(ctx context.Context, userID string) ([]entity.Membership, error) {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationRepository.ListMemberships", "repository")
+	defer span.End()
+
+	query := `SELECT m.organization_id, o.name, m.user_id, m.role, m.created_at
+              FROM memberships m JOIN organizations o ON o.id = m.organization_id
+              WHERE m.user_id = $1
+              ORDER BY m.created_at, o.name`
+
+	// Master so a membership is visible right after it is created
+	rows, err := r.db.Writer(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := []entity.Membership{}
+	for rows.Next() {
+		var m entity.Membership
+		if err := rows.Scan(&m.OrganizationID, &m.OrganizationName, &m.UserID, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan membership: %w", err)
+		}
+		memberships = append(memberships, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating memberships: %w", err)
+	}
+
+	return memberships, nil
+}
+
+// ListMembers returns the members of an organization with their email, oldest membership first.
+func (r *organizationRepository) ListMembers(ctx context.Context, organizationID string) ([]entity.Membership, error) {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationRepository.ListMembers", "repository")
+	defer span.End()
+
+	query := `SELECT m.organization_id, o.name, m.user_id, u.email, m.role, m.created_at
+              FROM memberships m
+              JOIN organizations o ON o.id = m.organization_id
+              JOIN users u ON u.id = m.user_id
+              WHERE m.organization_id = $1 AND u.deleted_at IS NULL
+              ORDER BY m.created_at, u.email`
+
+	// Slave for Read
+	rows, err := r.db.Reader(ctx).Query(ctx, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []entity.Membership{}
+	for rows.Next() {
+		var m entity.Membership
+		if err := rows.Scan(&m.OrganizationID, &m.OrganizationName, &m.UserID, &m.UserEmail, &m.Role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationRepository.RemoveMember", "repository")
+	defer span.End()
+
+	query := `DELETE FROM memberships WHERE organization_id = $1 AND user_id = $2`
+
+	// Master for Delete
+	tag, err := r.db.Writer(ctx).Exec(ctx, query, organizationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("membership not found")
+	}
+	return nil
+}
+
+// RemoveUser deletes every membership of the user.
+func (r *organizationRepository) RemoveUser(ctx context.Context, userID string) error {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationRepository.RemoveUser", "repository")
+	defer span.End()
+
+	query := `DELETE FROM memberships WHERE user_id = $1`
+
+	// Master for Delete
+	if _, err := r.db.Writer(ctx).Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to remove memberships: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/pkg/tracer"
+)
+
+// OutboxRepository stores domain events for the outbox relay. Create records the tenant in ctx,
+// the other methods act on the events of every tenant.
+type OutboxRepository interface {
+	Create(ctx context.Context, event *entity.OutboxEvent) error
+	ClaimPending(ctx context.Context, limit int) ([]entity.OutboxEvent, error)
+	MarkSent(ctx context.Context, ids []string) error
+	MarkFailed(ctx context.Context, id string, reason string, maxAttempts int) (bool, error)
+	DeleteSent(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *database.Database
+}
+
+func NewOutboxRepository(db *database.Database) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Create writes an event, joining the transaction in ctx so it commits with the change it describes.
+func (r *outboxRepository) Create(ctx context.Context, event *entity.OutboxEvent) error {
+	ctx, span := tracer.StartSpan(ctx, "OutboxRepository.Create", "repository")
+	defer span.End()
+
+	tenantID, err := tenantArg(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO outbox (tenant_id, aggregate_type, aggregate_id, event_type, payload, trace_id)
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+
+	// Master for Create
+	err = r.db.Writer(ctx).QueryRow(ctx, query,
+		tenantID, event.AggregateType, event.AggregateID, event.EventType, event.Payload, event.TraceID,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+	if tenantID != nil {
+		event.TenantID = tenantID.(string)
+	}
+	return nil
+}
+
+// ClaimPending locks up to limit unsent events, oldest first, until the transaction in ctx ends.
+// Events locked by another relay are skipped, so relays can run side by side. Parked events are
+// not returned.
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit int) ([]entity.OutboxEvent, error) {
+	ctx, span := tracer.StartSpan(ctx, "OutboxRepository.ClaimPending", "repository")
+	defer span.End()
+
+	query := `SELECT id, COALESCE(tenant_id::text, ''), aggregate_type, aggregate_id, event_type, payload,
+                     trace_id, attempts, created_at
+              FROM outbox WHERE sent_at IS NULL AND failed_at IS NULL
+              ORDER BY created_at LIMIT $1 FOR UPDATE SKIP LOCKED`
+
+	rows, err := r.db.Writer(ctx).Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []entity.OutboxEvent{}
+	for rows.Next() {
+		var event entity.OutboxEvent
+		if err := rows.Scan(
+			&event.ID, &event.TenantID, &event.AggregateType, &event.AggregateID, &event.EventType, &event.Payload,
+			&event.TraceID, &event.Attempts, &event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *outboxRepository) MarkSent(ctx context.Context, ids []string) error {
+	ctx, span := tracer.StartSpan(ctx, "OutboxRepository.MarkSent", "repository")
+	defer span.End()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Master for Update
+	_, err := r.db.Writer(ctx).Exec(ctx, `UPDATE outbox SET sent_at = CURRENT_TIMESTAMP WHERE id = ANY($1::uuid[])`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox events sent: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed counts a failed publish of the event, which stays pending until it has failed
+// maxAttempts times. It is then parked, and MarkFailed reports true. Zero means no limit.
+func (r *outboxRepository) MarkFailed(ctx context.Context, id string, reason string, maxAttempts int) (bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "OutboxRepository.MarkFailed", "repository")
+	defer span.End()
+
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $1,
+                     failed_at = CASE WHEN $3 > 0 AND attempts + 1 >= $3 THEN now() END
+              WHERE id = $2 RETURNING failed_at IS NOT NULL`
+
+	var parked bool
+	// Master for Update
+	if err := r.db.Writer(ctx).QueryRow(ctx, query, reason, id, maxAttempts).Scan(&parked); err != nil {
+		return false, fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return parked, nil
+}
+
+// DeleteSent removes the events sent before the given time and returns how many were removed.
+func (r *outboxRepository) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracer.StartSpan(ctx, "OutboxRepository.DeleteSent", "repository")
+	defer span.End()
+
+	// Master for Delete
+	tag, err := r.db.Writer(ctx).Exec(ctx, `DELETE FROM outbox WHERE sent_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sent outbox events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
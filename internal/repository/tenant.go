@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go-boilerplate/pkg/tenant"
+)
+
+const (
+	// userTenantClause restricts users to members of the tenant
+	userTenantClause = `EXISTS (SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = $%d)`
+
+	// tenantIDClause restricts tables that carry their own tenant_id column
+	tenantIDClause = `tenant_id = $%d`
+)
+
+// tenantCondition returns an " AND ..." clause restricting rows to the tenant in ctx, with the tenant
+// bound to placeholder $n of clause. System contexts get no clause. Contexts without a tenant get
+// tenant.ErrMissing, so a caller that forgets the tenant can never read across tenants.
+func tenantCondition(ctx context.Context, clause string, n int) (string, []any, error) {
+	id, err := tenant.Scope(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if id == "" {
+		return "", nil, nil
+	}
+	return " AND " + fmt.Sprintf(clause, n), []any{id}, nil
+}
+
+// tenantArg returns the tenant in ctx for inserts, or nil for system contexts.
+func tenantArg(ctx context.Context) (any, error) {
+	id, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, nil
+	}
+	return id, nil
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"go-boilerplate/internal/dto"
@@ -13,13 +15,36 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// ErrUserNotFound is returned by GetByID and GetByEmail when no live user matches.
+var ErrUserNotFound = errors.New("user not found")
+
+// UserRepository scopes every method to the tenant in ctx, except Create, GetByEmail and
+// FindExistingEmails which work across tenants because emails are unique globally.
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	List(ctx context.Context, req dto.ListUsersRequest, timezone string) ([]entity.User, int64, error)
+	Stream(ctx context.Context, req dto.ListUsersRequest, fn func(user entity.User) error) error
+	Search(ctx context.Context, query string, limit int) ([]entity.UserSearchResult, error)
 	GetByID(ctx context.Context, id string, timezone string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id string) error
+	UpdateRole(ctx context.Context, id string, role string) error
+	UpdateStatus(ctx context.Context, id string, status entity.UserStatus, reason string, expiresAt *time.Time) error
+	LiftExpiredSuspensions(ctx context.Context, now time.Time) ([]string, error)
+	Anonymize(ctx context.Context, id string, email string) error
+	FindExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
+	BulkCreate(ctx context.Context, users []entity.User) (map[string]bool, error)
+}
+
+// userStreamFetchSize is the number of rows pulled from the export cursor per round trip.
+const userStreamFetchSize = 1000
+
+// userOrderColumns whitelists the columns users can be sorted by.
+var userOrderColumns = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"email":      true,
 }
 
 type userRepository struct {
@@ -39,7 +64,7 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
               VALUES ($1, $2) RETURNING id`
 
 	// Master for Create
-	err := r.db.Master.QueryRow(ctx, query, user.Email, user.Password).Scan(&user.ID)
+	err := r.db.Writer(ctx).QueryRow(ctx, query, user.Email, user.Password).Scan(&user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -50,17 +75,17 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 	ctx, span := tracer.StartSpan(ctx, "UserRepository.GetByEmail", "repository")
 	defer span.End()
 
-	query := `SELECT id, email, password FROM users 
+	query := `SELECT id, email, password, role, status, status_reason, status_expires_at FROM users 
               WHERE email = $1 AND deleted_at IS NULL`
 
 	var user entity.User
 	// Slave for Read
-	err := r.db.Slave.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.Password,
+	err := r.db.Reader(ctx).QueryRow(ctx, query, email).Scan(
+		&user.ID, &user.Email, &user.Password, &user.Role, &user.Status, &user.StatusReason, &user.StatusExpiresAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
@@ -75,33 +100,29 @@ func (r *userRepository) List(ctx context.Context, req dto.ListUsersRequest, tim
 		timezone = "UTC"
 	}
 
-	searchQuery := ""
-	if req.Search != "" {
-		searchQuery = fmt.Sprintf(" AND email ILIKE '%%%s%%'", req.Search)
-	}
-
-	if req.Order == "" {
-		req.Order = "created_at desc"
+	filter, args, err := userListFilter(ctx, req)
+	if err != nil {
+		return nil, 0, err
 	}
-
 	offset := (req.Page - 1) * req.Limit
 
 	// Count total
 	var total int64
-	countQuery := fmt.Sprintf(`SELECT count(*) FROM users WHERE deleted_at IS NULL %s`, searchQuery)
+	countQuery := fmt.Sprintf(`SELECT count(*) FROM users WHERE deleted_at IS NULL %s`, filter)
 
 	// Slave for Read
-	if err := r.db.Slave.QueryRow(ctx, countQuery).Scan(&total); err != nil {
+	if err := r.db.Reader(ctx).QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
 	// List users
-	query := fmt.Sprintf(`SELECT id, email, created_at AT TIME ZONE '%s', updated_at AT TIME ZONE '%s' FROM users 
+	query := fmt.Sprintf(`SELECT id, email, first_name, last_name, created_at AT TIME ZONE '%s', updated_at AT TIME ZONE '%s' FROM users 
                           WHERE deleted_at IS NULL %s 
-                          ORDER BY %s LIMIT $1 OFFSET $2`, timezone, timezone, searchQuery, req.Order)
+                          ORDER BY %s LIMIT $%d OFFSET $%d`, timezone, timezone, filter, userListOrder(req.Order), len(args)+1, len(args)+2)
+	args = append(args, req.Limit, offset)
 
 	// Slave for Read
-	rows, err := r.db.Slave.Query(ctx, query, req.Limit, offset)
+	rows, err := r.db.Reader(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -110,7 +131,7 @@ func (r *userRepository) List(ctx context.Context, req dto.ListUsersRequest, tim
 	var users []entity.User
 	for rows.Next() {
 		var user entity.User
-		if err := rows.Scan(&user.ID, &user.Email, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, user)
@@ -123,6 +144,110 @@ func (r *userRepository) List(ctx context.Context, req dto.ListUsersRequest, tim
 	return users, total, nil
 }
 
+// Stream walks every user matching the list filters through a server-side cursor on the replica,
+// fetching a page at a time so the result set is never held in memory. Timestamps are returned as stored.
+func (r *userRepository) Stream(ctx context.Context, req dto.ListUsersRequest, fn func(user entity.User) error) error {
+	ctx, span := tracer.StartSpan(ctx, "UserRepository.Stream", "repository")
+	defer span.End()
+
+	filter, args, err := userListFilter(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	// Slave for Read, cursors only live inside a transaction
+	tx, err := r.db.Slave.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin export transaction: %w", err)
+	}
+	// Read-only work, nothing to commit
+	defer tx.Rollback(ctx)
+
+	declare := fmt.Sprintf(`DECLARE user_export NO SCROLL CURSOR FOR
+                            SELECT id, email, first_name, last_name, role, created_at, updated_at FROM users
+                            WHERE deleted_at IS NULL %s
+                            ORDER BY %s`, filter, userListOrder(req.Order))
+	if _, err := tx.Exec(ctx, declare, args...); err != nil {
+		return fmt.Errorf("failed to declare export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf(`FETCH FORWARD %d FROM user_export`, userStreamFetchSize)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("failed to fetch users: %w", err)
+		}
+
+		fetched := 0
+		for rows.Next() {
+			var user entity.User
+			if err := rows.Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan user: %w", err)
+			}
+			fetched++
+			if err := fn(user); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating users: %w", err)
+		}
+		if fetched < userStreamFetchSize {
+			return nil
+		}
+	}
+}
+
+// Search ranks users by trigram word similarity against email and name, boosting prefix matches
+// so type-ahead queries surface the expected users first.
+func (r *userRepository) Search(ctx context.Context, query string, limit int) ([]entity.UserSearchResult, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserRepository.Search", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, userTenantClause, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf(`SELECT id, email, first_name, last_name, created_at, updated_at,
+                   GREATEST(word_similarity($1, email), word_similarity($1, first_name), word_similarity($1, last_name))
+                   + CASE WHEN email ILIKE $2 OR first_name ILIKE $2 OR last_name ILIKE $2 THEN 1 ELSE 0 END AS score
+            FROM users
+            WHERE deleted_at IS NULL%s
+              AND ($1 <%% email OR $1 <%% first_name OR $1 <%% last_name
+                   OR email ILIKE $2 OR first_name ILIKE $2 OR last_name ILIKE $2)
+            ORDER BY score DESC, email
+            LIMIT $3`, scope)
+	args := append([]any{query, escapeLike(query) + "%", limit}, scopeArgs...)
+
+	// Slave for Read
+	rows, err := r.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	results := []entity.UserSearchResult{}
+	for rows.Next() {
+		var result entity.UserSearchResult
+		if err := rows.Scan(&result.ID, &result.Email, &result.FirstName, &result.LastName,
+			&result.CreatedAt, &result.UpdatedAt, &result.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return results, nil
+}
+
 func (r *userRepository) GetByID(ctx context.Context, id string, timezone string) (*entity.User, error) {
 	ctx, span := tracer.StartSpan(ctx, "UserRepository.GetByID", "repository")
 	defer span.End()
@@ -131,17 +256,24 @@ func (r *userRepository) GetByID(ctx context.Context, id string, timezone string
 		timezone = "UTC"
 	}
 
-	query := fmt.Sprintf(`SELECT id, email, password, created_at AT TIME ZONE '%s', updated_at AT TIME ZONE '%s' FROM users 
-              WHERE id = $1 AND deleted_at IS NULL`, timezone, timezone)
+	scope, scopeArgs, err := tenantCondition(ctx, userTenantClause, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT id, email, first_name, last_name, password, role, created_at AT TIME ZONE '%s', updated_at AT TIME ZONE '%s',
+                     status, status_reason, status_expires_at FROM users 
+              WHERE id = $1 AND deleted_at IS NULL%s`, timezone, timezone, scope)
 
 	var user entity.User
 	// Slave for Read
-	err := r.db.Slave.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt,
+	err = r.db.Reader(ctx).QueryRow(ctx, query, append([]any{id}, scopeArgs...)...).Scan(
+		&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+		&user.Status, &user.StatusReason, &user.StatusExpiresAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by id: %w", err)
 	}
@@ -152,11 +284,16 @@ func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
 	ctx, span := tracer.StartSpan(ctx, "UserRepository.Update", "repository")
 	defer span.End()
 
-	query := `UPDATE users SET email = $1, password = $2, updated_at = CURRENT_TIMESTAMP 
-              WHERE id = $3 AND deleted_at IS NULL RETURNING id`
+	scope, scopeArgs, err := tenantCondition(ctx, userTenantClause, 4)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE users SET email = $1, password = $2, updated_at = CURRENT_TIMESTAMP 
+              WHERE id = $3 AND deleted_at IS NULL%s RETURNING id`, scope)
 
 	// Master for Update
-	err := r.db.Master.QueryRow(ctx, query, user.Email, user.Password, user.ID).Scan(&user.ID)
+	err = r.db.Writer(ctx).QueryRow(ctx, query, append([]any{user.Email, user.Password, user.ID}, scopeArgs...)...).Scan(&user.ID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return fmt.Errorf("user not found or deleted")
@@ -166,15 +303,126 @@ func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
 	return nil
 }
 
+func (r *userRepository) UpdateRole(ctx context.Context, id string, role string) error {
+	ctx, span := tracer.StartSpan(ctx, "UserRepository.UpdateRole", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, userTenantClause, 3)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP 
+              WHERE id = $2 AND deleted_at IS NULL%s`, scope)
+
+	// Master for Update
+	tag, err := r.db.Writer(ctx).Exec(ctx, query, append([]any{role, id}, scopeArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found or deleted")
+	}
+	return nil
+}
+
+// UpdateStatus sets the status of a user that is not deleted, with its reason and optional expiry.
+func (r *userRepository) UpdateStatus(ctx context.Context, id string, status entity.UserStatus, reason string, expiresAt *time.Time) error {
+	ctx, span := tracer.StartSpan(ctx, "UserRepository.UpdateStatus", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, userTenantClause, 5)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE users SET status = $1, status_reason = $2, status_expires_at = $3, updated_at = CURRENT_TIMESTAMP 
+              WHERE id = $4 AND deleted_at IS NULL%s`, scope)
+
+	// Master for Update
+	tag, err := r.db.Writer(ctx).Exec(ctx, query, append([]any{status, reason, expiresAt, id}, scopeArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found or deleted")
+	}
+	return nil
+}
+
+// LiftExpiredSuspensions reactivates every suspension that expired by now and returns the affected user IDs.
+// It is not tenant-scoped, the scheduler runs it for all organizations.
+func (r *userRepository) LiftExpiredSuspensions(ctx context.Context, now time.Time) ([]string, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserRepository.LiftExpiredSuspensions", "repository")
+	defer span.End()
+
+	query := `UPDATE users SET status = $1, status_reason = '', status_expires_at = NULL, updated_at = CURRENT_TIMESTAMP 
+              WHERE status = $2 AND status_expires_at <= $3 AND deleted_at IS NULL
+              RETURNING id`
+
+	// Master for Update
+	rows, err := r.db.Writer(ctx).Query(ctx, query, entity.UserStatusActive, entity.UserStatusSuspended, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lift suspensions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan lifted user: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lifted users: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Anonymize replaces the user's personal data with the given placeholder email and soft deletes the row.
+// It also applies to users that are already soft deleted.
+func (r *userRepository) Anonymize(ctx context.Context, id string, email string) error {
+	ctx, span := tracer.StartSpan(ctx, "UserRepository.Anonymize", "repository")
+	defer span.End()
+
+	scope, scopeArgs, err := tenantCondition(ctx, userTenantClause, 3)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE users SET email = $1, first_name = '', last_name = '', password = '',
+                     updated_at = CURRENT_TIMESTAMP, deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP)
+              WHERE id = $2%s`, scope)
+
+	// Master for Update
+	tag, err := r.db.Writer(ctx).Exec(ctx, query, append([]any{email, id}, scopeArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
 func (r *userRepository) Delete(ctx context.Context, id string) error {
 	ctx, span := tracer.StartSpan(ctx, "UserRepository.Delete", "repository")
 	defer span.End()
 
+	scope, scopeArgs, err := tenantCondition(ctx, userTenantClause, 3)
+	if err != nil {
+		return err
+	}
+
 	deletedAt := time.Now()
-	query := `UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	query := fmt.Sprintf(`UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL%s`, scope)
 
 	// Master for Delete
-	tag, err := r.db.Master.Exec(ctx, query, deletedAt, id)
+	tag, err := r.db.Writer(ctx).Exec(ctx, query, append([]any{deletedAt, id}, scopeArgs...)...)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -183,3 +431,140 @@ func (r *userRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// FindExistingEmails returns the subset of emails that already belong to a user, including soft-deleted ones
+// since the unique constraint on email still applies to them.
+func (r *userRepository) FindExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserRepository.FindExistingEmails", "repository")
+	defer span.End()
+
+	existing := make(map[string]bool)
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	query := `SELECT email FROM users WHERE email = ANY($1)`
+
+	// Master to avoid missing rows inserted by a previous batch that has not replicated yet
+	rows, err := r.db.Writer(ctx).Query(ctx, query, emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find existing emails: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan email: %w", err)
+		}
+		existing[email] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating emails: %w", err)
+	}
+
+	return existing, nil
+}
+
+// BulkCreate inserts users and makes them members of the tenant in ctx. Rows whose email is already
+// taken are skipped rather than failing the batch; it returns the emails that were inserted.
+func (r *userRepository) BulkCreate(ctx context.Context, users []entity.User) (map[string]bool, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserRepository.BulkCreate", "repository")
+	defer span.End()
+
+	if len(users) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	tenantID, err := tenantArg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make([]string, len(users))
+	firstNames := make([]string, len(users))
+	lastNames := make([]string, len(users))
+	passwords := make([]string, len(users))
+	roles := make([]string, len(users))
+	for i, user := range users {
+		role := user.Role
+		if role == "" {
+			role = entity.RoleUser
+		}
+		emails[i], firstNames[i], lastNames[i], passwords[i], roles[i] = user.Email, user.FirstName, user.LastName, user.Password, role
+	}
+
+	// The membership insert reads the new rows from the first CTE, so both happen in one statement
+	query := `WITH inserted AS (
+                  INSERT INTO users (email, first_name, last_name, password, role)
+                  SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[])
+                  ON CONFLICT (email) DO NOTHING
+                  RETURNING id, email
+              ), members AS (
+                  INSERT INTO memberships (organization_id, user_id, role)
+                  SELECT $6::uuid, id, $7 FROM inserted WHERE $6::uuid IS NOT NULL
+              )
+              SELECT email FROM inserted`
+
+	// Master for Create
+	rows, err := r.db.Writer(ctx).Query(ctx, query, emails, firstNames, lastNames, passwords, roles,
+		tenantID, entity.MembershipRoleMember)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bulk create users: %w", err)
+	}
+	defer rows.Close()
+
+	created := make(map[string]bool, len(users))
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan created email: %w", err)
+		}
+		created[email] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to bulk create users: %w", err)
+	}
+
+	return created, nil
+}
+
+// userListFilter builds the extra WHERE conditions shared by List and Stream, including the tenant scope.
+// Conditions are appended after "deleted_at IS NULL" and use positional args starting at $1.
+func userListFilter(ctx context.Context, req dto.ListUsersRequest) (string, []any, error) {
+	var args []any
+	filter := ""
+	if req.Search != "" {
+		// Served by the trigram indexes on email and name
+		args = append(args, "%"+escapeLike(req.Search)+"%")
+		n := len(args)
+		filter += fmt.Sprintf(" AND (email ILIKE $%d OR first_name ILIKE $%d OR last_name ILIKE $%d)", n, n, n)
+	}
+
+	scope, scopeArgs, err := tenantCondition(ctx, userTenantClause, len(args)+1)
+	if err != nil {
+		return "", nil, err
+	}
+	return filter + scope, append(args, scopeArgs...), nil
+}
+
+// userListOrder validates an "<column> <direction>" order clause, falling back to newest first.
+func userListOrder(order string) string {
+	parts := strings.Fields(strings.ToLower(order))
+	if len(parts) == 0 || len(parts) > 2 || !userOrderColumns[parts[0]] {
+		return "created_at desc"
+	}
+	if len(parts) == 2 && parts[1] != "asc" && parts[1] != "desc" {
+		return "created_at desc"
+	}
+	return strings.Join(parts, " ")
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes LIKE wildcards so user input is matched literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
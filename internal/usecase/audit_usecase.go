@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/repository"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/request"
+	"go-boilerplate/pkg/response"
+	"go-boilerplate/pkg/tenant"
+	"go-boilerplate/pkg/tracer"
+)
+
+type AuditUsecase interface {
+	ListEvents(ctx context.Context, req dto.ListAuditEventsRequest) ([]entity.AuditEvent, response.Meta, error)
+}
+
+type auditUsecase struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditUsecase(repo repository.AuditRepository) AuditUsecase {
+	return &auditUsecase{repo: repo}
+}
+
+func (u *auditUsecase) ListEvents(ctx context.Context, req dto.ListAuditEventsRequest) ([]entity.AuditEvent, response.Meta, error) {
+	ctx, span := tracer.StartSpan(ctx, "AuditUsecase.ListEvents", "usecase")
+	defer span.End()
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	events, total, err := u.repo.List(ctx, req)
+	if err != nil {
+		return nil, response.Meta{}, appErrors.Wrap(err, 500, "Failed to list audit events")
+	}
+
+	meta := response.Meta{
+		Offset: (req.Page - 1) * req.Limit,
+		Limit:  req.Limit,
+		Total:  total,
+		Order:  "created_at desc",
+	}
+
+	return events, meta, nil
+}
+
+// recordAudit writes an audit event for a mutation, attributing it to the actor in the request metadata.
+// It must be called with the ctx of the transaction performing the mutation so both commit together.
+// before and after are compared field by field through their JSON form; pass nil for creations and deletions.
+func recordAudit(ctx context.Context, repo repository.AuditRepository, action, resourceType, resourceID string, before, after any) error {
+	md := request.MetadataFromContext(ctx)
+	traceID, _, _ := tracer.TraceContext(ctx)
+	tenantID, _ := tenant.FromContext(ctx)
+
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	return repo.Create(ctx, &entity.AuditEvent{
+		ActorID:      md.ActorID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Changes:      changes,
+		IP:           md.IP,
+		UserAgent:    md.UserAgent,
+		TraceID:      traceID,
+		TenantID:     tenantID,
+	})
+}
+
+// auditDiff returns the fields whose JSON value differs between before and after.
+// Fields hidden from JSON, such as passwords, never appear in the diff.
+func auditDiff(before, after any) (map[string]entity.AuditChange, error) {
+	beforeFields, err := toFieldMap(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFieldMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]entity.AuditChange)
+	for key, b := range beforeFields {
+		if a, ok := afterFields[key]; !ok || !reflect.DeepEqual(a, b) {
+			changes[key] = entity.AuditChange{Before: b, After: afterFields[key]}
+		}
+	}
+	for key, a := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = entity.AuditChange{Before: nil, After: a}
+		}
+	}
+	return changes, nil
+}
+
+func toFieldMap(v any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to encode audit snapshot")
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to decode audit snapshot")
+	}
+	return fields, nil
+}
@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/infrastructure/mailer"
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/pkg/auth"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/request"
+	"go-boilerplate/pkg/tenant"
+	"go-boilerplate/pkg/tracer"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type InvitationUsecase interface {
+	Invite(ctx context.Context, organizationID, actorID, email, role string) (*entity.Invitation, error)
+	ListPending(ctx context.Context, organizationID, actorID string) ([]entity.Invitation, error)
+	Resend(ctx context.Context, organizationID, actorID, invitationID string) (*entity.Invitation, error)
+	Revoke(ctx context.Context, organizationID, actorID, invitationID string) error
+	Accept(ctx context.Context, token, password string) (*dto.AcceptInvitationResponse, error)
+}
+
+type invitationUsecase struct {
+	repo      repository.InvitationRepository
+	orgRepo   repository.OrganizationRepository
+	userRepo  repository.UserRepository
+	auditRepo repository.AuditRepository
+	txManager database.Transactor
+	publisher rabbitmq.Publisher
+	config    *config.Config
+}
+
+func NewInvitationUsecase(
+	repo repository.InvitationRepository,
+	orgRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	txManager database.Transactor,
+	publisher rabbitmq.Publisher,
+	cfg *config.Config,
+) InvitationUsecase {
+	return &invitationUsecase{
+		repo:      repo,
+		orgRepo:   orgRepo,
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		txManager: txManager,
+		publisher: publisher,
+		config:    cfg,
+	}
+}
+
+// Invite creates a pending invitation and emails a signed link to it. Only owners may invite admins.
+func (u *invitationUsecase) Invite(ctx context.Context, organizationID, actorID, email, role string) (*entity.Invitation, error) {
+	ctx, span := tracer.StartSpan(ctx, "InvitationUsecase.Invite", "usecase")
+	defer span.End()
+
+	actor, err := requireOrgRole(ctx, u.orgRepo, organizationID, actorID, entity.MembershipRoleOwner, entity.MembershipRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == "" {
+		role = entity.MembershipRoleMember
+	}
+	if role != entity.MembershipRoleMember && role != entity.MembershipRoleAdmin {
+		return nil, appErrors.New(400, "Invalid role")
+	}
+	if role == entity.MembershipRoleAdmin && actor.Role != entity.MembershipRoleOwner {
+		return nil, appErrors.New(403, "Only owners can invite admins")
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	existing, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, appErrors.Wrap(err, 500, "Failed to load user")
+	}
+	if existing != nil {
+		if _, err := u.orgRepo.GetMembership(ctx, organizationID, existing.ID); err == nil {
+			return nil, appErrors.New(409, "User is already a member")
+		}
+	}
+
+	inv := &entity.Invitation{
+		OrganizationID: organizationID,
+		Email:          email,
+		Role:           role,
+		InvitedBy:      actorID,
+		ExpiresAt:      time.Now().Add(u.config.Invite.Expiry),
+	}
+
+	var token string
+	err = u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		if err := u.repo.Create(ctx, inv); err != nil {
+			if errors.Is(err, repository.ErrInvitationExists) {
+				return appErrors.New(409, "A pending invitation already exists for this email")
+			}
+			return appErrors.Wrap(err, 500, "Failed to create invitation")
+		}
+		if token, err = u.issueToken(ctx, inv); err != nil {
+			return err
+		}
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionInvitationCreate, entity.AuditResourceInvitation, inv.ID, nil, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.send(ctx, inv, actor.OrganizationName, token); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (u *invitationUsecase) ListPending(ctx context.Context, organizationID, actorID string) ([]entity.Invitation, error) {
+	ctx, span := tracer.StartSpan(ctx, "InvitationUsecase.ListPending", "usecase")
+	defer span.End()
+
+	if _, err := requireOrgRole(ctx, u.orgRepo, organizationID, actorID, entity.MembershipRoleOwner, entity.MembershipRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	invitations, err := u.repo.ListPending(ctx)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to list invitations")
+	}
+	return invitations, nil
+}
+
+// Resend issues a new token with a fresh expiry and emails it again. Earlier links stop working.
+func (u *invitationUsecase) Resend(ctx context.Context, organizationID, actorID, invitationID string) (*entity.Invitation, error) {
+	ctx, span := tracer.StartSpan(ctx, "InvitationUsecase.Resend", "usecase")
+	defer span.End()
+
+	actor, err := requireOrgRole(ctx, u.orgRepo, organizationID, actorID, entity.MembershipRoleOwner, entity.MembershipRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	var inv *entity.Invitation
+	var token string
+	err = u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		if inv, err = u.getPending(ctx, invitationID); err != nil {
+			return err
+		}
+		before := *inv
+		if token, err = u.issueToken(ctx, inv); err != nil {
+			return err
+		}
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionInvitationResend, entity.AuditResourceInvitation, inv.ID, &before, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.send(ctx, inv, actor.OrganizationName, token); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (u *invitationUsecase) Revoke(ctx context.Context, organizationID, actorID, invitationID string) error {
+	ctx, span := tracer.StartSpan(ctx, "InvitationUsecase.Revoke", "usecase")
+	defer span.End()
+
+	if _, err := requireOrgRole(ctx, u.orgRepo, organizationID, actorID, entity.MembershipRoleOwner, entity.MembershipRoleAdmin); err != nil {
+		return err
+	}
+
+	return u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		inv, err := u.getPending(ctx, invitationID)
+		if err != nil {
+			return err
+		}
+		if err := u.repo.UpdateStatus(ctx, inv.ID, entity.InvitationStatusRevoked); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to revoke invitation")
+		}
+		after := *inv
+		after.Status = entity.InvitationStatusRevoked
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionInvitationRevoke, entity.AuditResourceInvitation, inv.ID, inv, &after)
+	})
+}
+
+// Accept joins the invited email to the organization, creating the account when it does not exist yet.
+// Holding the emailed token proves control of the address, so existing accounts are linked without a password.
+func (u *invitationUsecase) Accept(ctx context.Context, token, password string) (*dto.AcceptInvitationResponse, error) {
+	ctx, span := tracer.StartSpan(ctx, "InvitationUsecase.Accept", "usecase")
+	defer span.End()
+
+	invitationID, err := auth.ValidateInvitationToken(token, u.config.JWT)
+	if err != nil {
+		return nil, appErrors.New(400, "Invalid or expired invitation")
+	}
+
+	// The invitee has no tenant yet, the invitation itself names the organization
+	inv, err := u.repo.GetByID(tenant.WithSystem(ctx), invitationID)
+	if err != nil {
+		return nil, appErrors.New(400, "Invalid or expired invitation")
+	}
+	if subtle.ConstantTimeCompare([]byte(hashInvitationToken(token)), []byte(inv.TokenHash)) != 1 {
+		return nil, appErrors.New(400, "Invitation link has been replaced by a newer one")
+	}
+	if inv.Status != entity.InvitationStatusPending {
+		return nil, appErrors.New(409, "Invitation is no longer pending")
+	}
+	if inv.Expired(time.Now()) {
+		return nil, appErrors.New(400, "Invalid or expired invitation")
+	}
+
+	res := &dto.AcceptInvitationResponse{OrganizationID: inv.OrganizationID}
+	// The invitee may already have an account through another organization, so the transaction
+	// spans every tenant while the work in it is scoped to the inviting organization
+	err = u.txManager.WithTx(tenant.WithSystem(ctx), database.TxOptions{}, func(ctx context.Context) error {
+		ctx = tenant.WithID(ctx, inv.OrganizationID)
+		user, err := u.userRepo.GetByEmail(ctx, inv.Email)
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return appErrors.Wrap(err, 500, "Failed to load user")
+		}
+		if user == nil {
+			if password == "" {
+				return appErrors.New(400, "Password is required to create an account")
+			}
+			hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				return appErrors.Wrap(err, 500, "Failed to hash password")
+			}
+			user = &entity.User{Email: inv.Email, Password: string(hashed), Role: entity.RoleUser}
+			if err := u.userRepo.Create(ctx, user); err != nil {
+				return appErrors.Wrap(err, 500, "Failed to create user")
+			}
+			res.AccountCreated = true
+		}
+		res.UserID = user.ID
+
+		// Accepting twice through another invitation must not fail on the existing membership
+		if _, err := u.orgRepo.GetMembership(ctx, inv.OrganizationID, user.ID); err != nil {
+			membership := &entity.Membership{OrganizationID: inv.OrganizationID, UserID: user.ID, Role: inv.Role}
+			if err := u.orgRepo.AddMember(ctx, membership); err != nil {
+				return appErrors.Wrap(err, 500, "Failed to add member")
+			}
+		}
+
+		if err := u.repo.UpdateStatus(ctx, inv.ID, entity.InvitationStatusAccepted); err != nil {
+			return appErrors.Wrap(err, 409, "Invitation is no longer pending")
+		}
+
+		md := request.MetadataFromContext(ctx)
+		md.ActorID = user.ID
+		ctx = request.WithMetadata(ctx, md)
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionInvitationAccept, entity.AuditResourceInvitation, inv.ID, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (u *invitationUsecase) getPending(ctx context.Context, id string) (*entity.Invitation, error) {
+	inv, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 404, "Invitation not found")
+	}
+	if inv.Status != entity.InvitationStatusPending {
+		return nil, appErrors.New(409, "Invitation is no longer pending")
+	}
+	return inv, nil
+}
+
+// issueToken signs a new token with a fresh expiry and stores its hash on the invitation.
+func (u *invitationUsecase) issueToken(ctx context.Context, inv *entity.Invitation) (string, error) {
+	inv.ExpiresAt = time.Now().Add(u.config.Invite.Expiry)
+	token, err := auth.GenerateInvitationToken(inv.ID, inv.ExpiresAt, u.config.JWT)
+	if err != nil {
+		return "", appErrors.Wrap(err, 500, "Failed to sign invitation")
+	}
+	inv.TokenHash = hashInvitationToken(token)
+	if err := u.repo.UpdateToken(ctx, inv); err != nil {
+		return "", appErrors.Wrap(err, 500, "Failed to store invitation token")
+	}
+	return token, nil
+}
+
+// send queues the invitation email for the mail worker.
+func (u *invitationUsecase) send(ctx context.Context, inv *entity.Invitation, organizationName, token string) error {
+	link := u.config.Invite.AcceptURL + "?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("You have been invited to join %s", organizationName),
+		Body: fmt.Sprintf("You have been invited to join %s as %s.\n\nAccept the invitation: %s\n\nThis link expires on %s.",
+			organizationName, inv.Role, link, inv.ExpiresAt.UTC().Format(time.RFC1123)),
+	}
+	if err := u.publisher.Publish(ctx, u.config.Mail.Queue, msg); err != nil {
+		return appErrors.Wrap(err, 500, "Invitation saved but the email could not be queued, please resend it")
+	}
+	return nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
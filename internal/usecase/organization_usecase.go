@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/repository"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/tenant"
+	"go-boilerplate/pkg/tracer"
+)
+
+type OrganizationUsecase interface {
+	ListMine(ctx context.Context, userID string) ([]entity.Membership, error)
+	CurrentMembership(ctx context.Context, userID string) (*entity.Membership, error)
+	Create(ctx context.Context, userID, name string) (*entity.Organization, error)
+	ListMembers(ctx context.Context, organizationID, actorID string) ([]entity.Membership, error)
+	RemoveMember(ctx context.Context, organizationID, actorID, userID string) error
+}
+
+type organizationUsecase struct {
+	repo      repository.OrganizationRepository
+	auditRepo repository.AuditRepository
+	txManager database.Transactor
+}
+
+func NewOrganizationUsecase(
+	repo repository.OrganizationRepository,
+	auditRepo repository.AuditRepository,
+	txManager database.Transactor,
+) OrganizationUsecase {
+	return &organizationUsecase{repo: repo, auditRepo: auditRepo, txManager: txManager}
+}
+
+// ListMine returns every organization the user belongs to, not just the one the token is scoped to.
+func (u *organizationUsecase) ListMine(ctx context.Context, userID string) ([]entity.Membership, error) {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationUsecase.ListMine", "usecase")
+	defer span.End()
+
+	memberships, err := u.repo.ListMemberships(tenant.WithSystem(ctx), userID)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to list organizations")
+	}
+	return memberships, nil
+}
+
+// CurrentMembership returns the membership of the user in the organization the request is scoped to.
+func (u *organizationUsecase) CurrentMembership(ctx context.Context, userID string) (*entity.Membership, error) {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationUsecase.CurrentMembership", "usecase")
+	defer span.End()
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, appErrors.New(403, "Log in to an organization first")
+	}
+	membership, err := u.repo.GetMembership(ctx, tenantID, userID)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 403, "Not a member of this organization")
+	}
+	return membership, nil
+}
+
+// Create makes a new organization owned by the user. Log in with its ID to switch to it.
+func (u *organizationUsecase) Create(ctx context.Context, userID, name string) (*entity.Organization, error) {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationUsecase.Create", "usecase")
+	defer span.End()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, appErrors.New(400, "Organization name is required")
+	}
+
+	org := &entity.Organization{Name: name}
+	// The membership belongs to the new organization, outside the tenant of the request
+	err := u.txManager.WithTx(tenant.WithSystem(ctx), database.TxOptions{}, func(ctx context.Context) error {
+		if err := u.repo.Create(ctx, org); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to create organization")
+		}
+		membership := &entity.Membership{OrganizationID: org.ID, UserID: userID, Role: entity.MembershipRoleOwner}
+		if err := u.repo.AddMember(ctx, membership); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to create membership")
+		}
+		return recordAudit(tenant.WithID(ctx, org.ID), u.auditRepo, entity.AuditActionOrganizationCreate,
+			entity.AuditResourceOrganization, org.ID, nil, org)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (u *organizationUsecase) ListMembers(ctx context.Context, organizationID, actorID string) ([]entity.Membership, error) {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationUsecase.ListMembers", "usecase")
+	defer span.End()
+
+	if _, err := requireOrgRole(ctx, u.repo, organizationID, actorID); err != nil {
+		return nil, err
+	}
+
+	members, err := u.repo.ListMembers(ctx, organizationID)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to list members")
+	}
+	return members, nil
+}
+
+// RemoveMember removes a user from the organization. Owners cannot be removed and only owners may remove admins.
+func (u *organizationUsecase) RemoveMember(ctx context.Context, organizationID, actorID, userID string) error {
+	ctx, span := tracer.StartSpan(ctx, "OrganizationUsecase.RemoveMember", "usecase")
+	defer span.End()
+
+	actor, err := requireOrgRole(ctx, u.repo, organizationID, actorID, entity.MembershipRoleOwner, entity.MembershipRoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	return u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		target, err := u.repo.GetMembership(ctx, organizationID, userID)
+		if err != nil {
+			return appErrors.Wrap(err, 404, "Member not found")
+		}
+		switch {
+		case target.Role == entity.MembershipRoleOwner:
+			return appErrors.New(403, "Owners cannot be removed")
+		case target.Role == entity.MembershipRoleAdmin && actor.Role != entity.MembershipRoleOwner:
+			return appErrors.New(403, "Only owners can remove admins")
+		}
+
+		if err := u.repo.RemoveMember(ctx, organizationID, userID); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to remove member")
+		}
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionMemberRemove, entity.AuditResourceOrganization,
+			organizationID, target, nil)
+	})
+}
+
+// requireOrgRole checks that the actor is acting inside organizationID and holds one of roles there.
+// Without roles any membership is enough.
+func requireOrgRole(ctx context.Context, repo repository.OrganizationRepository, organizationID, actorID string, roles ...string) (*entity.Membership, error) {
+	if tenantID, _ := tenant.FromContext(ctx); tenantID != organizationID {
+		return nil, appErrors.New(403, "Log in to this organization to manage it")
+	}
+
+	membership, err := repo.GetMembership(ctx, organizationID, actorID)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 403, "Not a member of this organization")
+	}
+	if len(roles) > 0 && !slices.Contains(roles, membership.Role) {
+		return nil, appErrors.New(403, "Insufficient organization role")
+	}
+	return membership, nil
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/pkg/logger"
+	"go-boilerplate/pkg/tenant"
+	"go-boilerplate/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+// OutboxUsecase publishes the domain events stored in the outbox. Delivery is at least once:
+// an event whose confirm is lost is published again, consumers deduplicate on the message ID.
+type OutboxUsecase interface {
+	Relay(ctx context.Context) (int, error)
+	PurgeSent(ctx context.Context) (int64, error)
+}
+
+type outboxUsecase struct {
+	repo      repository.OutboxRepository
+	txManager database.Transactor
+	publisher rabbitmq.EventPublisher
+	config    config.OutboxConfig
+}
+
+func NewOutboxUsecase(
+	repo repository.OutboxRepository,
+	txManager database.Transactor,
+	publisher rabbitmq.EventPublisher,
+	cfg config.OutboxConfig,
+) OutboxUsecase {
+	return &outboxUsecase{repo: repo, txManager: txManager, publisher: publisher, config: cfg}
+}
+
+// Relay publishes a batch of pending events, oldest first, and returns how many were sent. The
+// events stay locked while they are published, so concurrent relays never send the same event.
+// Relay stops at the first failure to keep the order of the remaining events; the failure is
+// recorded on the event and it is tried again on the next call. An event that failed MaxAttempts
+// times is parked so it stops holding back the others, and an error is logged to alert on.
+func (u *outboxUsecase) Relay(ctx context.Context) (int, error) {
+	ctx, span := tracer.StartSpan(ctx, "OutboxUsecase.Relay", "usecase")
+	defer span.End()
+
+	ctx = tenant.WithSystem(ctx)
+
+	var sent []string
+	var publishErr error
+	err := u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		sent, publishErr = nil, nil
+
+		events, err := u.repo.ClaimPending(ctx, u.config.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := u.publish(ctx, event); err != nil {
+				publishErr = fmt.Errorf("failed to publish event %s: %w", event.ID, err)
+				parked, err := u.repo.MarkFailed(ctx, event.ID, err.Error(), u.config.MaxAttempts)
+				if err != nil {
+					return err
+				}
+				if parked {
+					logger.ErrorCtx(ctx, "Outbox event parked after too many failed publishes",
+						zap.String("event_id", event.ID), zap.String("event_type", event.EventType),
+						zap.Int("attempts", event.Attempts+1), zap.Error(publishErr))
+				}
+				break
+			}
+			sent = append(sent, event.ID)
+		}
+
+		return u.repo.MarkSent(ctx, sent)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(sent), publishErr
+}
+
+func (u *outboxUsecase) publish(ctx context.Context, event entity.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.config.PublishTimeout)
+	defer cancel()
+	return u.publisher.PublishEvent(ctx, event.EventType, event.ID, body)
+}
+
+// PurgeSent deletes the events sent longer ago than the retention and returns how many were deleted.
+func (u *outboxUsecase) PurgeSent(ctx context.Context) (int64, error) {
+	ctx, span := tracer.StartSpan(ctx, "OutboxUsecase.PurgeSent", "usecase")
+	defer span.End()
+
+	return u.repo.DeleteSent(ctx, time.Now().Add(-u.config.Retention))
+}
+
+// recordEvent stores a domain event for the outbox relay. It must be called with the ctx of the
+// transaction performing the change so the event is published only if the change commits.
+func recordEvent(ctx context.Context, repo repository.OutboxRepository, eventType, aggregateType, aggregateID string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+	traceID, _, _ := tracer.TraceContext(ctx)
+
+	return repo.Create(ctx, &entity.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       body,
+		TraceID:       traceID,
+	})
+}
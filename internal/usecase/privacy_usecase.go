@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/infrastructure/minio"
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/pkg/auth"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/logger"
+	"go-boilerplate/pkg/tenant"
+	"go-boilerplate/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+// ErasureMessage is the payload published to the erasure queue.
+type ErasureMessage struct {
+	JobID string `json:"job_id"`
+}
+
+type PrivacyUsecase interface {
+	ExportData(ctx context.Context, userID string) (*dto.DataExportResponse, error)
+	RequestErasure(ctx context.Context, userID string) (*entity.Job, error)
+	ProcessErasure(ctx context.Context, jobID string) error
+	GetErasureCertificate(ctx context.Context, jobID string) (*entity.ErasureCertificate, error)
+}
+
+type privacyUsecase struct {
+	userRepo       repository.UserRepository
+	auditRepo      repository.AuditRepository
+	jobRepo        repository.JobRepository
+	orgRepo        repository.OrganizationRepository
+	invitationRepo repository.InvitationRepository
+	txManager      database.Transactor
+	storage        minio.Storage
+	publisher      rabbitmq.Publisher
+	// revocations holds the token revocation markers
+	revocations cache.Cache
+	cache       cache.Cache
+	config      *config.Config
+}
+
+func NewPrivacyUsecase(
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	jobRepo repository.JobRepository,
+	orgRepo repository.OrganizationRepository,
+	invitationRepo repository.InvitationRepository,
+	txManager database.Transactor,
+	storage minio.Storage,
+	publisher rabbitmq.Publisher,
+	revocations cache.Cache,
+	c cache.Cache,
+	cfg *config.Config,
+) PrivacyUsecase {
+	return &privacyUsecase{
+		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		jobRepo:        jobRepo,
+		orgRepo:        orgRepo,
+		invitationRepo: invitationRepo,
+		txManager:      txManager,
+		storage:        storage,
+		publisher:      publisher,
+		revocations:    revocations,
+		cache:          c,
+		config:         cfg,
+	}
+}
+
+// ExportData writes everything held about the user into a ZIP archive and returns a presigned link to it.
+func (u *privacyUsecase) ExportData(ctx context.Context, userID string) (*dto.DataExportResponse, error) {
+	ctx, span := tracer.StartSpan(ctx, "PrivacyUsecase.ExportData", "usecase")
+	defer span.End()
+
+	user, err := u.userRepo.GetByID(ctx, userID, "UTC")
+	if err != nil {
+		return nil, appErrors.Wrap(err, 404, "User not found")
+	}
+	// The export covers the subject's data across every organization they belong to
+	systemCtx := tenant.WithSystem(ctx)
+	events, err := u.auditRepo.ListByUser(systemCtx, userID)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to load audit events")
+	}
+	jobs, err := u.jobRepo.ListByCreator(systemCtx, userID)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to load jobs")
+	}
+
+	archive, err := buildExportArchive(map[string]any{
+		"profile.json":      user,
+		"audit_events.json": events,
+		"jobs.json":         jobs,
+	})
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to build export archive")
+	}
+
+	now := time.Now().UTC()
+	key := exportKeyPrefix(userID) + now.Format("20060102T150405Z") + ".zip"
+	if err := u.storage.Upload(ctx, key, bytes.NewReader(archive), int64(len(archive)), "application/zip"); err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to store export archive")
+	}
+
+	expiry := u.config.Privacy.ExportLinkExpiry
+	url, err := u.storage.PresignedGetURL(ctx, key, expiry)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to create download link")
+	}
+
+	if err := recordAudit(ctx, u.auditRepo, entity.AuditActionUserExport, entity.AuditResourceUser, userID, nil, nil); err != nil {
+		logger.ErrorCtx(ctx, "Failed to record export audit event", zap.String("user_id", userID), zap.Error(err))
+	}
+
+	return &dto.DataExportResponse{URL: url, ExpiresAt: now.Add(expiry)}, nil
+}
+
+// RequestErasure revokes the user's tokens right away and queues the rest of the erasure.
+func (u *privacyUsecase) RequestErasure(ctx context.Context, userID string) (*entity.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "PrivacyUsecase.RequestErasure", "usecase")
+	defer span.End()
+
+	if _, err := u.userRepo.GetByID(ctx, userID, "UTC"); err != nil {
+		return nil, appErrors.Wrap(err, 404, "User not found")
+	}
+
+	job := &entity.Job{
+		Type:      entity.JobTypeUserErasure,
+		Status:    entity.JobStatusPending,
+		CreatedBy: userID,
+	}
+	if err := u.jobRepo.Create(ctx, job); err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to create erasure job")
+	}
+
+	if err := auth.RevokeTokens(ctx, u.revocations, userID, u.revocationTTL()); err != nil {
+		u.jobRepo.UpdateStatus(ctx, job.ID, entity.JobStatusFailed, "failed to revoke tokens")
+		return nil, appErrors.Wrap(err, 500, "Failed to revoke tokens")
+	}
+
+	if err := u.publisher.Publish(ctx, u.config.Privacy.ErasureQueue, ErasureMessage{JobID: job.ID}); err != nil {
+		u.jobRepo.UpdateStatus(ctx, job.ID, entity.JobStatusFailed, "failed to enqueue job")
+		return nil, appErrors.Wrap(err, 500, "Failed to enqueue erasure job")
+	}
+
+	return job, nil
+}
+
+func (u *privacyUsecase) ProcessErasure(ctx context.Context, jobID string) error {
+	ctx, span := tracer.StartSpan(ctx, "PrivacyUsecase.ProcessErasure", "usecase")
+	defer span.End()
+
+	// Erasure removes the user from every organization, so it runs unscoped
+	ctx = tenant.WithSystem(ctx)
+
+	job, err := u.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to load job %s: %w", jobID, err)
+	}
+
+	// Redelivered messages must not erase twice or overwrite the certificate, and only one of
+	// the workers handling a redelivery claims the job
+	claimed := false
+	if job.Status == entity.JobStatusPending {
+		if claimed, err = u.jobRepo.Claim(ctx, job.ID); err != nil {
+			return err
+		}
+	}
+	if !claimed {
+		logger.InfoCtx(ctx, "Skipping erasure job that is not pending",
+			zap.String("job_id", job.ID), zap.String("status", string(job.Status)))
+		return nil
+	}
+
+	if err := u.runErasure(ctx, job); err != nil {
+		logger.ErrorCtx(ctx, "User erasure failed", zap.String("job_id", job.ID), zap.Error(err))
+		if err := u.jobRepo.UpdateStatus(ctx, job.ID, entity.JobStatusFailed, err.Error()); err != nil {
+			logger.ErrorCtx(ctx, "Failed to mark job failed", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		// The job records the failure and is no longer pending, a retry would skip it
+		return rabbitmq.Permanent(err)
+	}
+
+	return u.jobRepo.UpdateStatus(ctx, job.ID, entity.JobStatusCompleted, "")
+}
+
+func (u *privacyUsecase) runErasure(ctx context.Context, job *entity.Job) error {
+	userID := job.CreatedBy
+	cert := &entity.ErasureCertificate{
+		JobID:       job.ID,
+		SubjectID:   userID,
+		RequestedAt: job.CreatedAt,
+	}
+	done := func(step string) {
+		cert.Steps = append(cert.Steps, entity.ErasureStep{Name: step, CompletedAt: time.Now().UTC()})
+	}
+
+	err := u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		// Invitations are matched by address, so they go before the profile is anonymized
+		if err := u.invitationRepo.DeleteByUser(ctx, userID); err != nil {
+			return err
+		}
+		if err := u.orgRepo.RemoveUser(ctx, userID); err != nil {
+			return err
+		}
+		if err := u.userRepo.Anonymize(ctx, userID, fmt.Sprintf("erased+%s@erased.invalid", userID)); err != nil {
+			return err
+		}
+		if err := u.auditRepo.RedactUser(ctx, userID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionUserErase, entity.AuditResourceUser, userID, nil, nil)
+	})
+	if err != nil {
+		return err
+	}
+	done(entity.ErasureStepDeleteInvitations)
+	done(entity.ErasureStepRemoveMemberships)
+	done(entity.ErasureStepAnonymizeProfile)
+	done(entity.ErasureStepRedactAuditTrail)
+
+	if err := u.storage.DeletePrefix(ctx, exportKeyPrefix(userID)); err != nil {
+		return err
+	}
+	done(entity.ErasureStepDeleteExports)
+
+	// Revoked again in case tokens were issued between the request and now
+	if err := auth.RevokeTokens(ctx, u.revocations, userID, u.revocationTTL()); err != nil {
+		return err
+	}
+	done(entity.ErasureStepRevokeTokens)
+
+	if err := u.cache.Invalidate(ctx, userCacheTag(userID)); err != nil {
+		return err
+	}
+	done(entity.ErasureStepPurgeCache)
+
+	cert.CompletedAt = time.Now().UTC()
+	data, err := signCertificate(cert)
+	if err != nil {
+		return err
+	}
+	return u.storage.Upload(ctx, erasureCertificateKey(job.ID), bytes.NewReader(data), int64(len(data)), "application/json")
+}
+
+func (u *privacyUsecase) GetErasureCertificate(ctx context.Context, jobID string) (*entity.ErasureCertificate, error) {
+	ctx, span := tracer.StartSpan(ctx, "PrivacyUsecase.GetErasureCertificate", "usecase")
+	defer span.End()
+
+	job, err := u.jobRepo.GetByID(ctx, jobID)
+	if err != nil || job.Type != entity.JobTypeUserErasure {
+		return nil, appErrors.New(404, "Erasure job not found")
+	}
+	if job.Status != entity.JobStatusCompleted {
+		return nil, appErrors.New(409, "Erasure has not completed yet")
+	}
+
+	body, err := u.storage.Download(ctx, erasureCertificateKey(job.ID))
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to load certificate")
+	}
+	defer body.Close()
+
+	var cert entity.ErasureCertificate
+	if err := json.NewDecoder(body).Decode(&cert); err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to decode certificate")
+	}
+	return &cert, nil
+}
+
+func (u *privacyUsecase) revocationTTL() time.Duration {
+	return time.Duration(u.config.JWT.RefreshExpiresIn) * time.Minute
+}
+
+// exportKeyPrefix is the storage prefix of the user's data export archives.
+func exportKeyPrefix(userID string) string {
+	return fmt.Sprintf("exports/users/%s/", userID)
+}
+
+func erasureCertificateKey(jobID string) string {
+	return fmt.Sprintf("erasures/%s/certificate.json", jobID)
+}
+
+// signCertificate fills in the digest and returns the encoded certificate.
+func signCertificate(cert *entity.ErasureCertificate) ([]byte, error) {
+	cert.Digest = ""
+	unsigned, err := json.Marshal(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode certificate: %w", err)
+	}
+	sum := sha256.Sum256(unsigned)
+	cert.Digest = hex.EncodeToString(sum[:])
+	return json.MarshalIndent(cert, "", "  ")
+}
+
+func buildExportArchive(files map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
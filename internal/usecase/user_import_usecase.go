@@ -0,0 +1,411 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/minio"
+	"go-boilerplate/internal/infrastructure/rabbitmq"
+	"go-boilerplate/internal/repository"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/logger"
+	"go-boilerplate/pkg/tenant"
+	"go-boilerplate/pkg/tracer"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const maxJobErrorsLimit = 1000
+
+// ImportMessage is the payload published to the import queue.
+type ImportMessage struct {
+	JobID string `json:"job_id"`
+}
+
+type UserImportUsecase interface {
+	StartImport(ctx context.Context, actorID, filename string, file io.Reader, size int64) (*entity.Job, error)
+	ProcessImport(ctx context.Context, jobID string) error
+	RequeueStale(ctx context.Context) (int, error)
+	GetJob(ctx context.Context, id string, errorsLimit int) (*dto.JobResponse, error)
+}
+
+type userImportUsecase struct {
+	userRepo  repository.UserRepository
+	jobRepo   repository.JobRepository
+	storage   minio.Storage
+	publisher rabbitmq.Publisher
+	config    config.ImportConfig
+}
+
+func NewUserImportUsecase(
+	userRepo repository.UserRepository,
+	jobRepo repository.JobRepository,
+	storage minio.Storage,
+	publisher rabbitmq.Publisher,
+	cfg config.ImportConfig,
+) UserImportUsecase {
+	return &userImportUsecase{
+		userRepo:  userRepo,
+		jobRepo:   jobRepo,
+		storage:   storage,
+		publisher: publisher,
+		config:    cfg,
+	}
+}
+
+func (u *userImportUsecase) StartImport(ctx context.Context, actorID, filename string, file io.Reader, size int64) (*entity.Job, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserImportUsecase.StartImport", "usecase")
+	defer span.End()
+
+	if !strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return nil, appErrors.New(400, "Only CSV files are supported")
+	}
+	if u.config.MaxFileSize > 0 && size > u.config.MaxFileSize {
+		return nil, appErrors.New(413, fmt.Sprintf("File exceeds maximum size of %d bytes", u.config.MaxFileSize))
+	}
+
+	job := &entity.Job{
+		Type:      entity.JobTypeUserImport,
+		Status:    entity.JobStatusPending,
+		CreatedBy: actorID,
+	}
+	if err := u.jobRepo.Create(ctx, job); err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to create import job")
+	}
+
+	job.ObjectKey = fmt.Sprintf("imports/users/%s.csv", job.ID)
+	if err := u.storage.Upload(ctx, job.ObjectKey, file, size, "text/csv"); err != nil {
+		u.markFailed(ctx, job.ID, "failed to store upload")
+		return nil, appErrors.Wrap(err, 500, "Failed to store import file")
+	}
+
+	if err := u.publisher.Publish(ctx, u.config.Queue, ImportMessage{JobID: job.ID}); err != nil {
+		u.markFailed(ctx, job.ID, "failed to enqueue job")
+		return nil, appErrors.Wrap(err, 500, "Failed to enqueue import job")
+	}
+
+	return job, nil
+}
+
+// importRow is a validated CSV row waiting to be inserted.
+type importRow struct {
+	row       int
+	email     string
+	firstName string
+	lastName  string
+	password  string
+}
+
+// importColumns holds the position of each known CSV column, -1 when absent.
+type importColumns struct {
+	email, password, firstName, lastName int
+}
+
+func (u *userImportUsecase) ProcessImport(ctx context.Context, jobID string) error {
+	ctx, span := tracer.StartSpan(ctx, "UserImportUsecase.ProcessImport", "usecase")
+	defer span.End()
+
+	// Queue messages carry no tenant, the job itself records which organization it belongs to
+	job, err := u.jobRepo.GetByID(tenant.WithSystem(ctx), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to load job %s: %w", jobID, err)
+	}
+	if job.TenantID != "" {
+		ctx = tenant.WithID(ctx, job.TenantID)
+	} else {
+		ctx = tenant.WithSystem(ctx)
+	}
+
+	// Redelivered messages must not import the same file twice, and only one of the workers
+	// handling a redelivery claims the job. A processing job is only taken over once its worker
+	// stopped reporting progress, RequeueStale publishes it again for that.
+	claimed := false
+	switch job.Status {
+	case entity.JobStatusPending:
+		claimed, err = u.jobRepo.Claim(ctx, job.ID)
+	case entity.JobStatusProcessing:
+		if claimed, err = u.jobRepo.ReclaimStale(ctx, job.ID, time.Now().Add(-u.config.StaleAfter)); claimed {
+			logger.WarnCtx(ctx, "Restarting import job abandoned by its worker", zap.String("job_id", job.ID))
+			job.TotalRows, job.ProcessedRows, job.SucceededRows, job.FailedRows = 0, 0, 0, 0
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		logger.InfoCtx(ctx, "Skipping import job that is not pending",
+			zap.String("job_id", job.ID), zap.String("status", string(job.Status)))
+		return nil
+	}
+
+	if err := u.runImport(ctx, job); err != nil {
+		logger.ErrorCtx(ctx, "User import failed", zap.String("job_id", job.ID), zap.Error(err))
+		if err := u.jobRepo.UpdateProgress(ctx, job); err != nil {
+			logger.ErrorCtx(ctx, "Failed to record import progress", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		u.markFailed(ctx, job.ID, err.Error())
+		// The job records the failure and is no longer pending, a retry would skip it
+		return rabbitmq.Permanent(err)
+	}
+
+	if err := u.jobRepo.UpdateProgress(ctx, job); err != nil {
+		return err
+	}
+	return u.jobRepo.UpdateStatus(ctx, job.ID, entity.JobStatusCompleted, "")
+}
+
+// RequeueStale publishes the import jobs whose worker stopped reporting progress, so another worker
+// takes them over. It returns how many were published.
+func (u *userImportUsecase) RequeueStale(ctx context.Context) (int, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserImportUsecase.RequeueStale", "usecase")
+	defer span.End()
+
+	ids, err := u.jobRepo.ListStale(tenant.WithSystem(ctx), entity.JobTypeUserImport, time.Now().Add(-u.config.StaleAfter))
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if err := u.publisher.Publish(ctx, u.config.Queue, ImportMessage{JobID: id}); err != nil {
+			return i, fmt.Errorf("failed to requeue import job %s: %w", id, err)
+		}
+	}
+	return len(ids), nil
+}
+
+// markFailed records the failure of a job. The caller already fails, so an error recording it is logged.
+func (u *userImportUsecase) markFailed(ctx context.Context, jobID, reason string) {
+	if err := u.jobRepo.UpdateStatus(ctx, jobID, entity.JobStatusFailed, reason); err != nil {
+		logger.ErrorCtx(ctx, "Failed to mark job failed", zap.String("job_id", jobID), zap.Error(err))
+	}
+}
+
+func (u *userImportUsecase) runImport(ctx context.Context, job *entity.Job) error {
+	body, err := u.storage.Download(ctx, job.ObjectKey)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	cols := importColumns{email: -1, password: -1, firstName: -1, lastName: -1}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "email":
+			cols.email = i
+		case "password":
+			cols.password = i
+		case "first_name":
+			cols.firstName = i
+		case "last_name":
+			cols.lastName = i
+		}
+	}
+	if cols.email < 0 || cols.password < 0 {
+		return fmt.Errorf("csv header must contain email and password columns")
+	}
+
+	batchSize := u.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	// Emails seen earlier in this file, mapped to the row they first appeared on
+	seen := make(map[string]int)
+	batch := make([]importRow, 0, batchSize)
+	var rowErrors []entity.JobError
+	rowNumber := 1 // header is row 1
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		rowNumber++
+		job.TotalRows++
+
+		if err != nil {
+			rowErrors = append(rowErrors, entity.JobError{Row: rowNumber, Message: fmt.Sprintf("malformed row: %v", err)})
+			continue
+		}
+
+		row, rowErr := parseImportRow(record, rowNumber, cols)
+		if rowErr != nil {
+			rowErrors = append(rowErrors, *rowErr)
+			continue
+		}
+
+		if firstRow, ok := seen[row.email]; ok {
+			rowErrors = append(rowErrors, entity.JobError{
+				Row:     row.row,
+				Email:   row.email,
+				Message: fmt.Sprintf("duplicate email in file, first seen on row %d", firstRow),
+			})
+			continue
+		}
+		seen[row.email] = row.row
+
+		batch = append(batch, row)
+		if len(batch) >= batchSize {
+			rowErrors = append(rowErrors, u.flushBatch(ctx, job, batch)...)
+			batch = batch[:0]
+			if err := u.saveProgress(ctx, job, rowErrors); err != nil {
+				return err
+			}
+			rowErrors = nil
+		}
+	}
+
+	if len(batch) > 0 {
+		rowErrors = append(rowErrors, u.flushBatch(ctx, job, batch)...)
+	}
+	return u.saveProgress(ctx, job, rowErrors)
+}
+
+// flushBatch inserts a batch of rows and returns the errors for rows that could not be inserted.
+func (u *userImportUsecase) flushBatch(ctx context.Context, job *entity.Job, batch []importRow) []entity.JobError {
+	var rowErrors []entity.JobError
+
+	emails := make([]string, len(batch))
+	for i, row := range batch {
+		emails[i] = row.email
+	}
+
+	existing, err := u.userRepo.FindExistingEmails(ctx, emails)
+	if err != nil {
+		return failBatch(batch, "failed to check existing emails")
+	}
+
+	users := make([]entity.User, 0, len(batch))
+	inserted := make([]importRow, 0, len(batch))
+	for _, row := range batch {
+		if existing[row.email] {
+			rowErrors = append(rowErrors, entity.JobError{Row: row.row, Email: row.email, Message: "email already exists"})
+			continue
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(row.password), bcrypt.DefaultCost)
+		if err != nil {
+			rowErrors = append(rowErrors, entity.JobError{Row: row.row, Email: row.email, Message: "failed to hash password"})
+			continue
+		}
+
+		users = append(users, entity.User{
+			Email:     row.email,
+			FirstName: row.firstName,
+			LastName:  row.lastName,
+			Password:  string(hashedPassword),
+			Role:      entity.RoleUser,
+		})
+		inserted = append(inserted, row)
+	}
+
+	created, err := u.userRepo.BulkCreate(ctx, users)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to insert import batch", zap.String("job_id", job.ID), zap.Error(err))
+		return append(rowErrors, failBatch(inserted, "failed to insert row")...)
+	}
+
+	// A concurrent registration can still take an email after the check above
+	for _, row := range inserted {
+		if !created[row.email] {
+			rowErrors = append(rowErrors, entity.JobError{Row: row.row, Email: row.email, Message: "email already exists"})
+		}
+	}
+
+	job.SucceededRows += len(created)
+	return rowErrors
+}
+
+func (u *userImportUsecase) saveProgress(ctx context.Context, job *entity.Job, rowErrors []entity.JobError) error {
+	// Every row ends up either inserted or reported as an error
+	job.FailedRows += len(rowErrors)
+	job.ProcessedRows = job.SucceededRows + job.FailedRows
+
+	if err := u.jobRepo.AddErrors(ctx, job.ID, rowErrors); err != nil {
+		return err
+	}
+	return u.jobRepo.UpdateProgress(ctx, job)
+}
+
+func (u *userImportUsecase) GetJob(ctx context.Context, id string, errorsLimit int) (*dto.JobResponse, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserImportUsecase.GetJob", "usecase")
+	defer span.End()
+
+	if errorsLimit <= 0 {
+		errorsLimit = 100
+	}
+	if errorsLimit > maxJobErrorsLimit {
+		errorsLimit = maxJobErrorsLimit
+	}
+
+	job, err := u.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 404, "Job not found")
+	}
+
+	jobErrors, err := u.jobRepo.ListErrors(ctx, id, errorsLimit)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to list job errors")
+	}
+
+	return &dto.JobResponse{Job: *job, Errors: jobErrors}, nil
+}
+
+func parseImportRow(record []string, rowNumber int, cols importColumns) (importRow, *entity.JobError) {
+	if cols.email >= len(record) || cols.password >= len(record) {
+		return importRow{}, &entity.JobError{Row: rowNumber, Message: "missing columns"}
+	}
+
+	email := strings.TrimSpace(record[cols.email])
+	password := record[cols.password]
+
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return importRow{}, &entity.JobError{Row: rowNumber, Email: email, Message: "invalid email"}
+	}
+	if len(password) < 6 {
+		return importRow{}, &entity.JobError{Row: rowNumber, Email: email, Message: "password must be at least 6 characters"}
+	}
+
+	return importRow{
+		row:       rowNumber,
+		email:     email,
+		firstName: optionalColumn(record, cols.firstName),
+		lastName:  optionalColumn(record, cols.lastName),
+		password:  password,
+	}, nil
+}
+
+func optionalColumn(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+func failBatch(batch []importRow, message string) []entity.JobError {
+	rowErrors := make([]entity.JobError, len(batch))
+	for i, row := range batch {
+		rowErrors[i] = entity.JobError{Row: row.row, Email: row.email, Message: message}
+	}
+	return rowErrors
+}
@@ -2,47 +2,103 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"strings"
 	"time"
 
 	"go-boilerplate/internal/config"
 	"go-boilerplate/internal/dto"
 	"go-boilerplate/internal/entity"
-	"go-boilerplate/internal/infrastructure/redis"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/infrastructure/database"
 	"go-boilerplate/internal/repository"
 	"go-boilerplate/pkg/auth"
 	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/logger"
+	"go-boilerplate/pkg/request"
 	"go-boilerplate/pkg/response"
+	"go-boilerplate/pkg/tenant"
 	"go-boilerplate/pkg/tracer"
 
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUsecase interface {
 	Register(ctx context.Context, email, password string) error
-	Login(ctx context.Context, email, password string) (string, string, error)
+	Login(ctx context.Context, email, password, organizationID string) (string, string, error)
 	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
 	ListUsers(ctx context.Context, req dto.ListUsersRequest, timezone string) ([]dto.UserResponse, response.Meta, error)
+	ExportUsers(ctx context.Context, req dto.ListUsersRequest, timezone string, fn func(user dto.UserResponse) error) error
+	SearchUsers(ctx context.Context, req dto.SearchUsersRequest) ([]dto.UserSearchResult, error)
 	GetUser(ctx context.Context, id string, timezone string) (*entity.User, error)
+	GetCurrentUser(ctx context.Context, id string) (*entity.User, error)
 	UpdateUser(ctx context.Context, id string, email string) error
 	DeleteUser(ctx context.Context, id string) error
+	ChangeRole(ctx context.Context, id string, role string) error
+	ChangeStatus(ctx context.Context, actorID, id string, req dto.ChangeUserStatusRequest) error
+	LiftExpiredSuspensions(ctx context.Context) (int, error)
 }
 
+// Reasons returned with 403 when a blocked account signs in or makes a request.
+const (
+	ReasonAccountSuspended = "account_suspended"
+	ReasonAccountBanned    = "account_banned"
+)
+
 type userUsecase struct {
-	repo   repository.UserRepository
-	config *config.Config
-	redis  *redis.Client
+	repo       repository.UserRepository
+	orgRepo    repository.OrganizationRepository
+	auditRepo  repository.AuditRepository
+	outboxRepo repository.OutboxRepository
+	txManager  database.Transactor
+	config     *config.Config
+	// revocations holds the token revocation markers
+	revocations cache.Cache
+	cache       cache.Cache
+
+	users        *cache.ReadThrough[*entity.User]
+	currentUsers *cache.ReadThrough[*entity.User]
 }
 
-func NewUserUsecase(repo repository.UserRepository, cfg *config.Config, rdb *redis.Client) UserUsecase {
-	return &userUsecase{repo: repo, config: cfg, redis: rdb}
+func NewUserUsecase(
+	repo repository.UserRepository,
+	orgRepo repository.OrganizationRepository,
+	auditRepo repository.AuditRepository,
+	outboxRepo repository.OutboxRepository,
+	txManager database.Transactor,
+	cfg *config.Config,
+	revocations cache.Cache,
+	c cache.Cache,
+) UserUsecase {
+	isNotFound := func(err error) bool { return errors.Is(err, repository.ErrUserNotFound) }
+	return &userUsecase{
+		repo: repo, orgRepo: orgRepo, auditRepo: auditRepo, outboxRepo: outboxRepo, txManager: txManager, config: cfg, revocations: revocations, cache: c,
+		users: cache.NewReadThrough[*entity.User](c, "user", cache.Options{
+			TTL:         cfg.Cache.UserTTL,
+			Jitter:      cfg.Cache.Jitter,
+			NegativeTTL: cfg.Cache.NegativeTTL,
+			IsNotFound:  isNotFound,
+			Timeout:     cfg.Cache.Timeout,
+		}),
+		// Not negatively cached, a user added back to the organization must get in right away
+		currentUsers: cache.NewReadThrough[*entity.User](c, "current_user", cache.Options{
+			TTL:        cfg.Session.CurrentUserTTL,
+			IsNotFound: isNotFound,
+			Timeout:    cfg.Cache.Timeout,
+		}),
+	}
 }
 
 func (u *userUsecase) Register(ctx context.Context, email, password string) error {
 	ctx, span := tracer.StartSpan(ctx, "UserUsecase.Register", "usecase")
 	defer span.End()
 
+	// Emails are unique across organizations, and the new organization is not a tenant yet
+	ctx = tenant.WithSystem(ctx)
+
 	existingUser, _ := u.repo.GetByEmail(ctx, email)
 	if existingUser != nil {
 		return appErrors.New(400, "Email already exists")
@@ -56,19 +112,46 @@ func (u *userUsecase) Register(ctx context.Context, email, password string) erro
 	user := &entity.User{
 		Email:    email,
 		Password: string(hashedPassword),
+		Role:     entity.RoleUser,
 	}
 
-	if err := u.repo.Create(ctx, user); err != nil {
-		return appErrors.Wrap(err, 500, "Failed to create user")
-	}
+	return u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		if err := u.repo.Create(ctx, user); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to create user")
+		}
 
-	return nil
+		// Every sign-up gets a personal organization it owns
+		org := &entity.Organization{Name: email}
+		if err := u.orgRepo.Create(ctx, org); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to create organization")
+		}
+		membership := &entity.Membership{OrganizationID: org.ID, UserID: user.ID, Role: entity.MembershipRoleOwner}
+		if err := u.orgRepo.AddMember(ctx, membership); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to create membership")
+		}
+		ctx = tenant.WithID(ctx, org.ID)
+
+		// Self sign-up has no authenticated actor, the new user is its own actor
+		if md := request.MetadataFromContext(ctx); md.ActorID == "" {
+			md.ActorID = user.ID
+			ctx = request.WithMetadata(ctx, md)
+		}
+		if err := recordAudit(ctx, u.auditRepo, entity.AuditActionUserRegister, entity.AuditResourceUser, user.ID, nil, user); err != nil {
+			return err
+		}
+		return recordEvent(ctx, u.outboxRepo, entity.EventUserRegistered, entity.AuditResourceUser, user.ID,
+			entity.UserEvent{UserID: user.ID, Email: user.Email})
+	})
 }
 
-func (u *userUsecase) Login(ctx context.Context, email, password string) (string, string, error) {
+// Login signs the user into organizationID, or into their oldest membership when it is empty.
+func (u *userUsecase) Login(ctx context.Context, email, password, organizationID string) (string, string, error) {
 	ctx, span := tracer.StartSpan(ctx, "UserUsecase.Login", "usecase")
 	defer span.End()
 
+	// The tenant is only known once the memberships of the user are loaded
+	ctx = tenant.WithSystem(ctx)
+
 	user, err := u.repo.GetByEmail(ctx, email)
 	if err != nil {
 		return "", "", appErrors.New(401, "Invalid credentials")
@@ -78,7 +161,17 @@ func (u *userUsecase) Login(ctx context.Context, email, password string) (string
 		return "", "", appErrors.New(401, "Invalid credentials")
 	}
 
-	accessToken, refreshToken, err := auth.GenerateTokenPair(user.ID, u.config.JWT)
+	// Checked after the password so the status of an account is not revealed to anyone
+	if err := blockedError(user, time.Now()); err != nil {
+		return "", "", err
+	}
+
+	tenantID, err := u.resolveTenant(ctx, user.ID, organizationID)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, refreshToken, err := auth.GenerateTokenPair(auth.Subject{UserID: user.ID, Role: user.Role, TenantID: tenantID}, u.config.JWT)
 	if err != nil {
 		return "", "", appErrors.Wrap(err, 500, "Failed to generate tokens")
 	}
@@ -95,13 +188,28 @@ func (u *userUsecase) RefreshToken(ctx context.Context, tokenString string) (str
 		return "", "", appErrors.New(401, "Invalid refresh token")
 	}
 
-	// Optional: Check if user still exists/is active
-	user, err := u.repo.GetByID(ctx, claims.UserID, "")
+	revoked, err := auth.IsRevoked(ctx, u.revocations, claims)
+	if err != nil {
+		return "", "", appErrors.Wrap(err, 500, "Failed to check token revocation")
+	}
+	if revoked {
+		return "", "", appErrors.New(401, "Refresh token has been revoked")
+	}
+
+	if claims.TenantID == "" {
+		return "", "", appErrors.New(401, "Refresh token has no organization, please log in again")
+	}
+
+	// Optional: Check if user still exists/is active, and is still a member of the organization
+	user, err := u.repo.GetByID(tenant.WithID(ctx, claims.TenantID), claims.UserID, "")
 	if err != nil {
 		return "", "", appErrors.New(401, "User not found")
 	}
+	if err := blockedError(user, time.Now()); err != nil {
+		return "", "", err
+	}
 
-	accessToken, refreshToken, err := auth.GenerateTokenPair(user.ID, u.config.JWT)
+	accessToken, refreshToken, err := auth.GenerateTokenPair(auth.Subject{UserID: user.ID, Role: user.Role, TenantID: claims.TenantID}, u.config.JWT)
 	if err != nil {
 		return "", "", appErrors.Wrap(err, 500, "Failed to generate tokens")
 	}
@@ -109,6 +217,25 @@ func (u *userUsecase) RefreshToken(ctx context.Context, tokenString string) (str
 	return accessToken, refreshToken, nil
 }
 
+func (u *userUsecase) resolveTenant(ctx context.Context, userID, organizationID string) (string, error) {
+	memberships, err := u.orgRepo.ListMemberships(ctx, userID)
+	if err != nil {
+		return "", appErrors.Wrap(err, 500, "Failed to load organizations")
+	}
+	if len(memberships) == 0 {
+		return "", appErrors.New(403, "User does not belong to any organization")
+	}
+	if organizationID == "" {
+		return memberships[0].OrganizationID, nil
+	}
+	for _, m := range memberships {
+		if m.OrganizationID == organizationID {
+			return m.OrganizationID, nil
+		}
+	}
+	return "", appErrors.New(403, "User is not a member of this organization")
+}
+
 func (u *userUsecase) ListUsers(ctx context.Context, req dto.ListUsersRequest, timezone string) ([]dto.UserResponse, response.Meta, error) {
 	ctx, span := tracer.StartSpan(ctx, "UserUsecase.ListUsers", "usecase")
 	defer span.End()
@@ -136,6 +263,8 @@ func (u *userUsecase) ListUsers(ctx context.Context, req dto.ListUsersRequest, t
 		userResponses[i] = dto.UserResponse{
 			ID:        u.ID,
 			Email:     u.Email,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
 			CreatedAt: u.CreatedAt,
 			UpdatedAt: u.UpdatedAt,
 		}
@@ -152,6 +281,101 @@ func (u *userUsecase) ListUsers(ctx context.Context, req dto.ListUsersRequest, t
 	return userResponses, meta, nil
 }
 
+// ExportUsers streams every user matching the list filters to fn, with timestamps in the given timezone.
+func (u *userUsecase) ExportUsers(ctx context.Context, req dto.ListUsersRequest, timezone string, fn func(user dto.UserResponse) error) error {
+	ctx, span := tracer.StartSpan(ctx, "UserUsecase.ExportUsers", "usecase")
+	defer span.End()
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return appErrors.New(400, "Invalid timezone")
+	}
+
+	err = u.repo.Stream(ctx, req, func(user entity.User) error {
+		return fn(dto.UserResponse{
+			ID:        user.ID,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			CreatedAt: user.CreatedAt.In(loc),
+			UpdatedAt: user.UpdatedAt.In(loc),
+		})
+	})
+	if err != nil {
+		var customErr *appErrors.CustomError
+		if errors.As(err, &customErr) {
+			return err
+		}
+		return appErrors.Wrap(err, 500, "Failed to export users")
+	}
+
+	return nil
+}
+
+func (u *userUsecase) SearchUsers(ctx context.Context, req dto.SearchUsersRequest) ([]dto.UserSearchResult, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserUsecase.SearchUsers", "usecase")
+	defer span.End()
+
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, appErrors.New(400, "Search query is required")
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Limit > 50 {
+		req.Limit = 50
+	}
+
+	matches, err := u.repo.Search(ctx, query, req.Limit)
+	if err != nil {
+		return nil, appErrors.Wrap(err, 500, "Failed to search users")
+	}
+
+	results := make([]dto.UserSearchResult, len(matches))
+	for i, m := range matches {
+		highlights := make(map[string]string)
+		for field, value := range map[string]string{"email": m.Email, "first_name": m.FirstName, "last_name": m.LastName} {
+			if h, ok := highlightMatch(value, query); ok {
+				highlights[field] = h
+			}
+		}
+
+		results[i] = dto.UserSearchResult{
+			UserResponse: dto.UserResponse{
+				ID:        m.ID,
+				Email:     m.Email,
+				FirstName: m.FirstName,
+				LastName:  m.LastName,
+				CreatedAt: m.CreatedAt,
+				UpdatedAt: m.UpdatedAt,
+			},
+			Score:      m.Score,
+			Highlights: highlights,
+		}
+	}
+
+	return results, nil
+}
+
+// highlightMatch wraps the first case-insensitive occurrence of query in value with <mark> tags.
+// Fuzzy matches without a literal occurrence are not highlighted.
+func highlightMatch(value, query string) (string, bool) {
+	idx := strings.Index(strings.ToLower(value), strings.ToLower(query))
+	if idx < 0 || value == "" {
+		return "", false
+	}
+	// Lowercasing can change byte lengths for some runes, only trust byte-aligned matches
+	end := idx + len(query)
+	if end > len(value) || !strings.EqualFold(value[idx:end], query) {
+		return "", false
+	}
+
+	return html.EscapeString(value[:idx]) +
+		"<mark>" + html.EscapeString(value[idx:end]) + "</mark>" +
+		html.EscapeString(value[end:]), true
+}
+
 func (u *userUsecase) GetUser(ctx context.Context, id string, timezone string) (*entity.User, error) {
 	ctx, span := tracer.StartSpan(ctx, "UserUsecase.GetUser", "usecase")
 	defer span.End()
@@ -160,45 +384,81 @@ func (u *userUsecase) GetUser(ctx context.Context, id string, timezone string) (
 		timezone = "UTC"
 	}
 
-	// Check Redis Cache (timezone-specific)
-	cacheKey := fmt.Sprintf("user:%s:%s", id, timezone)
-	cachedUser, err := u.redis.Get(ctx, cacheKey).Result()
-	if err == nil {
-		var user entity.User
-		if err := json.Unmarshal([]byte(cachedUser), &user); err == nil {
-			return &user, nil
-		}
-	}
-
-	// Determine from DB
-	user, err := u.repo.GetByID(ctx, id, timezone)
+	// Check Redis Cache (tenant and timezone-specific)
+	tenantID, _ := tenant.FromContext(ctx)
+	cacheKey := fmt.Sprintf("user:%s:%s:%s", id, tenantID, timezone)
+	// Tagged so any change to the user evicts every tenant and timezone variant
+	user, err := u.users.Get(ctx, cacheKey, func(ctx context.Context) (*entity.User, error) {
+		return u.repo.GetByID(ctx, id, timezone)
+	}, userCacheTag(id))
 	if err != nil {
 		return nil, appErrors.Wrap(err, 404, "User not found")
 	}
 
-	// Set Cache
-	userJSON, _ := json.Marshal(user)
-	u.redis.Set(ctx, cacheKey, string(userJSON), 1*time.Hour)
+	return user, nil
+}
+
+// GetCurrentUser loads the authenticated user for the tenant in ctx, cached briefly so every request
+// can afford it. Deleted accounts and users removed from the organization are rejected with 401.
+func (u *userUsecase) GetCurrentUser(ctx context.Context, id string) (*entity.User, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserUsecase.GetCurrentUser", "usecase")
+	defer span.End()
+
+	// Password is excluded from JSON, so the hash never reaches Redis
+	user, err := u.currentUsers.Get(ctx, currentUserCacheKey(ctx, id), func(ctx context.Context) (*entity.User, error) {
+		return u.repo.GetByID(ctx, id, "UTC")
+	}, userCacheTag(id))
+	if err != nil {
+		return nil, appErrors.Wrap(err, 401, "Account no longer exists")
+	}
 
+	// Checked on cached entries too, a suspension may have started since they were stored
+	if err := blockedError(user, time.Now()); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
+// blockedError returns the 403 for a suspended or banned account, or nil when it may proceed.
+func blockedError(user *entity.User, now time.Time) error {
+	if !user.Blocked(now) {
+		return nil
+	}
+	if user.Status == entity.UserStatusBanned {
+		return appErrors.New(403, "Account has been banned").WithReason(ReasonAccountBanned)
+	}
+	return appErrors.New(403, "Account is suspended").WithReason(ReasonAccountSuspended)
+}
+
+func currentUserCacheKey(ctx context.Context, id string) string {
+	tenantID, _ := tenant.FromContext(ctx)
+	return fmt.Sprintf("user:%s:%s:current", id, tenantID)
+}
+
 func (u *userUsecase) UpdateUser(ctx context.Context, id string, email string) error {
 	ctx, span := tracer.StartSpan(ctx, "UserUsecase.UpdateUser", "usecase")
 	defer span.End()
 
-	user, err := u.repo.GetByID(ctx, id, "UTC") // Get original for update
-	if err != nil {
-		return appErrors.Wrap(err, 404, "User not found")
-	}
+	err := u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		before, err := u.repo.GetByID(ctx, id, "UTC") // Get original for update
+		if err != nil {
+			return appErrors.Wrap(err, 404, "User not found")
+		}
 
-	user.Email = email
-	if err := u.repo.Update(ctx, user); err != nil {
-		return appErrors.Wrap(err, 500, "Failed to update user")
+		after := *before
+		after.Email = email
+		if err := u.repo.Update(ctx, &after); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to update user")
+		}
+
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionUserUpdate, entity.AuditResourceUser, id, before, &after)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Invalidate Cache (all timezones for this user)
-	u.redis.Del(ctx, fmt.Sprintf("user:%s:*", id))
+	// Invalidate Cache (all tenants and timezones for this user)
+	u.invalidateUser(ctx, id)
 
 	return nil
 }
@@ -207,12 +467,165 @@ func (u *userUsecase) DeleteUser(ctx context.Context, id string) error {
 	ctx, span := tracer.StartSpan(ctx, "UserUsecase.DeleteUser", "usecase")
 	defer span.End()
 
-	if err := u.repo.Delete(ctx, id); err != nil {
-		return appErrors.Wrap(err, 500, "Failed to delete user")
+	err := u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		before, err := u.repo.GetByID(ctx, id, "UTC")
+		if err != nil {
+			return appErrors.Wrap(err, 404, "User not found")
+		}
+
+		if err := u.repo.Delete(ctx, id); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to delete user")
+		}
+
+		if err := recordAudit(ctx, u.auditRepo, entity.AuditActionUserDelete, entity.AuditResourceUser, id, before, nil); err != nil {
+			return err
+		}
+		return recordEvent(ctx, u.outboxRepo, entity.EventUserDeleted, entity.AuditResourceUser, id,
+			entity.UserEvent{UserID: id, Email: before.Email})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Deleted accounts lose access right away, the current user entries are tagged too
+	u.invalidateUser(ctx, id)
+
+	return nil
+}
+
+// ChangeRole sets the role of a user in every organization. Only platform admins may call it.
+func (u *userUsecase) ChangeRole(ctx context.Context, id string, role string) error {
+	ctx, span := tracer.StartSpan(ctx, "UserUsecase.ChangeRole", "usecase")
+	defer span.End()
+
+	if role != entity.RoleUser && role != entity.RoleAdmin && role != entity.RolePlatformAdmin {
+		return appErrors.New(400, "Invalid role")
+	}
+
+	err := u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		before, err := u.repo.GetByID(ctx, id, "UTC")
+		if err != nil {
+			return appErrors.Wrap(err, 404, "User not found")
+		}
+		if before.Role == role {
+			return nil
+		}
+
+		after := *before
+		after.Role = role
+		if err := u.repo.UpdateRole(ctx, id, role); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to change role")
+		}
+
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionUserRoleChange, entity.AuditResourceUser, id, before, &after)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Invalidate Cache (all tenants and timezones for this user)
+	u.invalidateUser(ctx, id)
+
+	return nil
+}
+
+// ChangeStatus suspends, bans or reactivates a user in every organization. Expiry only applies to
+// suspensions. Only platform admins may call it.
+func (u *userUsecase) ChangeStatus(ctx context.Context, actorID, id string, req dto.ChangeUserStatusRequest) error {
+	ctx, span := tracer.StartSpan(ctx, "UserUsecase.ChangeStatus", "usecase")
+	defer span.End()
+
+	if actorID == id {
+		return appErrors.New(400, "You cannot change your own status")
+	}
+
+	status := entity.UserStatus(req.Status)
+	switch status {
+	case entity.UserStatusActive, entity.UserStatusSuspended, entity.UserStatusBanned:
+	default:
+		return appErrors.New(400, "Invalid status")
+	}
+	if req.ExpiresAt != nil {
+		if status != entity.UserStatusSuspended {
+			return appErrors.New(400, "Only suspensions can expire")
+		}
+		if !req.ExpiresAt.After(time.Now()) {
+			return appErrors.New(400, "Expiry must be in the future")
+		}
+	}
+
+	reason := req.Reason
+	if status == entity.UserStatusActive {
+		reason = ""
+	}
+
+	err := u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		before, err := u.repo.GetByID(ctx, id, "UTC")
+		if err != nil {
+			return appErrors.Wrap(err, 404, "User not found")
+		}
+
+		after := *before
+		after.Status, after.StatusReason, after.StatusExpiresAt = status, reason, req.ExpiresAt
+		if err := u.repo.UpdateStatus(ctx, id, status, reason, req.ExpiresAt); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to change status")
+		}
+
+		return recordAudit(ctx, u.auditRepo, entity.AuditActionUserStatusChange, entity.AuditResourceUser, id, before, &after)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Invalidate Cache (all timezones for this user)
-	u.redis.Del(ctx, fmt.Sprintf("user:%s:*", id))
+	// Evicted in every organization, so authenticated requests see the new status right away
+	u.invalidateUser(ctx, id)
 
 	return nil
 }
+
+// LiftExpiredSuspensions reactivates users whose suspension has expired, across every organization,
+// and returns how many were lifted. It is run periodically by the worker.
+func (u *userUsecase) LiftExpiredSuspensions(ctx context.Context) (int, error) {
+	ctx, span := tracer.StartSpan(ctx, "UserUsecase.LiftExpiredSuspensions", "usecase")
+	defer span.End()
+
+	ctx = tenant.WithSystem(ctx)
+
+	var ids []string
+	err := u.txManager.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		ids, err = u.repo.LiftExpiredSuspensions(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			after := map[string]any{"status": entity.UserStatusActive}
+			if err := recordAudit(ctx, u.auditRepo, entity.AuditActionUserStatusChange, entity.AuditResourceUser, id,
+				map[string]any{"status": entity.UserStatusSuspended}, after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		u.invalidateUser(ctx, id)
+	}
+
+	return len(ids), nil
+}
+
+// invalidateUser evicts every cached entry of the user. Failures are logged, the entries still expire.
+func (u *userUsecase) invalidateUser(ctx context.Context, id string) {
+	if err := u.cache.Invalidate(ctx, userCacheTag(id)); err != nil {
+		logger.ErrorCtx(ctx, "Failed to invalidate user cache", zap.String("user_id", id), zap.Error(err))
+	}
+}
+
+// userCacheTag tags every cache entry derived from the user.
+func userCacheTag(id string) string {
+	return "user:" + id
+}
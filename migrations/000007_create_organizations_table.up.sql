@@ -0,0 +1,65 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS memberships (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX idx_memberships_user_id ON memberships(user_id);
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tenant_id UUID NULL REFERENCES organizations(id);
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS tenant_id UUID NULL;
+
+CREATE INDEX idx_jobs_tenant_id ON jobs(tenant_id);
+CREATE INDEX idx_audit_events_tenant_id ON audit_events(tenant_id, created_at DESC);
+
+-- Existing data moves into a default organization
+INSERT INTO organizations (id, name) VALUES ('00000000-0000-0000-0000-000000000001', 'Default');
+INSERT INTO memberships (organization_id, user_id, role)
+SELECT '00000000-0000-0000-0000-000000000001', id, CASE WHEN role = 'admin' THEN 'owner' ELSE 'member' END
+FROM users;
+UPDATE jobs SET tenant_id = '00000000-0000-0000-0000-000000000001';
+UPDATE audit_events SET tenant_id = '00000000-0000-0000-0000-000000000001';
+
+-- Row-level security, enforced when the application sets app.tenant_id (DATABASE_RLS_ENABLED).
+-- An unset or empty app.tenant_id means unscoped access, e.g. for login and migrations.
+CREATE OR REPLACE FUNCTION current_tenant_id() RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE memberships ENABLE ROW LEVEL SECURITY;
+ALTER TABLE memberships FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON memberships
+    USING (current_tenant_id() IS NULL OR organization_id = current_tenant_id());
+
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+-- New users have no membership yet, so inserts are not restricted
+CREATE POLICY tenant_isolation_insert ON users FOR INSERT WITH CHECK (true);
+CREATE POLICY tenant_isolation_select ON users FOR SELECT
+    USING (current_tenant_id() IS NULL OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+CREATE POLICY tenant_isolation_update ON users FOR UPDATE
+    USING (current_tenant_id() IS NULL OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+CREATE POLICY tenant_isolation_delete ON users FOR DELETE
+    USING (current_tenant_id() IS NULL OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+
+ALTER TABLE jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE jobs FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON jobs
+    USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+
+ALTER TABLE audit_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON audit_events
+    USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
@@ -0,0 +1,2 @@
+DROP POLICY IF EXISTS tenant_isolation ON invitations;
+DROP TABLE IF EXISTS invitations;
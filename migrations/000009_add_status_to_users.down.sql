@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_suspension_expiry;
+ALTER TABLE users DROP COLUMN IF EXISTS status_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_expires_at TIMESTAMPTZ NULL;
+
+-- Serves the scheduled job that lifts expired suspensions
+CREATE INDEX idx_users_suspension_expiry ON users(status_expires_at) WHERE status = 'suspended';
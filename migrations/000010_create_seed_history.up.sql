@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS seed_history (
+    environment VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (environment, name)
+);
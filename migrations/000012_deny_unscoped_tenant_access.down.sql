@@ -0,0 +1,23 @@
+ALTER POLICY tenant_isolation ON memberships
+    USING (current_tenant_id() IS NULL OR organization_id = current_tenant_id());
+ALTER POLICY tenant_isolation_select ON users
+    USING (current_tenant_id() IS NULL OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+ALTER POLICY tenant_isolation_update ON users
+    USING (current_tenant_id() IS NULL OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+ALTER POLICY tenant_isolation_delete ON users
+    USING (current_tenant_id() IS NULL OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+ALTER POLICY tenant_isolation ON jobs
+    USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER POLICY tenant_isolation ON audit_events
+    USING (current_tenant_id() IS NULL OR tenant_id = current_tenant_id());
+ALTER POLICY tenant_isolation ON invitations
+    USING (current_tenant_id() IS NULL OR organization_id = current_tenant_id());
+
+DROP FUNCTION IF EXISTS tenant_unscoped();
+
+CREATE OR REPLACE FUNCTION current_tenant_id() RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1,28 @@
+-- app.tenant_id holds the tenant of the connection, or '*' for work that deliberately spans every
+-- tenant (tenant.WithSystem, migrations, DATABASE_RLS_ENABLED=false). Unset or empty now denies
+-- access, so a code path that forgets the tenant sees no rows instead of every row.
+CREATE OR REPLACE FUNCTION current_tenant_id() RETURNS UUID AS $$
+    SELECT NULLIF(NULLIF(current_setting('app.tenant_id', true), ''), '*')::uuid
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION tenant_unscoped() RETURNS BOOLEAN AS $$
+    SELECT COALESCE(current_setting('app.tenant_id', true) = '*', false)
+$$ LANGUAGE sql STABLE;
+
+ALTER POLICY tenant_isolation ON memberships
+    USING (tenant_unscoped() OR organization_id = current_tenant_id());
+ALTER POLICY tenant_isolation_select ON users
+    USING (tenant_unscoped() OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+ALTER POLICY tenant_isolation_update ON users
+    USING (tenant_unscoped() OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+ALTER POLICY tenant_isolation_delete ON users
+    USING (tenant_unscoped() OR EXISTS (
+        SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = current_tenant_id()));
+ALTER POLICY tenant_isolation ON jobs
+    USING (tenant_unscoped() OR tenant_id = current_tenant_id());
+ALTER POLICY tenant_isolation ON audit_events
+    USING (tenant_unscoped() OR tenant_id = current_tenant_id());
+ALTER POLICY tenant_isolation ON invitations
+    USING (tenant_unscoped() OR organization_id = current_tenant_id());
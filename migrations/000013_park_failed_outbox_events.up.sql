@@ -0,0 +1,7 @@
+-- Events that failed to publish OUTBOX_MAX_ATTEMPTS times are parked: the relay skips them until
+-- failed_at is cleared.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ NULL;
+
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX idx_outbox_pending ON outbox(created_at) WHERE sent_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_outbox_failed_at ON outbox(failed_at) WHERE failed_at IS NOT NULL;
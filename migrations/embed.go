@@ -0,0 +1,16 @@
+// Package migrations embeds the SQL migrations and seeders, so binaries can apply them without the files.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed seeder
+var seeder embed.FS
+
+// SeederFS holds a directory of SQL seeders per environment, and common for every environment.
+var SeederFS, _ = fs.Sub(seeder, "seeder")
@@ -1,4 +1,4 @@
--- Seed users
+-- Seed users, members of the default organization so they can log in
 -- Uses ON CONFLICT to avoid duplicate email errors
 
 INSERT INTO users (email, password)
@@ -8,3 +8,9 @@ VALUES
     ('seed3@example.com', '$2a$10$.fn/MHkPqrdNyhuk7f95/O/Lo10q7KsQJqhDnm0V5E7rzFmY8vhVq'), -- password: password123
     ('seed4@example.com', '$2a$10$.fn/MHkPqrdNyhuk7f95/O/Lo10q7KsQJqhDnm0V5E7rzFmY8vhVq')    -- password: password123
 ON CONFLICT (email) DO NOTHING;
+
+INSERT INTO memberships (organization_id, user_id, role)
+SELECT '00000000-0000-0000-0000-000000000001', id, 'member'
+FROM users
+WHERE email IN ('seed1@example.com', 'seed2@example.com', 'seed3@example.com', 'seed4@example.com')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,12 @@
+-- Seed a platform admin owning the default organization for local development
+-- password: password123
+
+INSERT INTO users (email, password, first_name, last_name, role)
+VALUES ('admin@example.com', '$2a$10$.fn/MHkPqrdNyhuk7f95/O/Lo10q7KsQJqhDnm0V5E7rzFmY8vhVq', 'Admin', 'User', 'platform_admin')
+ON CONFLICT (email) DO NOTHING;
+
+INSERT INTO memberships (organization_id, user_id, role)
+SELECT '00000000-0000-0000-0000-000000000001', id, 'owner'
+FROM users
+WHERE email = 'admin@example.com'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// GenerateInvitationToken signs a token that carries the invitation ID in its jti and expires with the invitation.
+func GenerateInvitationToken(invitationID string, expiresAt time.Time, cfg JWTConfig) (string, error) {
+	key, err := parsePrivateKey(cfg.PrivateKeyPath)
+	if err != nil {
+		return "", err
+	}
+
+	claims := &Claims{
+		TokenType: TokenTypeInvitation,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        invitationID,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+}
+
+// ValidateInvitationToken checks the signature and expiry and returns the invitation ID.
+func ValidateInvitationToken(tokenString string, cfg JWTConfig) (string, error) {
+	claims, err := validateTokenWithType(tokenString, cfg, TokenTypeInvitation)
+	if err != nil {
+		return "", err
+	}
+	if claims.ID == "" {
+		return "", fmt.Errorf("invitation token has no id")
+	}
+	return claims.ID, nil
+}
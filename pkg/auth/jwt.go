@@ -4,6 +4,7 @@ import (
 	"crypto/rsa"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,49 +17,83 @@ type JWTConfig struct {
 	RefreshExpiresIn int    `env:"REFRESH_EXPIRES_IN" envDefault:"10080"` // in minutes (7 days)
 }
 
-
-
 type TokenType string
 
 const (
-	TokenTypeAccess  TokenType = "access"
-	TokenTypeRefresh TokenType = "refresh"
+	TokenTypeAccess     TokenType = "access"
+	TokenTypeRefresh    TokenType = "refresh"
+	TokenTypeInvitation TokenType = "invitation"
 )
 
+// Subject identifies who a token pair is issued for.
+type Subject struct {
+	UserID   string
+	Role     string
+	TenantID string
+}
+
 type Claims struct {
 	UserID    string    `json:"user_id"`
+	Role      string    `json:"role,omitempty"`
+	TenantID  string    `json:"tenant_id,omitempty"`
 	TokenType TokenType `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
+// Parsed keys by file path. Each file is read once per process, so rotating a key takes a restart.
+var (
+	privateKeys sync.Map
+	publicKeys  sync.Map
+)
+
 func parsePrivateKey(path string) (*rsa.PrivateKey, error) {
+	if key, ok := privateKeys.Load(path); ok {
+		return key.(*rsa.PrivateKey), nil
+	}
 	keyData, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read private key: %w", err)
 	}
-	return jwt.ParseRSAPrivateKeyFromPEM(keyData)
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(keyData)
+	if err != nil {
+		return nil, err
+	}
+	privateKeys.Store(path, key)
+	return key, nil
 }
 
 func parsePublicKey(path string) (*rsa.PublicKey, error) {
+	if key, ok := publicKeys.Load(path); ok {
+		return key.(*rsa.PublicKey), nil
+	}
 	keyData, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read public key: %w", err)
 	}
-	return jwt.ParseRSAPublicKeyFromPEM(keyData)
+	key, err := jwt.ParseRSAPublicKeyFromPEM(keyData)
+	if err != nil {
+		return nil, err
+	}
+	publicKeys.Store(path, key)
+	return key, nil
 }
 
-func GenerateTokenPair(userID string, cfg JWTConfig) (accessToken, refreshToken string, err error) {
+func GenerateTokenPair(subject Subject, cfg JWTConfig) (accessToken, refreshToken string, err error) {
 	key, err := parsePrivateKey(cfg.PrivateKeyPath)
 	if err != nil {
 		return "", "", err
 	}
+	now := time.Now()
 
 	// Access Token
 	accessClaims := &Claims{
-		UserID:    userID,
+		UserID:    subject.UserID,
+		Role:      subject.Role,
+		TenantID:  subject.TenantID,
 		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(cfg.AccessExpiresIn) * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(cfg.AccessExpiresIn) * time.Minute)),
 		},
 	}
 	accessToken, err = jwt.NewWithClaims(jwt.SigningMethodRS256, accessClaims).SignedString(key)
@@ -68,10 +103,13 @@ func GenerateTokenPair(userID string, cfg JWTConfig) (accessToken, refreshToken
 
 	// Refresh Token
 	refreshClaims := &Claims{
-		UserID:    userID,
+		UserID:    subject.UserID,
+		Role:      subject.Role,
+		TenantID:  subject.TenantID,
 		TokenType: TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(cfg.RefreshExpiresIn) * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(cfg.RefreshExpiresIn) * time.Minute)),
 		},
 	}
 	refreshToken, err = jwt.NewWithClaims(jwt.SigningMethodRS256, refreshClaims).SignedString(key)
@@ -84,7 +122,7 @@ func GenerateTokenPair(userID string, cfg JWTConfig) (accessToken, refreshToken
 
 // Deprecated: Use GenerateTokenPair instead. Keeping for backward compatibility if needed, but updated to use AccessExpiresIn
 func GenerateToken(userID string, cfg JWTConfig) (string, error) {
-	accessToken, _, err := GenerateTokenPair(userID, cfg)
+	accessToken, _, err := GenerateTokenPair(Subject{UserID: userID}, cfg)
 	return accessToken, err
 }
 
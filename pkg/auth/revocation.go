@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Store holds revocation markers. It is satisfied by the application cache, so markers are
+// encoded as JSON numbers, which Redis stores as the plain unix time.
+type Store interface {
+	Get(ctx context.Context, key string, dest any) (bool, error)
+	Set(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error
+}
+
+// RevokedKey is the key holding the unix time before which all tokens of a user are revoked.
+func RevokedKey(userID string) string {
+	return fmt.Sprintf("auth:revoked:%s", userID)
+}
+
+// RevokeTokens revokes every token issued to the user up to now.
+// ttl should be at least the refresh token lifetime so no revoked token outlives the marker.
+func RevokeTokens(ctx context.Context, store Store, userID string, ttl time.Duration) error {
+	if err := store.Set(ctx, RevokedKey(userID), time.Now().Unix(), ttl); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token was issued before the user's tokens were revoked.
+// Tokens without an issued-at claim predate revocation support and are treated as revoked once a marker exists.
+func IsRevoked(ctx context.Context, store Store, claims *Claims) (bool, error) {
+	var revokedAt int64
+	found, err := store.Get(ctx, RevokedKey(claims.UserID), &revokedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if !found {
+		return false, nil
+	}
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	return claims.IssuedAt.Unix() <= revokedAt, nil
+}
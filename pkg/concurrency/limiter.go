@@ -0,0 +1,105 @@
+package concurrency
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Priority orders requests when the limiter is close to its limit.
+type Priority int
+
+const (
+	// PriorityLow requests only get the share of the limit set by Options.LowPriorityShare
+	PriorityLow Priority = iota
+	// PriorityNormal requests get the whole limit
+	PriorityNormal
+)
+
+type Options struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// LatencyTarget is the latency above which the limit is considered too high
+	LatencyTarget time.Duration
+	// Backoff multiplies the limit when a request exceeds LatencyTarget, such as 0.9
+	Backoff float64
+	// LowPriorityShare is the fraction of the limit low priority requests may fill
+	LowPriorityShare float64
+}
+
+// Limiter bounds in-flight requests with AIMD: the limit grows by one for every limit's worth of
+// requests completing within the latency target, and shrinks by Backoff when one does not. The
+// limit settles just below the concurrency at which latency starts to degrade.
+type Limiter struct {
+	opts Options
+
+	mu           sync.Mutex
+	limit        float64
+	inFlight     int
+	lastDecrease time.Time
+}
+
+func NewLimiter(opts Options) *Limiter {
+	opts.MinLimit = max(opts.MinLimit, 1)
+	opts.MaxLimit = max(opts.MaxLimit, opts.MinLimit)
+	initial := min(max(opts.InitialLimit, opts.MinLimit), opts.MaxLimit)
+	return &Limiter{opts: opts, limit: float64(initial)}
+}
+
+// Acquire admits a request of the given priority, or reports false when it should be shed. The
+// returned function must be called with the latency of every admitted request once it completes.
+func (l *Limiter) Acquire(priority Priority) (func(latency time.Duration), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	capacity := l.limit
+	if priority == PriorityLow {
+		capacity *= l.opts.LowPriorityShare
+	}
+	if float64(l.inFlight) >= math.Floor(capacity) {
+		return nil, false
+	}
+	l.inFlight++
+
+	var once sync.Once
+	return func(latency time.Duration) {
+		once.Do(func() { l.release(latency) })
+	}, true
+}
+
+func (l *Limiter) release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	inFlight := l.inFlight
+	l.inFlight--
+
+	now := time.Now()
+	if latency > l.opts.LatencyTarget {
+		// Requests admitted before the last decrease were measured against the old limit, so
+		// decrease at most once per target latency instead of collapsing the limit
+		if now.Sub(l.lastDecrease) >= l.opts.LatencyTarget {
+			l.limit = math.Max(float64(l.opts.MinLimit), l.limit*l.opts.Backoff)
+			l.lastDecrease = now
+		}
+		return
+	}
+	// Only grow when the limit is actually being used
+	if float64(inFlight)*2 >= l.limit {
+		l.limit = math.Min(float64(l.opts.MaxLimit), l.limit+1/l.limit)
+	}
+}
+
+// Limit returns the current limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight returns the number of admitted requests not yet released.
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
@@ -9,8 +9,10 @@ import (
 type CustomError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
-	Err     error  `json:"-"`
-	Stack   string `json:"-"`
+	// Reason is a stable machine-readable code for errors clients need to tell apart
+	Reason string `json:"reason,omitempty"`
+	Err    error  `json:"-"`
+	Stack  string `json:"-"`
 }
 
 func (e *CustomError) Error() string {
@@ -20,6 +22,11 @@ func (e *CustomError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the wrapped error, so errors.Is and errors.As see through usecase errors.
+func (e *CustomError) Unwrap() error {
+	return e.Err
+}
+
 func New(code int, message string) *CustomError {
 	return &CustomError{
 		Code:    code,
@@ -37,6 +44,12 @@ func Wrap(err error, code int, message string) *CustomError {
 	}
 }
 
+// WithReason sets the machine-readable reason and returns the error for chaining.
+func (e *CustomError) WithReason(reason string) *CustomError {
+	e.Reason = reason
+	return e
+}
+
 func getStackTrace() string {
 	var pc [32]uintptr
 	n := runtime.Callers(3, pc[:])
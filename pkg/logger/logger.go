@@ -76,6 +76,12 @@ func InfoCtx(ctx context.Context, message string, fields ...zap.Field) {
 	Log.Info(message, fields...)
 }
 
+// WarnCtx logs a warning message with trace context fields.
+func WarnCtx(ctx context.Context, message string, fields ...zap.Field) {
+	fields = append(fields, TraceFields(ctx)...)
+	Log.Warn(message, fields...)
+}
+
 // ErrorCtx logs an error message with trace context fields.
 func ErrorCtx(ctx context.Context, message string, fields ...zap.Field) {
 	fields = append(fields, TraceFields(ctx)...)
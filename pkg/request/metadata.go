@@ -0,0 +1,23 @@
+package request
+
+import "context"
+
+// Metadata describes who made a request and from where.
+type Metadata struct {
+	ActorID   string
+	IP        string
+	UserAgent string
+}
+
+type metadataKey struct{}
+
+// WithMetadata returns a copy of ctx carrying md.
+func WithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFromContext returns the request metadata in ctx, or an empty Metadata.
+func MetadataFromContext(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
@@ -25,6 +25,7 @@ type Meta struct {
 
 type ErrorDetail struct {
 	Code    int    `json:"code,omitempty"`
+	Reason  string `json:"reason,omitempty"`
 	Message string `json:"message,omitempty"`
 }
 
@@ -52,6 +53,7 @@ func Error(c *gin.Context, err error) {
 			Message: customErr.Message,
 			Error: &ErrorDetail{
 				Code:    customErr.Code,
+				Reason:  customErr.Reason,
 				Message: customErr.Error(),
 			},
 		})
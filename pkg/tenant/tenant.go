@@ -0,0 +1,43 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMissing is returned when tenant-scoped data is accessed without a tenant in the context.
+var ErrMissing = errors.New("tenant is required")
+
+type tenantKey struct{}
+
+type systemKey struct{}
+
+// WithID returns a copy of ctx scoped to the given tenant.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant in ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithSystem marks ctx as deliberately spanning every tenant, for work such as login, workers and
+// data subject requests. It clears any tenant already in ctx; WithID applied afterwards scopes it again.
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(WithID(ctx, ""), systemKey{}, true)
+}
+
+// Scope returns the tenant queries in ctx must be restricted to. An empty id with a nil error means
+// ctx is marked as system and queries are not restricted. Without either, ErrMissing is returned
+// so a forgotten tenant fails closed instead of exposing other tenants.
+func Scope(ctx context.Context) (string, error) {
+	if id, ok := FromContext(ctx); ok {
+		return id, nil
+	}
+	if system, _ := ctx.Value(systemKey{}).(bool); system {
+		return "", nil
+	}
+	return "", ErrMissing
+}
@@ -36,8 +36,6 @@ func TestMain(m *testing.M) {
 
 	// Connect to Database
 	db = database.Connect(cfg.Database)
-	// For integration tests, we use Master for both to avoid replication lag
-	db.Slave = db.Master
 
 	// Connect to Redis
 	rdb = redis.Connect(cfg.Redis)
@@ -58,7 +56,7 @@ func TestMain(m *testing.M) {
 
 func truncateTables() {
 	log.Println("--- TRUNCATE TABLES CALLED ---")
-	tables := []string{"users"}
+	tables := []string{"users", "organizations", "audit_events", "outbox"}
 	ctx := context.Background()
 	for _, table := range tables {
 		query := fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE", table)
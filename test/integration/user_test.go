@@ -9,7 +9,10 @@ import (
 	"testing"
 
 	"go-boilerplate/internal/delivery/http/handler"
+	"go-boilerplate/internal/delivery/http/middleware"
 	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/infrastructure/database"
 	"go-boilerplate/internal/repository"
 	"go-boilerplate/internal/usecase"
 	"go-boilerplate/pkg/response"
@@ -23,18 +26,30 @@ func TestUserIntegration(t *testing.T) {
 
 	// Initialize layers
 	userRepo := repository.NewUserRepository(db)
-	userUsecase := usecase.NewUserUsecase(userRepo, cfg, rdb)
+	auditRepo := repository.NewAuditRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	userUsecase := usecase.NewUserUsecase(userRepo, orgRepo, auditRepo, repository.NewOutboxRepository(db), db, cfg, cache.New(rdb), cache.New(rdb))
 	userHandler := handler.NewUserHandler(userUsecase)
 
 	// Setup Router
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.POST("/register", userHandler.Register)
-	r.POST("/login", userHandler.Login)
-	r.GET("/users/:id", userHandler.GetUser)
+	consistency := middleware.ConsistencyMiddleware(cfg.Consistency, db, cache.New(rdb))
+	r.POST("/register", consistency, userHandler.Register)
+	r.POST("/login", consistency, userHandler.Login)
+	users := r.Group("/users", middleware.AuthMiddleware(cfg.JWT, cache.New(rdb)), consistency)
+	users.GET("/:id", userHandler.GetUser)
+	users.GET("", userHandler.ListUsers)
 
 	var accessToken string
 	var userID string
+	// Returned by the registration, so reads right after it see the new user despite replication lag
+	var consistencyToken string
+	consistentCtx := func() context.Context {
+		lsn, _ := database.ParseLSN(consistencyToken)
+		ctx, _ := database.WithSession(context.Background(), lsn)
+		return ctx
+	}
 
 	t.Run("Register Success", func(t *testing.T) {
 		reqBody := dto.RegisterRequest{
@@ -53,6 +68,9 @@ func TestUserIntegration(t *testing.T) {
 		err := json.Unmarshal(w.Body.Bytes(), &res)
 		require.NoError(t, err)
 		require.True(t, res.Success, "Register failed: %s, Body: %s", res.Message, w.Body.String())
+
+		consistencyToken = w.Header().Get(middleware.ConsistencyHeader)
+		require.NotEmpty(t, consistencyToken)
 	})
 
 	t.Run("Register Duplicate Email Failure", func(t *testing.T) {
@@ -76,7 +94,7 @@ func TestUserIntegration(t *testing.T) {
 
 	t.Run("Login Success", func(t *testing.T) {
 		// Ensure user exists (sanity check)
-		user, err := userRepo.GetByEmail(context.Background(), "integration@example.com")
+		user, err := userRepo.GetByEmail(consistentCtx(), "integration@example.com")
 		require.NoError(t, err, "User should exist before login")
 		require.NotNil(t, user)
 
@@ -105,12 +123,14 @@ func TestUserIntegration(t *testing.T) {
 
 	t.Run("Get User Success", func(t *testing.T) {
 		// First get the user by email from DB to get the ID
-		user, err := userRepo.GetByEmail(context.Background(), "integration@example.com")
+		user, err := userRepo.GetByEmail(consistentCtx(), "integration@example.com")
 		require.NoError(t, err)
 		require.NotNil(t, user)
 		userID = user.ID
 
 		req, _ := http.NewRequest("GET", "/users/"+userID, nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set(middleware.ConsistencyHeader, consistencyToken)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
@@ -128,7 +148,8 @@ func TestUserIntegration(t *testing.T) {
 
 	t.Run("List Users Success", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/users?page=1&limit=10", nil)
-		r.GET("/users", userHandler.ListUsers) // Register route for this test
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set(middleware.ConsistencyHeader, consistencyToken)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
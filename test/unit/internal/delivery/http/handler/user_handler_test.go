@@ -10,8 +10,10 @@ import (
 	"time"
 
 	"go-boilerplate/internal/delivery/http/handler"
+	"go-boilerplate/internal/delivery/http/middleware"
 	"go-boilerplate/internal/dto"
 	"go-boilerplate/internal/entity"
+	appErrors "go-boilerplate/pkg/errors"
 	"go-boilerplate/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -29,8 +31,8 @@ func (m *MockUserUsecase) Register(ctx context.Context, email, password string)
 	return args.Error(0)
 }
 
-func (m *MockUserUsecase) Login(ctx context.Context, email, password string) (string, string, error) {
-	args := m.Called(ctx, email, password)
+func (m *MockUserUsecase) Login(ctx context.Context, email, password, organizationID string) (string, string, error) {
+	args := m.Called(ctx, email, password, organizationID)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
@@ -39,11 +41,28 @@ func (m *MockUserUsecase) RefreshToken(ctx context.Context, refreshToken string)
 	return args.String(0), args.String(1), args.Error(2)
 }
 
-func (m *MockUserUsecase) ListUsers(ctx context.Context, page, limit int, order string, timezone string) ([]dto.UserResponse, response.Meta, error) {
-	args := m.Called(ctx, page, limit, order, timezone)
+func (m *MockUserUsecase) ListUsers(ctx context.Context, req dto.ListUsersRequest, timezone string) ([]dto.UserResponse, response.Meta, error) {
+	args := m.Called(ctx, req, timezone)
 	return args.Get(0).([]dto.UserResponse), args.Get(1).(response.Meta), args.Error(2)
 }
 
+func (m *MockUserUsecase) ExportUsers(ctx context.Context, req dto.ListUsersRequest, timezone string, fn func(user dto.UserResponse) error) error {
+	args := m.Called(ctx, req, timezone, fn)
+	if users, ok := args.Get(0).([]dto.UserResponse); ok {
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockUserUsecase) SearchUsers(ctx context.Context, req dto.SearchUsersRequest) ([]dto.UserSearchResult, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]dto.UserSearchResult), args.Error(1)
+}
+
 func (m *MockUserUsecase) GetUser(ctx context.Context, id string, timezone string) (*entity.User, error) {
 	args := m.Called(ctx, id, timezone)
 	if args.Get(0) == nil {
@@ -52,6 +71,14 @@ func (m *MockUserUsecase) GetUser(ctx context.Context, id string, timezone strin
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (m *MockUserUsecase) GetCurrentUser(ctx context.Context, id string) (*entity.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
 func (m *MockUserUsecase) UpdateUser(ctx context.Context, id string, email string) error {
 	args := m.Called(ctx, id, email)
 	return args.Error(0)
@@ -62,6 +89,21 @@ func (m *MockUserUsecase) DeleteUser(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *MockUserUsecase) ChangeRole(ctx context.Context, id string, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserUsecase) ChangeStatus(ctx context.Context, actorID, id string, req dto.ChangeUserStatusRequest) error {
+	args := m.Called(ctx, actorID, id, req)
+	return args.Error(0)
+}
+
+func (m *MockUserUsecase) LiftExpiredSuspensions(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
@@ -107,7 +149,7 @@ func TestUserHandler_Login(t *testing.T) {
 	}
 	body, _ := json.Marshal(reqBody)
 
-	mockUsecase.On("Login", mock.Anything, reqBody.Email, reqBody.Password).Return("access-token", "refresh-token", nil)
+	mockUsecase.On("Login", mock.Anything, reqBody.Email, reqBody.Password, "").Return("access-token", "refresh-token", nil)
 
 	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
 	w := httptest.NewRecorder()
@@ -134,7 +176,7 @@ func TestUserHandler_ListUsers(t *testing.T) {
 		{ID: "019c514b-a933-74f2-8d08-a496675c66cf", Email: "u1@example.com"},
 	}
 	meta := response.Meta{Total: 1, Limit: 10, Offset: 0, Order: "created_at desc"}
-	mockUsecase.On("ListUsers", mock.Anything, 1, 10, "", "UTC").Return(userResponses, meta, nil)
+	mockUsecase.On("ListUsers", mock.Anything, dto.ListUsersRequest{Page: 1, Limit: 10}, "UTC").Return(userResponses, meta, nil)
 
 	req, _ := http.NewRequest("GET", "/users?page=1&limit=10", nil)
 	w := httptest.NewRecorder()
@@ -190,3 +232,141 @@ func TestUserHandler_GetUser_Timezone(t *testing.T) {
 	assert.Equal(t, 7*3600, offset, "Offset should be 7 hours for Asia/Jakarta")
 }
 
+
+func TestUserHandler_ExportUsers_CSV(t *testing.T) {
+	mockUsecase := new(MockUserUsecase)
+	h := handler.NewUserHandler(mockUsecase)
+
+	r := setupRouter()
+	r.GET("/admin/users/export", h.ExportUsers)
+
+	createdAt := time.Date(2024, 1, 1, 7, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+	users := []dto.UserResponse{
+		{ID: "1", Email: "u1@example.com", CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: "2", Email: "u2@example.com", CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+	mockUsecase.On("ExportUsers", mock.Anything, dto.ListUsersRequest{Search: "example"}, "Asia/Jakarta", mock.Anything).Return(users, nil)
+
+	req, _ := http.NewRequest("GET", "/admin/users/export?format=csv&search=example", nil)
+	req.Header.Set("X-Timezone", "Asia/Jakarta")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	assert.Equal(t, "id,email,first_name,last_name,created_at,updated_at\n"+
+		"1,u1@example.com,,,2024-01-01T07:00:00+07:00,2024-01-01T07:00:00+07:00\n"+
+		"2,u2@example.com,,,2024-01-01T07:00:00+07:00,2024-01-01T07:00:00+07:00\n", w.Body.String())
+}
+
+func TestUserHandler_ExportUsers_UnsupportedFormat(t *testing.T) {
+	mockUsecase := new(MockUserUsecase)
+	h := handler.NewUserHandler(mockUsecase)
+
+	r := setupRouter()
+	r.GET("/admin/users/export", h.ExportUsers)
+
+	req, _ := http.NewRequest("GET", "/admin/users/export?format=xml", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertNotCalled(t, "ExportUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserHandler_Me(t *testing.T) {
+	mockUsecase := new(MockUserUsecase)
+	h := handler.NewUserHandler(mockUsecase)
+
+	r := setupRouter()
+	r.GET("/me", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Set("tenantID", "org-1")
+	}, middleware.CurrentUserMiddleware(mockUsecase), h.Me)
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockUsecase.On("GetCurrentUser", mock.Anything, "user-1").Return(&entity.User{
+		ID: "user-1", Email: "me@example.com", FirstName: "Jane", Role: entity.RoleAdmin, CreatedAt: createdAt, UpdatedAt: createdAt,
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("X-Timezone", "Asia/Jakarta")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Data dto.ProfileResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, "me@example.com", res.Data.Email)
+	assert.Equal(t, entity.RoleAdmin, res.Data.Role)
+	assert.Equal(t, "org-1", res.Data.OrganizationID)
+	_, offset := res.Data.CreatedAt.Zone()
+	assert.Equal(t, 7*3600, offset)
+}
+
+func TestUserHandler_Me_DeletedAccount(t *testing.T) {
+	mockUsecase := new(MockUserUsecase)
+	h := handler.NewUserHandler(mockUsecase)
+
+	r := setupRouter()
+	r.GET("/me", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+	}, middleware.CurrentUserMiddleware(mockUsecase), h.Me)
+
+	mockUsecase.On("GetCurrentUser", mock.Anything, "user-1").Return(nil, appErrors.New(http.StatusUnauthorized, "Account no longer exists"))
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Account no longer exists")
+}
+
+func TestUserHandler_Me_SuspendedAccount(t *testing.T) {
+	mockUsecase := new(MockUserUsecase)
+	h := handler.NewUserHandler(mockUsecase)
+
+	r := setupRouter()
+	r.GET("/me", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+	}, middleware.CurrentUserMiddleware(mockUsecase), h.Me)
+
+	mockUsecase.On("GetCurrentUser", mock.Anything, "user-1").
+		Return(nil, appErrors.New(http.StatusForbidden, "Account is suspended").WithReason("account_suspended"))
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"error":"Account is suspended","reason":"account_suspended"}`, w.Body.String())
+}
+
+func TestUserHandler_ChangeStatus(t *testing.T) {
+	mockUsecase := new(MockUserUsecase)
+	h := handler.NewUserHandler(mockUsecase)
+
+	r := setupRouter()
+	r.PUT("/admin/users/:id/status", func(c *gin.Context) {
+		c.Set("userID", "admin-1")
+	}, h.ChangeStatus)
+
+	mockUsecase.On("ChangeStatus", mock.Anything, "admin-1", "user-1", dto.ChangeUserStatusRequest{Status: "banned", Reason: "fraud"}).Return(nil)
+
+	req, _ := http.NewRequest("PUT", "/admin/users/user-1/status", bytes.NewBufferString(`{"status":"banned","reason":"fraud"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("PUT", "/admin/users/user-1/status", bytes.NewBufferString(`{"status":"frozen"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
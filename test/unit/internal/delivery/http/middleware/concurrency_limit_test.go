@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/delivery/http/middleware"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/pkg/auth"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testJWTConfig writes a fresh RSA key pair to a temporary directory.
+func testJWTConfig(t *testing.T) config.JWTConfig {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	cfg := config.JWTConfig{
+		PrivateKeyPath:  filepath.Join(dir, "private.pem"),
+		PublicKeyPath:   filepath.Join(dir, "public.pem"),
+		AccessExpiresIn: 15,
+	}
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(cfg.PrivateKeyPath, privPEM, 0o600))
+	require.NoError(t, os.WriteFile(cfg.PublicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), 0o600))
+	return cfg
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.ConcurrencyConfig{
+		Enabled:        true,
+		InitialLimit:   2,
+		MinLimit:       1,
+		MaxLimit:       2,
+		LatencyTarget:  time.Minute,
+		Backoff:        0.9,
+		AnonymousShare: 0.5,
+		RetryAfter:     2 * time.Second,
+		ExemptRoutes:   []string{"/export"},
+	}
+
+	unblock := make(chan struct{})
+	started := make(chan struct{}, 10)
+	r := gin.New()
+	jwtCfg := testJWTConfig(t)
+	token, _, err := auth.GenerateTokenPair(auth.Subject{UserID: "user-1", TenantID: "org-1"}, jwtCfg)
+	require.NoError(t, err)
+	r.Use(middleware.ConcurrencyLimitMiddleware(cfg, jwtCfg))
+	r.GET("/slow", func(c *gin.Context) {
+		started <- struct{}{}
+		<-unblock
+		c.String(http.StatusOK, "ok")
+	})
+	r.GET("/export", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	bearer := map[string]string{"Authorization": "Bearer " + token}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		get(r, "/slow", nil)
+	}()
+	<-started
+
+	w := get(r, "/slow", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "anonymous requests only get half the limit")
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	forged := map[string]string{"Authorization": "Bearer forged"}
+	assert.Equal(t, http.StatusServiceUnavailable, get(r, "/slow", forged).Code, "invalid tokens count as anonymous")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		get(r, "/slow", bearer)
+	}()
+	<-started
+
+	assert.Equal(t, http.StatusServiceUnavailable, get(r, "/slow", bearer).Code)
+	assert.Equal(t, http.StatusOK, get(r, "/export", nil).Code, "exempt routes are never shed")
+
+	close(unblock)
+	wg.Wait()
+	require.Equal(t, http.StatusOK, get(r, "/slow", nil).Code)
+}
+
+func TestConcurrencyLimit_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ConcurrencyLimitMiddleware(config.ConcurrencyConfig{}, config.JWTConfig{}))
+	r.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestConcurrencyLimit_AuthReusesValidatedClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rdb := goredis.NewClient(&goredis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	jwtCfg := testJWTConfig(t)
+	token, _, err := auth.GenerateTokenPair(auth.Subject{UserID: "user-1", TenantID: "org-1"}, jwtCfg)
+	require.NoError(t, err)
+
+	// AuthMiddleware could not verify the token itself with these keys
+	unusable := config.JWTConfig{PublicKeyPath: filepath.Join(t.TempDir(), "missing.pem")}
+	r := gin.New()
+	r.Use(middleware.ConcurrencyLimitMiddleware(config.ConcurrencyConfig{
+		Enabled: true, InitialLimit: 2, MinLimit: 1, MaxLimit: 2, LatencyTarget: time.Minute, Backoff: 0.9, AnonymousShare: 0.5,
+	}, jwtCfg), middleware.AuthMiddleware(unusable, cache.New(rdb)))
+	r.GET("/me", func(c *gin.Context) { c.String(http.StatusOK, c.GetString("userID")) })
+
+	w := get(r, "/me", map[string]string{"Authorization": "Bearer " + token})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user-1", w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, get(r, "/me", map[string]string{"Authorization": "Bearer forged"}).Code)
+}
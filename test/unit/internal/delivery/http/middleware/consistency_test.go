@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/delivery/http/middleware"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/infrastructure/database"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/pashagolub/pgxmock/v4"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLSNReader struct {
+	lsn   database.LSN
+	calls int
+}
+
+func (f *fakeLSNReader) CurrentLSN(context.Context) (database.LSN, error) {
+	f.calls++
+	return f.lsn, nil
+}
+
+// newConsistentRouter authenticates requests carrying X-User, like AuthMiddleware. POST handlers
+// write to the master, GET handlers only read, from the master for /users/master.
+func newConsistentRouter(t *testing.T, cfg config.ConsistencyConfig, lsn *fakeLSNReader) (*gin.Engine, cache.Cache) {
+	gin.SetMode(gin.TestMode)
+	rdb := goredis.NewClient(&goredis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	tokens := cache.New(rdb)
+
+	master, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	db := &database.Database{Master: master}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Set("userID", user)
+		}
+	})
+	r.Use(middleware.ConsistencyMiddleware(cfg, lsn, tokens))
+	r.POST("/users", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		master.ExpectExec(regexp.QuoteMeta("INSERT INTO users")).WithArgs("a@example.com").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		_, err := db.Writer(ctx).Exec(ctx, "INSERT INTO users (email) VALUES ($1)", "a@example.com")
+		require.NoError(t, err)
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+	r.GET("/users/master", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		master.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		_, err := db.Writer(ctx).Exec(ctx, "SELECT 1")
+		require.NoError(t, err)
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	r.GET("/users", func(c *gin.Context) {
+		db.Reader(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	return r, tokens
+}
+
+func TestConsistency_ReturnsTokenAfterWrites(t *testing.T) {
+	lsn := &fakeLSNReader{lsn: 0x3000060}
+	r, tokens := newConsistentRouter(t, config.ConsistencyConfig{Enabled: true, TTL: time.Minute}, lsn)
+
+	w := get(r, "/users", map[string]string{"X-User": "u1"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(middleware.ConsistencyHeader))
+	assert.Zero(t, lsn.calls)
+
+	// Reads from the master are not writes
+	w = get(r, "/users/master", map[string]string{"X-User": "u1"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(middleware.ConsistencyHeader))
+	assert.Zero(t, lsn.calls)
+
+	// Anonymous writes, such as registering, only get the header
+	w = post(r, "/users")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "0/3000060", w.Header().Get(middleware.ConsistencyHeader))
+
+	req, _ := http.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set("X-User", "u1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "0/3000060", w.Header().Get(middleware.ConsistencyHeader))
+
+	var stored string
+	found, err := tokens.Get(context.Background(), "u1", &stored)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "0/3000060", stored)
+}
+
+func TestConsistency_UsesClientTokenWithoutQueryingMaster(t *testing.T) {
+	lsn := &fakeLSNReader{lsn: 0x3000060}
+	gin.SetMode(gin.TestMode)
+	rdb := goredis.NewClient(&goredis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	var required database.LSN
+	r := gin.New()
+	r.Use(middleware.ConsistencyMiddleware(config.ConsistencyConfig{Enabled: true, TTL: time.Minute}, lsn, cache.New(rdb)))
+	r.GET("/users", func(c *gin.Context) {
+		required = database.MinLSN(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	get(r, "/users", map[string]string{middleware.ConsistencyHeader: "0/3000000"})
+	assert.Equal(t, database.LSN(0x3000000), required)
+
+	get(r, "/users", map[string]string{middleware.ConsistencyHeader: "not-a-position"})
+	assert.Zero(t, required)
+
+	// Reads only checked the token, the master is queried after writes alone
+	assert.Zero(t, lsn.calls)
+}
+
+func TestConsistency_Disabled(t *testing.T) {
+	lsn := &fakeLSNReader{lsn: 0x3000060}
+	r, _ := newConsistentRouter(t, config.ConsistencyConfig{}, lsn)
+
+	w := post(r, "/users")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(middleware.ConsistencyHeader))
+	assert.Zero(t, lsn.calls)
+}
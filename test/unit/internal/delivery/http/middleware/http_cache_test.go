@@ -0,0 +1,140 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/delivery/http/middleware"
+	"go-boilerplate/internal/infrastructure/cache"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/response"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCachedRouter(t *testing.T, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	cfg := config.HTTPCacheConfig{
+		Control: map[string]string{
+			"/users/:id": "private, no-cache",
+			"/products":  "private, max-age=30",
+		},
+		SharedRoutes: []string{"/products"},
+		SharedTTL:    time.Minute,
+		Vary:         []string{"Accept-Language"},
+	}
+
+	r := gin.New()
+	r.Use(middleware.HTTPCacheMiddleware(cfg, cache.New(rdb)))
+	r.GET("/users/:id", func(c *gin.Context) {
+		*calls++
+		if c.Param("id") == "missing" {
+			response.Error(c, appErrors.New(http.StatusNotFound, "User not found"))
+			return
+		}
+		response.Success(c, http.StatusOK, "OK", gin.H{"id": c.Param("id")})
+	})
+	r.GET("/products", func(c *gin.Context) {
+		*calls++
+		response.Success(c, http.StatusOK, "OK", gin.H{"page": c.Query("page")})
+	})
+	r.GET("/health", func(c *gin.Context) {
+		*calls++
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func get(r *gin.Engine, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHTTPCache_ETagAndNotModified(t *testing.T) {
+	var calls int
+	r := newCachedRouter(t, &calls)
+
+	w := get(r, "/users/1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), `"id":"1"`)
+
+	w = get(r, "/users/1", map[string]string{"If-None-Match": `"other", W/` + etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	w = get(r, "/users/2", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	// Private routes always reach the handler
+	assert.Equal(t, 3, calls)
+}
+
+func TestHTTPCache_ErrorsAreNotCacheable(t *testing.T) {
+	var calls int
+	r := newCachedRouter(t, &calls)
+
+	w := get(r, "/users/missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), "User not found")
+}
+
+func TestHTTPCache_SharedResponses(t *testing.T) {
+	var calls int
+	r := newCachedRouter(t, &calls)
+
+	first := get(r, "/products?page=1&limit=10", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+
+	// Query order does not matter
+	second := get(r, "/products?limit=10&page=1", nil)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+	assert.Equal(t, first.Header().Get("Content-Type"), second.Header().Get("Content-Type"))
+	assert.Equal(t, 1, calls)
+
+	w := get(r, "/products?page=1&limit=10", map[string]string{"If-None-Match": first.Header().Get("ETag")})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, 1, calls)
+
+	// A different value of a vary header is a different entry
+	w = get(r, "/products?page=1&limit=10", map[string]string{"Accept-Language": "id"})
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestHTTPCache_UnconfiguredRoutesPassThrough(t *testing.T) {
+	var calls int
+	r := newCachedRouter(t, &calls)
+
+	w := get(r, "/health", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,15 @@
+package middleware_test
+
+import (
+	"os"
+	"testing"
+
+	"go-boilerplate/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	// Console-only logger, middlewares log infrastructure failures
+	logger.InitLogger(nil)
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,194 @@
+package middleware_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-boilerplate/internal/delivery/http/middleware"
+	"go-boilerplate/internal/infrastructure/ratelimit"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultPolicy = ratelimit.Policy{Limit: 2, WindowSeconds: 60}
+
+func loadPolicies(t *testing.T, content string) *ratelimit.Policies {
+	path := ""
+	if content != "" {
+		path = filepath.Join(t.TempDir(), "policies.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	policies, err := ratelimit.LoadPolicies(path, defaultPolicy)
+	require.NoError(t, err)
+	return policies
+}
+
+// newRateLimitedRouter authenticates requests carrying X-User and X-Tenant, like AuthMiddleware.
+func newRateLimitedRouter(t *testing.T, mr *miniredis.Miniredis, algorithm, mode string, policies *ratelimit.Policies) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Set("userID", user)
+			c.Set("tenantID", c.GetHeader("X-Tenant"))
+		}
+	})
+	limiter := ratelimit.WithFallback(ratelimit.New(rdb, algorithm), ratelimit.FallbackOptions{Mode: mode, Threshold: 5})
+	r.Use(middleware.RateLimitMiddleware(limiter, policies))
+	r.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	r.POST("/login", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/products", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/orgs/:id", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return r
+}
+
+func post(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	for _, algorithm := range []string{ratelimit.AlgorithmSlidingWindow, ratelimit.AlgorithmTokenBucket} {
+		t.Run(algorithm, func(t *testing.T) {
+			r := newRateLimitedRouter(t, miniredis.RunT(t), algorithm, ratelimit.FailureModeOpen, loadPolicies(t, ""))
+
+			w := get(r, "/ping", nil)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, ratelimit.DefaultPolicyName, w.Header().Get("X-RateLimit-Policy"))
+			assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+			assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+			assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"), "success responses carry the reset too")
+
+			get(r, "/ping", nil)
+			w = get(r, "/ping", nil)
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+			assert.Contains(t, w.Body.String(), "Too many requests")
+		})
+	}
+}
+
+func TestRateLimit_RedisDown(t *testing.T) {
+	t.Run("open", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		r := newRateLimitedRouter(t, mr, ratelimit.AlgorithmSlidingWindow, ratelimit.FailureModeOpen, loadPolicies(t, ""))
+		mr.Close()
+
+		for i := 0; i < 3; i++ {
+			w := get(r, "/ping", nil)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		r := newRateLimitedRouter(t, mr, ratelimit.AlgorithmSlidingWindow, ratelimit.FailureModeClosed, loadPolicies(t, ""))
+		mr.Close()
+
+		w := get(r, "/ping", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("local", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		r := newRateLimitedRouter(t, mr, ratelimit.AlgorithmSlidingWindow, ratelimit.FailureModeLocal, loadPolicies(t, ""))
+		mr.Close()
+
+		assert.Equal(t, http.StatusOK, get(r, "/ping", nil).Code)
+		assert.Equal(t, http.StatusOK, get(r, "/ping", nil).Code)
+		w := get(r, "/ping", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	})
+}
+
+const testPolicies = `{
+  "policies": [
+    {"name": "login", "routes": ["POST /login"], "identity": "ip", "limit": 1, "window_seconds": 60},
+    {"name": "products", "routes": ["/products"], "identity": "user", "limit": 1, "window_seconds": 60,
+     "plans": {"pro": {"limit": 5}}},
+    {"name": "orgs", "routes": ["/orgs/*"], "identity": "tenant", "limit": 1, "window_seconds": 60},
+    {"name": "partners", "routes": ["/ping"], "identity": "api_key", "limit": 1, "window_seconds": 60}
+  ],
+  "plan_members": {"pro": ["tenant:t-pro"], "partner": ["api_key:%s"]},
+  "exempt": ["user:monitor", "api_key:%s"]
+}`
+
+func TestRateLimit_Policies(t *testing.T) {
+	keyHash := func(key string) string {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+	content := fmt.Sprintf(testPolicies, keyHash("partner-key"), keyHash("internal-key"))
+	r := newRateLimitedRouter(t, miniredis.RunT(t), ratelimit.AlgorithmSlidingWindow, ratelimit.FailureModeOpen, loadPolicies(t, content))
+
+	t.Run("routes have separate budgets", func(t *testing.T) {
+		w := post(r, "/login")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "login", w.Header().Get("X-RateLimit-Policy"))
+		assert.Equal(t, http.StatusTooManyRequests, post(r, "/login").Code)
+
+		w = get(r, "/products", map[string]string{"X-User": "u1"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "products", w.Header().Get("X-RateLimit-Policy"))
+	})
+
+	t.Run("counted per user", func(t *testing.T) {
+		assert.Equal(t, http.StatusTooManyRequests, get(r, "/products", map[string]string{"X-User": "u1"}).Code)
+		assert.Equal(t, http.StatusOK, get(r, "/products", map[string]string{"X-User": "u2"}).Code)
+	})
+
+	t.Run("counted per tenant", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get(r, "/orgs/1", map[string]string{"X-User": "u1", "X-Tenant": "t1"}).Code)
+		w := get(r, "/orgs/2", map[string]string{"X-User": "u2", "X-Tenant": "t1"})
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "orgs", w.Header().Get("X-RateLimit-Policy"))
+	})
+
+	t.Run("plan overrides", func(t *testing.T) {
+		headers := map[string]string{"X-User": "u3", "X-Tenant": "t-pro"}
+		for i := 0; i < 5; i++ {
+			w := get(r, "/products", headers)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "pro", w.Header().Get("X-RateLimit-Plan"))
+			assert.Equal(t, "5", w.Header().Get("X-RateLimit-Limit"))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, get(r, "/products", headers).Code)
+	})
+
+	t.Run("exemptions", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, get(r, "/products", map[string]string{"X-User": "monitor"}).Code)
+			assert.Equal(t, http.StatusOK, get(r, "/ping", map[string]string{"X-API-Key": "internal-key"}).Code)
+		}
+	})
+
+	t.Run("counted per api key", func(t *testing.T) {
+		w := get(r, "/ping", map[string]string{"X-API-Key": "partner-key"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "partner", w.Header().Get("X-RateLimit-Plan"))
+		assert.Equal(t, http.StatusTooManyRequests, get(r, "/ping", map[string]string{"X-API-Key": "partner-key"}).Code)
+	})
+
+	t.Run("unknown api keys counted per ip", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get(r, "/ping", map[string]string{"X-API-Key": "k1"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, get(r, "/ping", map[string]string{"X-API-Key": "k2"}).Code)
+	})
+}
@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-boilerplate/internal/delivery/http/middleware"
+	"go-boilerplate/internal/entity"
+	appErrors "go-boilerplate/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole_PlatformAdminRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	status := func(role string) int {
+		r := gin.New()
+		r.Use(func(c *gin.Context) { c.Set("role", role) })
+		r.PUT("/users/:id/status", middleware.RequireRole(entity.RolePlatformAdmin), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/users/1/status", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, status(entity.RolePlatformAdmin))
+	assert.Equal(t, http.StatusForbidden, status(entity.RoleAdmin))
+	assert.Equal(t, http.StatusForbidden, status(entity.RoleUser))
+}
+
+// membershipLoader returns the membership role of each user, users without one are not members
+type membershipLoader map[string]string
+
+func (l membershipLoader) CurrentMembership(ctx context.Context, userID string) (*entity.Membership, error) {
+	role, ok := l[userID]
+	if !ok {
+		return nil, appErrors.New(http.StatusForbidden, "Not a member of this organization")
+	}
+	return &entity.Membership{UserID: userID, Role: role}, nil
+}
+
+func TestRequireMembershipRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	loader := membershipLoader{
+		"owner":  entity.MembershipRoleOwner,
+		"admin":  entity.MembershipRoleAdmin,
+		"member": entity.MembershipRoleMember,
+	}
+
+	status := func(userID, globalRole string) int {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set("userID", userID)
+			c.Set("role", globalRole)
+		})
+		r.GET("/audit", middleware.RequireMembershipRole(loader, entity.MembershipRoleOwner, entity.MembershipRoleAdmin),
+			func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/audit", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, status("owner", entity.RoleUser))
+	assert.Equal(t, http.StatusNoContent, status("admin", entity.RoleUser))
+	assert.Equal(t, http.StatusForbidden, status("member", entity.RoleAdmin), "the global role does not count")
+	assert.Equal(t, http.StatusForbidden, status("stranger", entity.RolePlatformAdmin))
+}
@@ -0,0 +1,147 @@
+package httpgateway_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/entity"
+	httpgateway "go-boilerplate/internal/gateway/http"
+	"go-boilerplate/internal/infrastructure/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCatalog returns a page titled with the number of the call, or err when set.
+type fakeCatalog struct {
+	calls atomic.Int32
+	mu    sync.Mutex
+	err   error
+	delay time.Duration
+}
+
+func (f *fakeCatalog) GetProducts(ctx context.Context, limit, skip int) ([]entity.Product, int64, error) {
+	n := f.calls.Add(1)
+	time.Sleep(f.delay)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, 0, f.err
+	}
+	return []entity.Product{{ID: int(n), Title: "page"}}, 100, nil
+}
+
+func (f *fakeCatalog) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+var swrConfig = config.ProductConfig{
+	FreshTTL:       50 * time.Millisecond,
+	StaleTTL:       time.Hour,
+	MaxStale:       24 * time.Hour,
+	RefreshTimeout: time.Second,
+}
+
+func newGateway(t *testing.T, cfg config.ProductConfig) (*fakeCatalog, httpgateway.ProductGateway) {
+	mr := miniredis.RunT(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	upstream := &fakeCatalog{}
+	return upstream, httpgateway.NewCachedProductGateway(upstream, cache.New(rdb), cfg)
+}
+
+func getProducts(gw httpgateway.ProductGateway) ([]entity.Product, *httpgateway.CacheInfo, error) {
+	ctx, info := httpgateway.WithCacheInfo(context.Background())
+	products, _, err := gw.GetProducts(ctx, 10, 0)
+	return products, info, err
+}
+
+func TestCachedProductGateway_MissThenHit(t *testing.T) {
+	upstream, gw := newGateway(t, config.ProductConfig{FreshTTL: time.Minute, StaleTTL: time.Minute})
+
+	products, info, err := getProducts(gw)
+	require.NoError(t, err)
+	assert.Equal(t, httpgateway.CacheMiss, info.Result)
+	assert.Equal(t, 1, products[0].ID)
+
+	products, info, err = getProducts(gw)
+	require.NoError(t, err)
+	assert.Equal(t, httpgateway.CacheHit, info.Result)
+	assert.Equal(t, 1, products[0].ID)
+	assert.Equal(t, int32(1), upstream.calls.Load())
+}
+
+func TestCachedProductGateway_StaleIsRefreshedInBackground(t *testing.T) {
+	upstream, gw := newGateway(t, swrConfig)
+
+	_, _, err := getProducts(gw)
+	require.NoError(t, err)
+	time.Sleep(2 * swrConfig.FreshTTL)
+
+	upstream.delay = 100 * time.Millisecond
+	start := time.Now()
+	products, info, err := getProducts(gw)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), upstream.delay, "stale pages are served without waiting for the catalog")
+	assert.Equal(t, httpgateway.CacheStale, info.Result)
+	assert.False(t, info.UpstreamFailed)
+	assert.Equal(t, 1, products[0].ID)
+
+	assert.Eventually(t, func() bool {
+		products, info, err := getProducts(gw)
+		return err == nil && info.Result == httpgateway.CacheHit && products[0].ID == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCachedProductGateway_ServesLastKnownPageWhenUpstreamDown(t *testing.T) {
+	cfg := swrConfig
+	cfg.StaleTTL = 0
+	upstream, gw := newGateway(t, cfg)
+
+	_, _, err := getProducts(gw)
+	require.NoError(t, err)
+	time.Sleep(2 * cfg.FreshTTL)
+
+	upstream.fail(errors.New("catalog down"))
+	products, info, err := getProducts(gw)
+	require.NoError(t, err)
+	assert.Equal(t, httpgateway.CacheStale, info.Result)
+	assert.True(t, info.UpstreamFailed)
+	assert.Equal(t, 1, products[0].ID)
+	assert.Equal(t, int32(2), upstream.calls.Load())
+}
+
+func TestCachedProductGateway_UpstreamErrorWithoutCachedPage(t *testing.T) {
+	upstream, gw := newGateway(t, swrConfig)
+	upstream.fail(errors.New("catalog down"))
+
+	_, _, err := getProducts(gw)
+	assert.EqualError(t, err, "catalog down")
+}
+
+func TestCachedProductGateway_ConcurrentMissesShareOneCall(t *testing.T) {
+	upstream, gw := newGateway(t, swrConfig)
+	upstream.delay = 50 * time.Millisecond
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := getProducts(gw)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), upstream.calls.Load())
+}
@@ -0,0 +1,15 @@
+package httpgateway_test
+
+import (
+	"os"
+	"testing"
+
+	"go-boilerplate/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	// Console-only logger, the cached gateway logs upstream failures
+	logger.InitLogger(nil)
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,146 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/infrastructure/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplica starts a bus as one API replica would, sharing the Redis of mr.
+func newReplica(t *testing.T, mr *miniredis.Miniredis) *cache.Bus {
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	subscribers := mr.PubSubNumSub("cache:invalidate")["cache:invalidate"]
+	bus := cache.NewBus(rdb)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go bus.Run(ctx)
+
+	// Invalidations published before the subscription would be missed
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub("cache:invalidate")["cache:invalidate"] > subscribers
+	}, time.Second, time.Millisecond)
+	return bus
+}
+
+func TestBus_ServesFromLocalLayer(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newReplica(t, mr).Namespace("user", cache.LocalOptions{Size: 10, TTL: time.Minute})
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "user:1", item{Name: "one"}, time.Minute, "user:1"))
+
+	var got item
+	found, err := c.Get(ctx, "user:1", &got)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// Served locally, Redis is not consulted again
+	mr.Set("user:1", `{"name":"changed behind the cache"}`)
+	found, err = c.Get(ctx, "user:1", &got)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "one", got.Name)
+}
+
+func TestBus_InvalidationReachesOtherReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	opts := cache.LocalOptions{Size: 10, TTL: time.Minute}
+	a := newReplica(t, mr).Namespace("user", opts)
+	b := newReplica(t, mr).Namespace("user", opts)
+	ctx := context.Background()
+
+	require.NoError(t, a.Set(ctx, "user:1", item{Name: "one"}, time.Minute, "user:1"))
+	var got item
+	found, err := b.Get(ctx, "user:1", &got)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.NoError(t, a.Invalidate(ctx, "user:1"))
+
+	assert.Eventually(t, func() bool {
+		found, err := b.Get(ctx, "user:1", &got)
+		return err == nil && !found
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBus_BroadcastsOnlyNamespacesCachedLocally(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	ctx := context.Background()
+
+	sub := rdb.Subscribe(ctx, "cache:invalidate")
+	t.Cleanup(func() { sub.Close() })
+	_, err := sub.Receive(ctx)
+	require.NoError(t, err)
+
+	bus := cache.NewBus(rdb)
+	remoteOnly := bus.Namespace("http", cache.LocalOptions{})
+	shared := bus.Namespace("user", cache.LocalOptions{Broadcast: true})
+
+	require.NoError(t, remoteOnly.Set(ctx, "http:1", item{Name: "one"}, time.Minute))
+	require.NoError(t, shared.Set(ctx, "user:1", item{Name: "one"}, time.Minute))
+
+	// Published in order, so the first message shows the http namespace sent nothing
+	select {
+	case msg := <-sub.Channel():
+		assert.JSONEq(t, `{"keys":["user:1"]}`, msg.Payload)
+	case <-time.After(time.Second):
+		t.Fatal("no invalidation broadcast")
+	}
+}
+
+func TestBus_CachesMisses(t *testing.T) {
+	mr := miniredis.RunT(t)
+	opts := cache.LocalOptions{Size: 10, TTL: time.Minute, CacheMisses: true}
+	a := newReplica(t, mr).Namespace("revocation", opts)
+	b := newReplica(t, mr).Namespace("revocation", opts)
+	ctx := context.Background()
+
+	var revokedAt int64
+	found, err := b.Get(ctx, "auth:revoked:1", &revokedAt)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	// The miss is remembered locally...
+	mr.Set("auth:revoked:1", "1700000000")
+	found, _ = b.Get(ctx, "auth:revoked:1", &revokedAt)
+	assert.False(t, found)
+
+	// ...until a replica writes the key through the bus
+	require.NoError(t, a.Set(ctx, "auth:revoked:1", int64(1700000001), time.Minute))
+	assert.Eventually(t, func() bool {
+		found, err := b.Get(ctx, "auth:revoked:1", &revokedAt)
+		return err == nil && found && revokedAt == 1700000001
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBus_LocalLayerIsBounded(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newReplica(t, mr).Namespace("user", cache.LocalOptions{Size: 2, TTL: time.Minute})
+	ctx := context.Background()
+
+	for _, key := range []string{"user:1", "user:2", "user:3"} {
+		require.NoError(t, c.Set(ctx, key, item{Name: key}, time.Minute))
+		var got item
+		_, err := c.Get(ctx, key, &got)
+		require.NoError(t, err)
+	}
+
+	// user:1 was evicted locally, so the change in Redis is seen
+	mr.Set("user:1", `{"name":"reloaded"}`)
+	var got item
+	found, err := c.Get(ctx, "user:1", &got)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "reloaded", got.Name)
+}
@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/infrastructure/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCache(t *testing.T) (*miniredis.Miniredis, cache.Cache) {
+	mr := miniredis.RunT(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return mr, cache.New(rdb)
+}
+
+type item struct {
+	Name string `json:"name"`
+}
+
+func TestCache_GetSet(t *testing.T) {
+	_, c := newCache(t)
+	ctx := context.Background()
+
+	var got item
+	found, err := c.Get(ctx, "item:1", &got)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, c.Set(ctx, "item:1", item{Name: "one"}, time.Minute))
+	found, err = c.Get(ctx, "item:1", &got)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "one", got.Name)
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	mr, c := newCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "user:1:UTC", item{}, time.Minute, "user:1"))
+	require.NoError(t, c.Set(ctx, "user:1:Asia/Jakarta", item{}, time.Hour, "user:1"))
+	require.NoError(t, c.Set(ctx, "user:2:UTC", item{}, time.Minute, "user:2"))
+
+	// The tag set outlives its longest-lived key
+	assert.Equal(t, time.Hour, mr.TTL("cache:tag:user:1"))
+
+	require.NoError(t, c.Invalidate(ctx, "user:1"))
+	assert.False(t, mr.Exists("user:1:UTC"))
+	assert.False(t, mr.Exists("user:1:Asia/Jakarta"))
+	assert.False(t, mr.Exists("cache:tag:user:1"))
+	assert.True(t, mr.Exists("user:2:UTC"))
+
+	// Unknown tags are a no-op
+	assert.NoError(t, c.Invalidate(ctx, "user:3"))
+}
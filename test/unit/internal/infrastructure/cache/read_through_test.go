@@ -0,0 +1,121 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/infrastructure/cache"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errMissing = errors.New("item not found")
+
+func TestReadThrough_CachesLoadedValue(t *testing.T) {
+	mr, c := newCache(t)
+	metrics := cache.NewMetrics()
+	rt := cache.NewReadThrough[*item](c, "item", cache.Options{TTL: time.Minute, Jitter: 0.5, Metrics: metrics})
+
+	var loads atomic.Int32
+	load := func(ctx context.Context) (*item, error) {
+		loads.Add(1)
+		return &item{Name: "one"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := rt.Get(context.Background(), "item:1", load, "items")
+		require.NoError(t, err)
+		assert.Equal(t, "one", got.Name)
+	}
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Equal(t, uint64(1), metrics.Count("item", cache.ResultMiss))
+	assert.Equal(t, uint64(2), metrics.Count("item", cache.ResultHit))
+
+	// Jitter only ever extends the TTL
+	ttl := mr.TTL("item:1")
+	assert.GreaterOrEqual(t, ttl, time.Minute)
+	assert.Less(t, ttl, 90*time.Second)
+}
+
+func TestReadThrough_CoalescesConcurrentMisses(t *testing.T) {
+	_, c := newCache(t)
+	rt := cache.NewReadThrough[*item](c, "item", cache.Options{TTL: time.Minute, Metrics: cache.NewMetrics()})
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (*item, error) {
+		loads.Add(1)
+		<-release
+		return &item{Name: "one"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := rt.Get(context.Background(), "item:1", load)
+			assert.NoError(t, err)
+			assert.Equal(t, "one", got.Name)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestReadThrough_NegativeCaching(t *testing.T) {
+	mr, c := newCache(t)
+	metrics := cache.NewMetrics()
+	rt := cache.NewReadThrough[*item](c, "item", cache.Options{
+		TTL:         time.Minute,
+		NegativeTTL: 5 * time.Second,
+		IsNotFound:  func(err error) bool { return errors.Is(err, errMissing) },
+		Metrics:     metrics,
+	})
+
+	var loads atomic.Int32
+	load := func(ctx context.Context) (*item, error) {
+		loads.Add(1)
+		return nil, errMissing
+	}
+
+	_, err := rt.Get(context.Background(), "item:404", load)
+	assert.ErrorIs(t, err, errMissing)
+	_, err = rt.Get(context.Background(), "item:404", load)
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Equal(t, 5*time.Second, mr.TTL("item:404"))
+	assert.Equal(t, uint64(1), metrics.Count("item", cache.ResultNegativeHit))
+
+	// Other errors are never cached
+	_, err = rt.Get(context.Background(), "item:500", func(ctx context.Context) (*item, error) {
+		return nil, errors.New("connection reset")
+	})
+	assert.Error(t, err)
+	assert.False(t, mr.Exists("item:500"))
+}
+
+func TestReadThrough_FallsBackWhenRedisIsDown(t *testing.T) {
+	rdb := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	metrics := cache.NewMetrics()
+	rt := cache.NewReadThrough[*item](cache.New(rdb), "item", cache.Options{
+		TTL: time.Minute, Timeout: 20 * time.Millisecond, Metrics: metrics,
+	})
+
+	got, err := rt.Get(context.Background(), "item:1", func(ctx context.Context) (*item, error) {
+		return &item{Name: "from db"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "from db", got.Name)
+	assert.Equal(t, uint64(1), metrics.Count("item", cache.ResultError))
+}
@@ -0,0 +1,67 @@
+package database_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"go-boilerplate/internal/infrastructure/database"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLSN(t *testing.T) {
+	lsn, err := database.ParseLSN("16/B374D848")
+	require.NoError(t, err)
+	assert.Equal(t, database.LSN(0x16B374D848), lsn)
+	assert.Equal(t, "16/B374D848", lsn.String())
+
+	for _, invalid := range []string{"", "16B374D848", "G/0", "0/100000000"} {
+		_, err := database.ParseLSN(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSession_RecordsWritesInCTEs(t *testing.T) {
+	master, _, db := newDatabase(t)
+	ctx, session := database.WithSession(context.Background(), 0)
+
+	master.ExpectQuery(regexp.QuoteMeta("WITH recent")).WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	rows, err := db.Writer(ctx).Query(ctx, "WITH recent AS (SELECT id, updated_at FROM jobs) SELECT id FROM recent")
+	require.NoError(t, err)
+	rows.Close()
+	assert.False(t, session.Wrote())
+
+	master.ExpectQuery(regexp.QuoteMeta("WITH moved")).WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	rows, err = db.Writer(ctx).Query(ctx, "WITH moved AS (DELETE FROM jobs RETURNING id) SELECT id FROM moved")
+	require.NoError(t, err)
+	rows.Close()
+	assert.True(t, session.Wrote())
+}
+
+func TestSession_RecordsWrites(t *testing.T) {
+	master, _, db := newDatabase(t)
+
+	ctx, session := database.WithSession(context.Background(), 0)
+	db.Reader(ctx)
+	assert.False(t, session.Wrote())
+
+	// Reads from the master are not writes
+	master.ExpectQuery(regexp.QuoteMeta("SELECT id FROM jobs")).WithArgs("job-1").WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow("job-1"))
+	var id string
+	require.NoError(t, db.Writer(ctx).QueryRow(ctx, "SELECT id FROM jobs WHERE id = $1", "job-1").Scan(&id))
+	assert.False(t, session.Wrote())
+
+	master.ExpectExec(regexp.QuoteMeta("UPDATE jobs")).WithArgs("processing", "job-1").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	_, err := db.Writer(ctx).Exec(ctx, "UPDATE jobs SET status = $1 WHERE id = $2", "processing", "job-1")
+	require.NoError(t, err)
+	assert.True(t, session.Wrote())
+
+	master.ExpectQuery(regexp.QuoteMeta("pg_current_wal_lsn()")).
+		WillReturnRows(pgxmock.NewRows([]string{"lsn"}).AddRow("0/3000060"))
+	lsn, err := db.CurrentLSN(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "0/3000060", lsn.String())
+}
@@ -0,0 +1,43 @@
+package database_test
+
+import (
+	"testing"
+
+	"go-boilerplate/internal/infrastructure/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			sql:  "SELECT * FROM users WHERE id IN ($1, $2, $3) AND age >= 18 LIMIT 10",
+			want: "select * from users where id in(...) and age >= ? limit ?",
+		},
+		{
+			sql:  "INSERT INTO t (a, b) VALUES ($1, 'x'), ($2, 'it''s')",
+			want: "insert into t(a, b) values(...)",
+		},
+		{
+			sql:  "-- find user\nSELECT \"Email\"  FROM users /* by id */ WHERE id = $1::uuid",
+			want: `select "Email" from users where id = ?::uuid`,
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, database.NormalizeQuery(tt.sql), tt.sql)
+	}
+}
+
+func TestFingerprint_SameShape(t *testing.T) {
+	a := database.Fingerprint("SELECT * FROM users WHERE email = 'a@example.com'")
+	b := database.Fingerprint("select *\n  from users\n where email = $1")
+	c := database.Fingerprint("SELECT * FROM users WHERE id = $1")
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 16)
+}
@@ -0,0 +1,13 @@
+package database_test
+
+import (
+	"os"
+	"testing"
+
+	"go-boilerplate/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger(nil)
+	os.Exit(m.Run())
+}
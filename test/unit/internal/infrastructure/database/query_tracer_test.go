@@ -0,0 +1,73 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.InfoLevel)
+	previous := logger.Log
+	logger.Log = zap.New(core)
+	t.Cleanup(func() { logger.Log = previous })
+	return logs
+}
+
+func trace(tracer *database.QueryTracer, sql string, delay time.Duration, err error) {
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{1}})
+	time.Sleep(delay)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: err})
+}
+
+func TestQueryTracer_SamplesFastQueries(t *testing.T) {
+	logs := observeLogs(t)
+
+	trace(database.NewQueryTracer(config.QueryLogConfig{SamplePercent: 0, SlowThreshold: time.Hour}), "SELECT 1", 0, nil)
+	assert.Zero(t, logs.Len())
+
+	trace(database.NewQueryTracer(config.QueryLogConfig{SamplePercent: 100, SlowThreshold: time.Hour}), "SELECT 1", 0, nil)
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.InfoLevel, entry.Level)
+	assert.NotContains(t, entry.ContextMap(), "args")
+}
+
+func TestQueryTracer_AlwaysLogsErrors(t *testing.T) {
+	logs := observeLogs(t)
+	tracer := database.NewQueryTracer(config.QueryLogConfig{SamplePercent: 0, SlowThreshold: time.Hour, LogArgs: true})
+
+	trace(tracer, "SELECT 1", 0, errors.New("boom"))
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.ErrorLevel, entry.Level)
+	assert.Equal(t, "boom", entry.ContextMap()["error"])
+	assert.Contains(t, entry.ContextMap(), "args")
+}
+
+func TestQueryTracer_LogsSlowQueriesWithFingerprint(t *testing.T) {
+	logs := observeLogs(t)
+	tracer := database.NewQueryTracer(config.QueryLogConfig{SamplePercent: 0, SlowThreshold: time.Millisecond})
+
+	sql := "SELECT * FROM users WHERE id = $1"
+	trace(tracer, sql, 2*time.Millisecond, nil)
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.WarnLevel, entry.Level)
+	assert.Equal(t, database.Fingerprint(sql), entry.ContextMap()["fingerprint"])
+	assert.Equal(t, "select * from users where id = ?", entry.ContextMap()["sql_normalized"])
+}
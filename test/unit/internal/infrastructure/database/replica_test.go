@@ -0,0 +1,146 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/infrastructure/database"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var lagQuery = regexp.QuoteMeta("pg_last_xact_replay_timestamp()")
+
+func newPools(t *testing.T, n int) []pgxmock.PgxPoolIface {
+	pools := make([]pgxmock.PgxPoolIface, n)
+	for i := range pools {
+		pool, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		pools[i] = pool
+	}
+	t.Cleanup(func() {
+		for _, pool := range pools {
+			assert.NoError(t, pool.ExpectationsWereMet())
+		}
+	})
+	return pools
+}
+
+func newReplicaSet(master pgxmock.PgxPoolIface, replicas []pgxmock.PgxPoolIface, balancer string) *database.ReplicaSet {
+	members := make([]database.Replica, len(replicas))
+	for i, pool := range replicas {
+		members[i] = database.Replica{Name: string(rune('a' + i)), Pool: pool}
+	}
+	return database.NewReplicaSet(master, members, database.ReplicaOptions{
+		Balancer:     balancer,
+		MaxLag:       10 * time.Second,
+		CheckTimeout: time.Second,
+	})
+}
+
+func expectLag(pool pgxmock.PgxPoolIface, seconds float64, replayed string) {
+	pool.ExpectQuery(lagQuery).WillReturnRows(pgxmock.NewRows([]string{"lag", "replayed"}).AddRow(seconds, &replayed))
+}
+
+func TestReplicaSet_SpreadsReads(t *testing.T) {
+	for _, balancer := range []string{database.BalancerRoundRobin, database.BalancerLeastConnections} {
+		t.Run(balancer, func(t *testing.T) {
+			pools := newPools(t, 3)
+			set := newReplicaSet(pools[0], pools[1:], balancer)
+
+			for _, replica := range pools[1:] {
+				replica.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+				replica.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+			}
+			for i := 0; i < 4; i++ {
+				_, err := set.Exec(context.Background(), "SELECT 1")
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReplicaSet_EjectsUnhealthyReplicas(t *testing.T) {
+	pools := newPools(t, 3)
+	master, lagging, dead := pools[0], pools[1], pools[2]
+	set := newReplicaSet(master, pools[1:], database.BalancerRoundRobin)
+	ctx := context.Background()
+
+	expectLag(lagging, 30, "0/100")
+	dead.ExpectQuery(lagQuery).WillReturnError(errors.New("connection refused"))
+	set.Check(ctx)
+
+	statuses := set.Status()
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Healthy)
+	assert.Equal(t, 30*time.Second, statuses[0].Lag)
+	assert.ErrorContains(t, statuses[0].Err, "replication lag")
+	assert.False(t, statuses[1].Healthy)
+	assert.ErrorContains(t, statuses[1].Err, "connection refused")
+
+	// Reads fall back to the master
+	master.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	_, err := set.Exec(ctx, "SELECT 1")
+	require.NoError(t, err)
+
+	// The replica that caught up takes the reads again
+	expectLag(lagging, 0.5, "0/200")
+	dead.ExpectQuery(lagQuery).WillReturnError(errors.New("connection refused"))
+	set.Check(ctx)
+	assert.True(t, set.Status()[0].Healthy)
+
+	lagging.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	lagging.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	for i := 0; i < 2; i++ {
+		_, err := set.Exec(ctx, "SELECT 1")
+		require.NoError(t, err)
+	}
+}
+
+func TestReplicaSet_PingFallsBackToMaster(t *testing.T) {
+	pools := newPools(t, 2)
+	master, replica := pools[0], pools[1]
+	set := newReplicaSet(master, pools[1:], database.BalancerRoundRobin)
+
+	replica.ExpectQuery(lagQuery).WillReturnError(errors.New("connection refused"))
+	set.Check(context.Background())
+
+	master.ExpectPing()
+	assert.NoError(t, set.Ping(context.Background()))
+}
+
+func TestReplicaSet_WaitsForSessionWrites(t *testing.T) {
+	pools := newPools(t, 3)
+	master, behind, caughtUp := pools[0], pools[1], pools[2]
+	set := newReplicaSet(master, pools[1:], database.BalancerRoundRobin)
+
+	expectLag(behind, 0, "0/16B3740")
+	expectLag(caughtUp, 0, "1/0")
+	set.Check(context.Background())
+
+	// Only the replica that replayed the write serves the session
+	ctx, _ := database.WithSession(context.Background(), mustParseLSN(t, "0/16B3748"))
+	caughtUp.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	caughtUp.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	for i := 0; i < 2; i++ {
+		_, err := set.Exec(ctx, "SELECT 1")
+		require.NoError(t, err)
+	}
+
+	// The master serves writes no replica replayed yet
+	ctx, _ = database.WithSession(context.Background(), mustParseLSN(t, "1/1"))
+	master.ExpectExec("SELECT 1").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	_, err := set.Exec(ctx, "SELECT 1")
+	require.NoError(t, err)
+}
+
+func mustParseLSN(t *testing.T, s string) database.LSN {
+	lsn, err := database.ParseLSN(s)
+	require.NoError(t, err)
+	return lsn
+}
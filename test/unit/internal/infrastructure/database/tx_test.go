@@ -0,0 +1,184 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"go-boilerplate/internal/infrastructure/database"
+	appErrors "go-boilerplate/pkg/errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDatabase(t *testing.T) (master, slave pgxmock.PgxPoolIface, db *database.Database) {
+	master, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	slave, err = pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, master.ExpectationsWereMet())
+		assert.NoError(t, slave.ExpectationsWereMet())
+	})
+	return master, slave, &database.Database{Master: master, Slave: slave}
+}
+
+func exec(ctx context.Context, db *database.Database, sql string) error {
+	_, err := db.Writer(ctx).Exec(ctx, sql)
+	return err
+}
+
+func TestWithTx_CommitsAndRollsBack(t *testing.T) {
+	master, _, db := newDatabase(t)
+	ctx := context.Background()
+
+	master.ExpectBegin()
+	master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	master.ExpectCommit()
+	err := db.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		return exec(ctx, db, "UPDATE a")
+	})
+	require.NoError(t, err)
+
+	failure := errors.New("validation failed")
+	master.ExpectBegin()
+	master.ExpectRollback()
+	err = db.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+}
+
+func TestWithTx_NestedCallsUseSavepoints(t *testing.T) {
+	master, _, db := newDatabase(t)
+
+	master.ExpectBegin()
+	master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	// The failing nested call only rolls back to its savepoint
+	master.ExpectBegin()
+	master.ExpectExec(regexp.QuoteMeta("UPDATE b")).WillReturnError(errors.New("unique violation"))
+	master.ExpectRollback()
+	master.ExpectBegin()
+	master.ExpectExec(regexp.QuoteMeta("UPDATE c")).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	master.ExpectCommit()
+	master.ExpectCommit()
+
+	err := db.WithTx(context.Background(), database.TxOptions{}, func(ctx context.Context) error {
+		require.NoError(t, exec(ctx, db, "UPDATE a"))
+		nestedErr := db.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+			return exec(ctx, db, "UPDATE b")
+		})
+		assert.EqualError(t, nestedErr, "unique violation")
+		return db.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error {
+			return exec(ctx, db, "UPDATE c")
+		})
+	})
+	require.NoError(t, err)
+}
+
+func TestWithTx_RetriesSerializationFailures(t *testing.T) {
+	master, _, db := newDatabase(t)
+	opts := database.TxOptions{IsoLevel: pgx.Serializable, MaxRetries: 2}
+	serializable := pgx.TxOptions{IsoLevel: pgx.Serializable}
+	conflict := &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+
+	master.ExpectBeginTx(serializable)
+	master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	master.ExpectCommit().WillReturnError(conflict)
+	master.ExpectBeginTx(serializable)
+	master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnError(conflict)
+	master.ExpectRollback()
+	master.ExpectBeginTx(serializable)
+	master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	master.ExpectCommit()
+
+	attempts := 0
+	err := db.WithTx(context.Background(), opts, func(ctx context.Context) error {
+		attempts++
+		return exec(ctx, db, "UPDATE a")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestWithTx_RetriesWrappedSerializationFailures(t *testing.T) {
+	master, _, db := newDatabase(t)
+	opts := database.TxOptions{IsoLevel: pgx.Serializable, MaxRetries: 1}
+	serializable := pgx.TxOptions{IsoLevel: pgx.Serializable}
+
+	master.ExpectBeginTx(serializable)
+	master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnError(&pgconn.PgError{Code: "40001"})
+	master.ExpectRollback()
+	master.ExpectBeginTx(serializable)
+	master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	master.ExpectCommit()
+
+	// Usecases wrap repository errors before returning them from fn
+	attempts := 0
+	err := db.WithTx(context.Background(), opts, func(ctx context.Context) error {
+		attempts++
+		if err := exec(ctx, db, "UPDATE a"); err != nil {
+			return appErrors.Wrap(err, 500, "Failed to update")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestWithTx_GivesUpAfterMaxRetries(t *testing.T) {
+	master, _, db := newDatabase(t)
+	deadlock := &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+
+	for i := 0; i < 2; i++ {
+		master.ExpectBegin()
+		master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnError(deadlock)
+		master.ExpectRollback()
+	}
+
+	err := db.WithTx(context.Background(), database.TxOptions{MaxRetries: 1}, func(ctx context.Context) error {
+		return exec(ctx, db, "UPDATE a")
+	})
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, "40P01", pgErr.Code)
+}
+
+func TestWithTx_DoesNotRetryOtherErrors(t *testing.T) {
+	master, _, db := newDatabase(t)
+
+	master.ExpectBegin()
+	master.ExpectExec(regexp.QuoteMeta("UPDATE a")).WillReturnError(&pgconn.PgError{Code: "23505"})
+	master.ExpectRollback()
+
+	err := db.WithTx(context.Background(), database.TxOptions{MaxRetries: 3}, func(ctx context.Context) error {
+		return exec(ctx, db, "UPDATE a")
+	})
+	assert.Error(t, err)
+}
+
+func TestWithTx_ReadOnlyRunsOnSlave(t *testing.T) {
+	_, slave, db := newDatabase(t)
+
+	slave.ExpectBeginTx(pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	slave.ExpectQuery(regexp.QuoteMeta("SELECT 1")).WillReturnRows(pgxmock.NewRows([]string{"n"}).AddRow(1))
+	slave.ExpectCommit()
+
+	err := db.WithTx(context.Background(), database.TxOptions{ReadOnly: true}, func(ctx context.Context) error {
+		var n int
+		if err := db.Reader(ctx).QueryRow(ctx, "SELECT 1").Scan(&n); err != nil {
+			return err
+		}
+
+		// Read-write work cannot join a read-only transaction
+		err := db.WithTx(ctx, database.TxOptions{}, func(ctx context.Context) error { return nil })
+		assert.ErrorContains(t, err, "read-only")
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,179 @@
+package migration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"go-boilerplate/internal/infrastructure/migration"
+	"go-boilerplate/migrations"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var files = fstest.MapFS{
+	"000001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id int)")},
+	"000001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+	"000002_add_name.up.sql":       {Data: []byte("ALTER TABLE users ADD name text")},
+	"000002_add_name.down.sql":     {Data: []byte("ALTER TABLE users DROP name")},
+	"README.md":                    {Data: []byte("not a migration")},
+}
+
+func newMigrator(t *testing.T) (pgxmock.PgxConnIface, *migration.Migrator) {
+	conn, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, conn.ExpectationsWereMet()) })
+
+	loaded, err := migration.Load(files)
+	require.NoError(t, err)
+	return conn, migration.New(conn, loaded)
+}
+
+func expectLocked(conn pgxmock.PgxConnIface, version int64, dirty bool) {
+	conn.ExpectExec(regexp.QuoteMeta("pg_advisory_lock")).WithArgs(pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	conn.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	query := conn.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty FROM schema_migrations"))
+	if version == 0 {
+		query.WillReturnError(pgx.ErrNoRows)
+	} else {
+		query.WillReturnRows(pgxmock.NewRows([]string{"version", "dirty"}).AddRow(version, dirty))
+	}
+}
+
+func expectUnlock(conn pgxmock.PgxConnIface) {
+	conn.ExpectExec(regexp.QuoteMeta("pg_advisory_unlock")).WithArgs(pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+}
+
+func expectVersion(conn pgxmock.PgxConnIface, version int64, dirty bool) {
+	conn.ExpectExec("INSERT INTO schema_migrations").WithArgs(version, dirty).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func TestLoad(t *testing.T) {
+	loaded, err := migration.Load(files)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, uint64(1), loaded[0].Version)
+	assert.Equal(t, "create_users", loaded[0].Name)
+	assert.Equal(t, "DROP TABLE users", loaded[0].Down)
+	assert.Equal(t, "add_name", loaded[1].Name)
+
+	_, err = migration.Load(fstest.MapFS{"000001_a.down.sql": {Data: []byte("x")}})
+	assert.ErrorContains(t, err, "no up file")
+}
+
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	loaded, err := migration.Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	for i, m := range loaded {
+		assert.Equal(t, uint64(i+1), m.Version, "versions are sequential")
+		assert.NotEmpty(t, m.Down, "%d_%s has a down file", m.Version, m.Name)
+	}
+}
+
+func TestUp_AppliesPendingMigrations(t *testing.T) {
+	conn, migrator := newMigrator(t)
+
+	expectLocked(conn, 1, false)
+	expectVersion(conn, 2, true)
+	conn.ExpectExec(regexp.QuoteMeta("ALTER TABLE users ADD name text")).WillReturnResult(pgxmock.NewResult("ALTER TABLE", 0))
+	expectVersion(conn, 2, false)
+	expectUnlock(conn)
+
+	applied, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "add_name", applied[0].Name)
+}
+
+func TestUp_LeavesFailedMigrationDirty(t *testing.T) {
+	conn, migrator := newMigrator(t)
+
+	expectLocked(conn, 0, false)
+	expectVersion(conn, 1, true)
+	conn.ExpectExec(regexp.QuoteMeta("CREATE TABLE users")).WillReturnError(assert.AnError)
+	expectUnlock(conn)
+
+	applied, err := migrator.Up(context.Background())
+	assert.ErrorContains(t, err, "migration 1_create_users failed")
+	assert.Empty(t, applied)
+}
+
+func TestUp_RefusesDirtyDatabase(t *testing.T) {
+	conn, migrator := newMigrator(t)
+
+	expectLocked(conn, 1, true)
+	expectUnlock(conn)
+
+	_, err := migrator.Up(context.Background())
+	assert.ErrorContains(t, err, "dirty at version 1")
+}
+
+func TestDown_RevertsLastMigrations(t *testing.T) {
+	conn, migrator := newMigrator(t)
+
+	expectLocked(conn, 2, false)
+	expectVersion(conn, 2, true)
+	conn.ExpectExec(regexp.QuoteMeta("ALTER TABLE users DROP name")).WillReturnResult(pgxmock.NewResult("ALTER TABLE", 0))
+	expectVersion(conn, 1, false)
+	expectVersion(conn, 1, true)
+	conn.ExpectExec(regexp.QuoteMeta("DROP TABLE users")).WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	conn.ExpectExec("DELETE FROM schema_migrations").WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	expectUnlock(conn)
+
+	// Asking for more than applied stops at the first migration
+	reverted, err := migrator.Down(context.Background(), 5)
+	require.NoError(t, err)
+	require.Len(t, reverted, 2)
+	assert.Equal(t, uint64(2), reverted[0].Version)
+}
+
+func TestStatus(t *testing.T) {
+	conn, migrator := newMigrator(t)
+
+	conn.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	conn.ExpectQuery(regexp.QuoteMeta("SELECT version, dirty FROM schema_migrations")).
+		WillReturnRows(pgxmock.NewRows([]string{"version", "dirty"}).AddRow(int64(1), true))
+
+	status, err := migrator.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), status.Version)
+	assert.True(t, status.Dirty)
+	require.Len(t, status.Pending, 1)
+	assert.Equal(t, uint64(2), status.Pending[0].Version)
+}
+
+func TestForce(t *testing.T) {
+	conn, migrator := newMigrator(t)
+
+	assert.ErrorContains(t, migrator.Force(context.Background(), 7), "no migration file for version 7")
+
+	conn.ExpectExec(regexp.QuoteMeta("pg_advisory_lock")).WithArgs(pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	conn.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	expectVersion(conn, 1, false)
+	expectUnlock(conn)
+	require.NoError(t, migrator.Force(context.Background(), 1))
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "000004_existing.up.sql"), []byte("SELECT 1"), 0o644))
+
+	up, down, err := migration.Create(dir, "add orders")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "000005_add_orders.up.sql"), up)
+	assert.Equal(t, filepath.Join(dir, "000005_add_orders.down.sql"), down)
+	assert.FileExists(t, up)
+	assert.FileExists(t, down)
+
+	// The new empty pair is a valid migration
+	up, _, err = migration.Create(dir, "next")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "000006_next.up.sql"), up)
+}
@@ -0,0 +1,105 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/infrastructure/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyLimiter fails while down is set and counts the calls it receives.
+type flakyLimiter struct {
+	calls atomic.Int32
+	down  atomic.Bool
+}
+
+func (f *flakyLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	f.calls.Add(1)
+	if f.down.Load() {
+		return ratelimit.Result{}, errors.New("connection refused")
+	}
+	return ratelimit.Result{Allowed: true, Limit: limit.Rate, Remaining: limit.Rate - 1}, nil
+}
+
+var perMinute = ratelimit.Limit{Rate: 4, Period: time.Minute}
+
+func TestWithFallback_Modes(t *testing.T) {
+	primary := &flakyLimiter{}
+	primary.down.Store(true)
+	ctx := context.Background()
+
+	open := ratelimit.WithFallback(primary, ratelimit.FallbackOptions{Mode: ratelimit.FailureModeOpen, Threshold: 5})
+	result, err := open.Allow(ctx, "ip:1", perMinute)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Zero(t, result.Limit)
+
+	closed := ratelimit.WithFallback(primary, ratelimit.FallbackOptions{Mode: ratelimit.FailureModeClosed, Threshold: 5})
+	_, err = closed.Allow(ctx, "ip:1", perMinute)
+	assert.ErrorIs(t, err, ratelimit.ErrUnavailable)
+
+	// Two replicas share the limit of 4, so each allows 2
+	local := ratelimit.WithFallback(primary, ratelimit.FallbackOptions{Mode: ratelimit.FailureModeLocal, Replicas: 2, Threshold: 5})
+	for i := 0; i < 2; i++ {
+		result, err = local.Allow(ctx, "ip:1", perMinute)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Limit)
+	}
+	result, err = local.Allow(ctx, "ip:1", perMinute)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 30*time.Second, result.RetryAfter.Round(time.Second))
+
+	result, err = local.Allow(ctx, "ip:2", perMinute)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestWithFallback_CircuitBreaker(t *testing.T) {
+	primary := &flakyLimiter{}
+	primary.down.Store(true)
+	limiter := ratelimit.WithFallback(primary, ratelimit.FallbackOptions{
+		Mode:      ratelimit.FailureModeOpen,
+		Threshold: 3,
+		Cooldown:  50 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		_, err := limiter.Allow(ctx, "ip:1", perMinute)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), primary.calls.Load(), "an open circuit skips the primary limiter")
+
+	// After the cooldown a failed probe opens the circuit again
+	time.Sleep(60 * time.Millisecond)
+	limiter.Allow(ctx, "ip:1", perMinute)
+	limiter.Allow(ctx, "ip:1", perMinute)
+	assert.Equal(t, int32(4), primary.calls.Load())
+
+	// A successful probe closes it
+	primary.down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "ip:1", perMinute)
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Limit)
+	}
+	assert.Equal(t, int32(7), primary.calls.Load())
+}
+
+func TestWithFallback_PassesThroughWhenHealthy(t *testing.T) {
+	primary := &flakyLimiter{}
+	limiter := ratelimit.WithFallback(primary, ratelimit.FallbackOptions{Mode: ratelimit.FailureModeClosed, Threshold: 1})
+
+	result, err := limiter.Allow(context.Background(), "ip:1", perMinute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Remaining)
+}
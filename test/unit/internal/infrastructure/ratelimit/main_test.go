@@ -0,0 +1,15 @@
+package ratelimit_test
+
+import (
+	"os"
+	"testing"
+
+	"go-boilerplate/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	// Console-only logger, the fallback limiter logs failures
+	logger.InitLogger(nil)
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,83 @@
+package ratelimit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/infrastructure/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fallback = ratelimit.Policy{Limit: 60, WindowSeconds: 60}
+
+func writePolicies(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPolicies_Match(t *testing.T) {
+	policies, err := ratelimit.LoadPolicies(writePolicies(t, `{"policies": [
+		{"name": "login", "routes": ["POST /api/v1/auth/login"], "identity": "ip", "limit": 5, "window_seconds": 60},
+		{"name": "admin", "routes": ["/api/v1/admin/*"], "identity": "user", "limit": 30, "window_seconds": 60}
+	]}`), fallback)
+	require.NoError(t, err)
+
+	assert.Equal(t, "login", policies.Match("POST", "/api/v1/auth/login").Name)
+	assert.Equal(t, ratelimit.DefaultPolicyName, policies.Match("GET", "/api/v1/auth/login").Name)
+	assert.Equal(t, "admin", policies.Match("PUT", "/api/v1/admin/users/:id/role").Name)
+	assert.Equal(t, "admin", policies.Match("GET", "/api/v1/admin").Name)
+	assert.Equal(t, ratelimit.DefaultPolicyName, policies.Match("GET", "/api/v1/administrators").Name)
+	assert.Equal(t, ratelimit.IdentityIP, policies.Match("GET", "/health").Identity)
+}
+
+func TestLoadPolicies_LimitFor(t *testing.T) {
+	policies, err := ratelimit.LoadPolicies(writePolicies(t, `{
+		"policies": [{"name": "api", "routes": ["/*"], "identity": "tenant", "limit": 10, "window_seconds": 60,
+			"plans": {"pro": {"limit": 100, "burst": 20}}}],
+		"plan_members": {"pro": ["tenant:t1"]},
+		"exempt": ["ip:10.0.0.5"]
+	}`), fallback)
+	require.NoError(t, err)
+	policy := policies.Match("GET", "/anything")
+
+	limit, plan := policies.LimitFor(policy, "user:u1", "tenant:t1", "ip:1.2.3.4")
+	assert.Equal(t, "pro", plan)
+	assert.Equal(t, ratelimit.Limit{Rate: 100, Period: time.Minute, Burst: 20}, limit)
+
+	limit, plan = policies.LimitFor(policy, "tenant:t2")
+	assert.Empty(t, plan)
+	assert.Equal(t, ratelimit.Limit{Rate: 10, Period: time.Minute}, limit)
+
+	assert.True(t, policies.IsExempt("user:u1", "ip:10.0.0.5"))
+	assert.False(t, policies.IsExempt("user:u1", "ip:1.2.3.4"))
+}
+
+func TestLoadPolicies_WithoutFile(t *testing.T) {
+	policies, err := ratelimit.LoadPolicies("", fallback)
+	require.NoError(t, err)
+
+	policy := policies.Match("GET", "/api/v1/products")
+	assert.Equal(t, ratelimit.DefaultPolicyName, policy.Name)
+	assert.Equal(t, 60, policy.Limit)
+}
+
+func TestLoadPolicies_Invalid(t *testing.T) {
+	_, err := ratelimit.LoadPolicies(writePolicies(t, `{"policies": [{"name": "x", "identity": "cookie", "limit": 1, "window_seconds": 1}]}`), fallback)
+	assert.ErrorContains(t, err, `unknown identity "cookie"`)
+
+	_, err = ratelimit.LoadPolicies(writePolicies(t, `{"policies": [{"name": "x", "identity": "ip"}]}`), fallback)
+	assert.ErrorContains(t, err, "positive limit")
+
+	_, err = ratelimit.LoadPolicies(filepath.Join(t.TempDir(), "missing.json"), fallback)
+	assert.Error(t, err)
+}
+
+func TestLoadPolicies_ShippedExample(t *testing.T) {
+	_, err := ratelimit.LoadPolicies("../../../../../configs/rate_limit_policies.json", fallback)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,110 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/infrastructure/ratelimit"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, *goredis.Client) {
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return mr, rdb
+}
+
+// advance moves the Redis clock the scripts read, and expires keys accordingly.
+func advance(mr *miniredis.Miniredis, d time.Duration) {
+	mr.SetTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(d))
+	mr.FastForward(d)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	mr, rdb := newRedis(t)
+	limiter := ratelimit.NewSlidingWindow(rdb)
+	ctx := context.Background()
+	limit := ratelimit.Limit{Rate: 3, Period: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "ip:1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2-i, result.Remaining)
+		assert.Equal(t, time.Minute, result.ResetAfter)
+		assert.Zero(t, result.RetryAfter)
+	}
+
+	advance(mr, 40*time.Second)
+	result, err := limiter.Allow(ctx, "ip:1", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "the window slides, the earlier requests still count")
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+
+	// Other keys have their own quota
+	result, err = limiter.Allow(ctx, "ip:2", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	advance(mr, 61*time.Second)
+	result, err = limiter.Allow(ctx, "ip:1", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestSlidingWindow_KeyExpires(t *testing.T) {
+	mr, rdb := newRedis(t)
+	limiter := ratelimit.NewSlidingWindow(rdb)
+
+	_, err := limiter.Allow(context.Background(), "ip:1", ratelimit.Limit{Rate: 3, Period: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, mr.TTL("rate_limit:sw:ip:1"))
+}
+
+func TestTokenBucket(t *testing.T) {
+	mr, rdb := newRedis(t)
+	limiter := ratelimit.NewTokenBucket(rdb)
+	ctx := context.Background()
+	// One token every 10s, bursts of 3
+	limit := ratelimit.Limit{Rate: 6, Period: time.Minute, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "ip:1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, "ip:1", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 10*time.Second, result.RetryAfter)
+	assert.Equal(t, 30*time.Second, result.ResetAfter)
+	assert.Equal(t, 30*time.Second, mr.TTL("rate_limit:tb:ip:1"))
+
+	advance(mr, 10*time.Second)
+	result, err = limiter.Allow(ctx, "ip:1", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestTokenBucket_BurstDefaultsToRate(t *testing.T) {
+	_, rdb := newRedis(t)
+	limiter := ratelimit.NewTokenBucket(rdb)
+
+	result, err := limiter.Allow(context.Background(), "ip:1", ratelimit.Limit{Rate: 5, Period: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Limit)
+	assert.Equal(t, 4, result.Remaining)
+}
@@ -0,0 +1,151 @@
+package seed_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/infrastructure/seed"
+	"go-boilerplate/migrations"
+	"go-boilerplate/pkg/tenant"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var recordQuery = regexp.QuoteMeta("INSERT INTO seed_history")
+
+func newDatabase(t *testing.T) (pgxmock.PgxPoolIface, *database.Database) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, mock.ExpectationsWereMet()) })
+	return mock, &database.Database{Master: mock, Slave: mock}
+}
+
+func TestLoadSQL(t *testing.T) {
+	_, db := newDatabase(t)
+	fsys := fstest.MapFS{
+		"common/users.sql":    {Data: []byte("INSERT INTO users")},
+		"dev/admin.sql":       {Data: []byte("INSERT INTO admins")},
+		"dev/README.md":       {Data: []byte("not a seeder")},
+		"staging/orders.sql":  {Data: []byte("INSERT INTO orders")},
+		"common/0_orgs.sql":   {Data: []byte("INSERT INTO organizations")},
+		"unrelated/other.sql": {Data: []byte("SELECT 1")},
+	}
+
+	seeds, err := seed.LoadSQL(fsys, seed.EnvironmentDev, db)
+	require.NoError(t, err)
+	var names []string
+	for _, s := range seeds {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"common/0_orgs.sql", "common/users.sql", "dev/admin.sql"}, names)
+
+	// Environments without a directory only get the common seeds
+	seeds, err = seed.LoadSQL(fsys, seed.EnvironmentTest, db)
+	require.NoError(t, err)
+	assert.Len(t, seeds, 2)
+}
+
+func TestLoadSQL_EmbeddedSeeders(t *testing.T) {
+	_, db := newDatabase(t)
+	for _, env := range []string{seed.EnvironmentDev, seed.EnvironmentStaging, seed.EnvironmentTest} {
+		seeds, err := seed.LoadSQL(migrations.SeederFS, env, db)
+		require.NoError(t, err)
+		assert.NotEmpty(t, seeds, env)
+	}
+}
+
+func TestRunner_RunsEachSeedOnce(t *testing.T) {
+	mock, db := newDatabase(t)
+	var ran []string
+	seeds := []seed.Seed{
+		{Name: "applied.sql", Run: func(ctx context.Context) error { ran = append(ran, "applied.sql"); return nil }},
+		{Name: "new.sql", Run: func(ctx context.Context) error {
+			ran = append(ran, "new.sql")
+			_, err := db.Writer(ctx).Exec(ctx, "INSERT INTO users")
+			return err
+		}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(recordQuery).WithArgs("dev", "applied.sql").WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(recordQuery).WithArgs("dev", "new.sql").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(pgxmock.NewResult("INSERT", 4))
+	mock.ExpectCommit()
+
+	applied, err := seed.NewRunner(db).Run(context.Background(), seed.EnvironmentDev, seeds)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new.sql"}, applied)
+	assert.Equal(t, []string{"new.sql"}, ran)
+}
+
+func TestRunner_StopsAtFailingSeed(t *testing.T) {
+	mock, db := newDatabase(t)
+	seeds := []seed.Seed{
+		{Name: "broken.sql", Run: func(ctx context.Context) error { return errors.New("syntax error") }},
+		{Name: "later.sql", Run: func(ctx context.Context) error { t.Fatal("later seeds must not run"); return nil }},
+	}
+
+	// The history entry is rolled back with the seed, so it runs again next time
+	mock.ExpectBegin()
+	mock.ExpectExec(recordQuery).WithArgs("test", "broken.sql").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectRollback()
+
+	applied, err := seed.NewRunner(db).Run(context.Background(), seed.EnvironmentTest, seeds)
+	assert.ErrorContains(t, err, "seed broken.sql failed: syntax error")
+	assert.Empty(t, applied)
+}
+
+func TestRunner_RejectsUnknownEnvironment(t *testing.T) {
+	_, db := newDatabase(t)
+	_, err := seed.NewRunner(db).Run(context.Background(), "production", nil)
+	assert.ErrorContains(t, err, "unknown seed environment")
+}
+
+type fakeCreator struct {
+	batches [][]entity.User
+	tenants []string
+}
+
+func (f *fakeCreator) BulkCreate(ctx context.Context, users []entity.User) (map[string]bool, error) {
+	id, _ := tenant.FromContext(ctx)
+	f.batches = append(f.batches, users)
+	f.tenants = append(f.tenants, id)
+	created := make(map[string]bool, len(users))
+	for _, user := range users {
+		created[user.Email] = true
+	}
+	return created, nil
+}
+
+func TestFakeUsers(t *testing.T) {
+	creator := &fakeCreator{}
+	users := seed.FakeUsers("fake_users", creator, seed.FakeUserOptions{Count: 2500, Password: "secret", OrganizationID: "org-1"})
+
+	require.NoError(t, users.Run(context.Background()))
+	require.Len(t, creator.batches, 3)
+	assert.Len(t, creator.batches[2], 500)
+	assert.Equal(t, []string{"org-1", "org-1", "org-1"}, creator.tenants)
+
+	emails := make(map[string]bool)
+	for _, batch := range creator.batches {
+		for _, user := range batch {
+			assert.NotEmpty(t, user.FirstName)
+			assert.Contains(t, user.Email, "@example.com")
+			emails[user.Email] = true
+		}
+	}
+	assert.Len(t, emails, 2500, "emails are unique")
+
+	first := creator.batches[0][0]
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(first.Password), []byte("secret")))
+}
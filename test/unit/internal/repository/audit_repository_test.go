@@ -0,0 +1,83 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/pkg/tenant"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const sqlInsertAudit = `INSERT INTO audit_events (actor_id, action, resource_type, resource_id, changes, ip, user_agent, trace_id, tenant_id)
+              VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::uuid) RETURNING id, created_at`
+
+func TestAuditRepository_Create_JoinsTransaction(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &database.Database{Master: mock, Slave: mock}
+	userRepo := repository.NewUserRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET role = $1`)).
+		WithArgs("admin", "user-1", "org-1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertAudit)).
+		WithArgs("admin-1", entity.AuditActionUserRoleChange, entity.AuditResourceUser, "user-1",
+			pgxmock.AnyArg(), "", "", "", "org-1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow("evt-1", time.Now()))
+	mock.ExpectCommit()
+
+	err = db.WithTx(tenant.WithID(context.Background(), "org-1"), database.TxOptions{}, func(ctx context.Context) error {
+		if err := userRepo.UpdateRole(ctx, "user-1", "admin"); err != nil {
+			return err
+		}
+		return auditRepo.Create(ctx, &entity.AuditEvent{
+			ActorID:      "admin-1",
+			Action:       entity.AuditActionUserRoleChange,
+			ResourceType: entity.AuditResourceUser,
+			ResourceID:   "user-1",
+			TenantID:     "org-1",
+		})
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_Create_RollsBackMutation(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &database.Database{Master: mock, Slave: mock}
+	userRepo := repository.NewUserRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET role = $1`)).
+		WithArgs("admin", "user-1", "org-1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery(regexp.QuoteMeta(sqlInsertAudit)).
+		WithArgs("", "", "", "user-1", pgxmock.AnyArg(), "", "", "", "").
+		WillReturnError(errors.New("audit insert failed"))
+	mock.ExpectRollback()
+
+	err = db.WithTx(tenant.WithID(context.Background(), "org-1"), database.TxOptions{}, func(ctx context.Context) error {
+		if err := userRepo.UpdateRole(ctx, "user-1", "admin"); err != nil {
+			return err
+		}
+		return auditRepo.Create(ctx, &entity.AuditEvent{ResourceID: "user-1"})
+	})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"context"
+	"go-boilerplate/internal/dto"
 	"go-boilerplate/internal/entity"
 	"go-boilerplate/internal/repository"
 	"regexp"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/pkg/tenant"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v4"
@@ -64,11 +66,12 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	repo := repository.NewUserRepository(db)
 	email := "test@example.com"
 
-	const sqlSelect = `SELECT id, email, password FROM users 
+	const sqlSelect = `SELECT id, email, password, role, status, status_reason, status_expires_at FROM users 
               WHERE email = $1 AND deleted_at IS NULL`
 
-	rows := pgxmock.NewRows([]string{"id", "email", "password"}).
-		AddRow("019c514b-a933-74f2-8d08-a496675c66cf", email, "hashed_password")
+	expiresAt := time.Now().Add(time.Hour)
+	rows := pgxmock.NewRows([]string{"id", "email", "password", "role", "status", "status_reason", "status_expires_at"}).
+		AddRow("019c514b-a933-74f2-8d08-a496675c66cf", email, "hashed_password", "user", entity.UserStatusSuspended, "spam", &expiresAt)
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).
 		WithArgs(email).
@@ -78,6 +81,8 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
 	assert.Equal(t, email, user.Email)
+	assert.Equal(t, entity.UserStatusSuspended, user.Status)
+	assert.True(t, user.Blocked(time.Now()))
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -91,25 +96,25 @@ func TestUserRepository_List(t *testing.T) {
 		Slave:  mock,
 	}
 	repo := repository.NewUserRepository(db)
-	page, limit := 1, 10
-	order := "created_at desc"
+	req := dto.ListUsersRequest{Page: 1, Limit: 10, Order: "created_at desc"}
 
 	// Count query
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM users WHERE deleted_at IS NULL`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM users WHERE deleted_at IS NULL  AND EXISTS (SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = $1)`)).
+		WithArgs("org-1").
 		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(2)))
 
 	// List query
-	rows := pgxmock.NewRows([]string{"id", "email", "created_at", "updated_at"}).
-		AddRow("019c514b-a933-74f2-8d08-a496675c66cf", "u1@example.com", time.Now(), time.Now()).
-		AddRow("019c514b-a933-74f2-8d08-a496675c66d0", "u2@example.com", time.Now(), time.Now())
-
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, created_at AT TIME ZONE 'UTC', updated_at AT TIME ZONE 'UTC' FROM users 
-                          WHERE deleted_at IS NULL 
-                          ORDER BY created_at desc LIMIT $1 OFFSET $2`)).
-		WithArgs(limit, 0).
+	rows := pgxmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at", "updated_at"}).
+		AddRow("019c514b-a933-74f2-8d08-a496675c66cf", "u1@example.com", "", "", time.Now(), time.Now()).
+		AddRow("019c514b-a933-74f2-8d08-a496675c66d0", "u2@example.com", "", "", time.Now(), time.Now())
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, first_name, last_name, created_at AT TIME ZONE 'UTC', updated_at AT TIME ZONE 'UTC' FROM users 
+                          WHERE deleted_at IS NULL  AND EXISTS (SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = $1) 
+                          ORDER BY created_at desc LIMIT $2 OFFSET $3`)).
+		WithArgs("org-1", req.Limit, 0).
 		WillReturnRows(rows)
 
-	users, total, err := repo.List(context.Background(), page, limit, order, "UTC")
+	users, total, err := repo.List(tenant.WithID(context.Background(), "org-1"), req, "UTC")
 	assert.NoError(t, err)
 	assert.Equal(t, int64(2), total)
 	assert.Len(t, users, 2)
@@ -128,16 +133,123 @@ func TestUserRepository_GetByID_NotFound(t *testing.T) {
 	repo := repository.NewUserRepository(db)
 	id := "019c514b-a933-74f2-8d08-a496675c66cf"
 
-	const sqlSelect = `SELECT id, email, password, created_at AT TIME ZONE 'UTC', updated_at AT TIME ZONE 'UTC' FROM users 
-              WHERE id = $1 AND deleted_at IS NULL`
+	const sqlSelect = `SELECT id, email, first_name, last_name, password, role, created_at AT TIME ZONE 'UTC', updated_at AT TIME ZONE 'UTC',
+                     status, status_reason, status_expires_at FROM users 
+              WHERE id = $1 AND deleted_at IS NULL AND EXISTS (SELECT 1 FROM memberships m WHERE m.user_id = users.id AND m.organization_id = $2)`
 
 	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).
-		WithArgs(id).
+		WithArgs(id, "org-1").
 		WillReturnError(pgx.ErrNoRows)
 
-	user, err := repo.GetByID(context.Background(), id, "UTC")
+	user, err := repo.GetByID(tenant.WithID(context.Background(), "org-1"), id, "UTC")
 	assert.Error(t, err)
 	assert.Nil(t, user)
 	assert.Contains(t, err.Error(), "user not found")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepository_Stream(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &database.Database{
+		Master: mock,
+		Slave:  mock,
+	}
+	repo := repository.NewUserRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DECLARE user_export NO SCROLL CURSOR FOR`)).
+		WithArgs("%example%", "org-1").
+		WillReturnResult(pgxmock.NewResult("DECLARE CURSOR", 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`FETCH FORWARD 1000 FROM user_export`)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "email", "first_name", "last_name", "role", "created_at", "updated_at"}).
+			AddRow("019c514b-a933-74f2-8d08-a496675c66cf", "u1@example.com", "", "", "user", time.Now(), time.Now()).
+			AddRow("019c514b-a933-74f2-8d08-a496675c66d0", "u2@example.com", "", "", "admin", time.Now(), time.Now()))
+	mock.ExpectRollback()
+
+	var emails []string
+	err = repo.Stream(tenant.WithID(context.Background(), "org-1"), dto.ListUsersRequest{Search: "example"}, func(user entity.User) error {
+		emails = append(emails, user.Email)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1@example.com", "u2@example.com"}, emails)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_Search(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &database.Database{
+		Master: mock,
+		Slave:  mock,
+	}
+	repo := repository.NewUserRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY score DESC, email`)).
+		WithArgs("jo_n", `jo\_n%`, 10, "org-1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "email", "first_name", "last_name", "created_at", "updated_at", "score"}).
+			AddRow("019c514b-a933-74f2-8d08-a496675c66cf", "jo_n@example.com", "Jo", "N", time.Now(), time.Now(), 1.5))
+
+	results, err := repo.Search(tenant.WithID(context.Background(), "org-1"), "jo_n", 10)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 1.5, results[0].Score)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_GetByID_RequiresTenant(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewUserRepository(&database.Database{Master: mock, Slave: mock})
+
+	user, err := repo.GetByID(context.Background(), "019c514b-a933-74f2-8d08-a496675c66cf", "UTC")
+	assert.ErrorIs(t, err, tenant.ErrMissing)
+	assert.Nil(t, user)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_GetByID_SystemIsUnscoped(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewUserRepository(&database.Database{Master: mock, Slave: mock})
+	id := "019c514b-a933-74f2-8d08-a496675c66cf"
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE id = $1 AND deleted_at IS NULL`)).
+		WithArgs(id).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.GetByID(tenant.WithSystem(tenant.WithID(context.Background(), "org-1")), id, "UTC")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_LiftExpiredSuspensions(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	db := &database.Database{
+		Master: mock,
+		Slave:  mock,
+	}
+	repo := repository.NewUserRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET status = $1, status_reason = '', status_expires_at = NULL`)).
+		WithArgs(entity.UserStatusActive, entity.UserStatusSuspended, now).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow("user-1").AddRow("user-2"))
+
+	ids, err := repo.LiftExpiredSuspensions(tenant.WithSystem(context.Background()), now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user-1", "user-2"}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,151 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/dto"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/infrastructure/database"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/request"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, event *entity.AuditEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, req dto.ListAuditEventsRequest) ([]entity.AuditEvent, int64, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]entity.AuditEvent), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAuditRepository) ListByUser(ctx context.Context, userID string) ([]entity.AuditEvent, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.AuditEvent), args.Error(1)
+}
+
+func (m *MockAuditRepository) RedactUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// fakeTransactor runs fn directly; atomicity is covered by the database package
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithTx(ctx context.Context, _ database.TxOptions, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// unreachableRedis fails every command immediately, cache invalidation is best effort
+func unreachableRedis() *goredis.Client {
+	return goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+}
+
+func TestUserUsecase_UpdateUser_RecordsAudit(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, mockAudit, nil, fakeTransactor{}, &config.Config{}, nil, cache.New(unreachableRedis()))
+
+	before := &entity.User{ID: "user-1", Email: "old@example.com", Password: "hash", Role: entity.RoleUser}
+	mockRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(before, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+		return u.Email == "new@example.com"
+	})).Return(nil)
+
+	var recorded *entity.AuditEvent
+	mockAudit.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*entity.AuditEvent)
+	}).Return(nil)
+
+	ctx := request.WithMetadata(context.Background(), request.Metadata{
+		ActorID: "admin-1", IP: "10.0.0.1", UserAgent: "curl/8.0",
+	})
+	err := uc.UpdateUser(ctx, "user-1", "new@example.com")
+	assert.NoError(t, err)
+
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, entity.AuditActionUserUpdate, recorded.Action)
+		assert.Equal(t, entity.AuditResourceUser, recorded.ResourceType)
+		assert.Equal(t, "user-1", recorded.ResourceID)
+		assert.Equal(t, "admin-1", recorded.ActorID)
+		assert.Equal(t, "10.0.0.1", recorded.IP)
+		assert.Equal(t, "curl/8.0", recorded.UserAgent)
+		assert.Equal(t, map[string]entity.AuditChange{
+			"email": {Before: "old@example.com", After: "new@example.com"},
+		}, recorded.Changes)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserUsecase_UpdateUser_AuditFailureAborts(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, mockAudit, nil, fakeTransactor{}, &config.Config{}, nil, cache.New(unreachableRedis()))
+
+	mockRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(&entity.User{ID: "user-1", Email: "old@example.com"}, nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.Anything).Return(errors.New("insert failed"))
+
+	err := uc.UpdateUser(context.Background(), "user-1", "new@example.com")
+	assert.Error(t, err)
+}
+
+func TestUserUsecase_ChangeRole(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, mockAudit, nil, fakeTransactor{}, &config.Config{}, nil, cache.New(unreachableRedis()))
+
+	mockRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(&entity.User{ID: "user-1", Role: entity.RoleUser}, nil)
+	mockRepo.On("UpdateRole", mock.Anything, "user-1", entity.RoleAdmin).Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		change, ok := e.Changes["role"]
+		return e.Action == entity.AuditActionUserRoleChange && ok &&
+			change.Before == entity.RoleUser && change.After == entity.RoleAdmin
+	})).Return(nil)
+
+	err := uc.ChangeRole(context.Background(), "user-1", entity.RoleAdmin)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestUserUsecase_ChangeRole_InvalidRole(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, new(MockAuditRepository), nil, fakeTransactor{}, &config.Config{}, nil, nil)
+
+	err := uc.ChangeRole(context.Background(), "user-1", "superuser")
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAuditUsecase_ListEvents_ClampsLimit(t *testing.T) {
+	mockAudit := new(MockAuditRepository)
+	uc := usecase.NewAuditUsecase(mockAudit)
+
+	req := dto.ListAuditEventsRequest{Action: entity.AuditActionUserDelete, Page: 2, Limit: 500}
+	expected := req
+	expected.Limit = 100
+	mockAudit.On("List", mock.Anything, expected).Return([]entity.AuditEvent{{ID: "evt-1"}}, int64(101), nil)
+
+	events, meta, err := uc.ListEvents(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, 100, meta.Offset)
+	assert.Equal(t, 100, meta.Limit)
+	assert.Equal(t, int64(101), meta.Total)
+	mockAudit.AssertExpectations(t)
+}
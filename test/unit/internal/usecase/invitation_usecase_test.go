@@ -0,0 +1,236 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/delivery/worker"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/mailer"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/auth"
+	"go-boilerplate/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockInvitationRepository
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) Create(ctx context.Context, invitation *entity.Invitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) GetByID(ctx context.Context, id string) (*entity.Invitation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) ListPending(ctx context.Context) ([]entity.Invitation, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entity.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) UpdateToken(ctx context.Context, invitation *entity.Invitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) UpdateStatus(ctx context.Context, id string, status entity.InvitationStatus) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) DeleteByUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// loopbackPublisher delivers mail queue messages straight to a mail worker, like RabbitMQ would
+type loopbackPublisher struct {
+	worker *worker.MailWorker
+}
+
+func (p loopbackPublisher) Publish(ctx context.Context, queue string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return p.worker.Handle(ctx, body)
+}
+
+func invitationConfig() *config.Config {
+	return &config.Config{
+		JWT: config.JWTConfig{
+			PrivateKeyPath: "../../../../certs/private.pem",
+			PublicKeyPath:  "../../../../certs/public.pem",
+		},
+		Mail:   config.MailConfig{Queue: "mail.send"},
+		Invite: config.InviteConfig{Expiry: 72 * time.Hour, AcceptURL: "https://app.example.com/invitations/accept"},
+	}
+}
+
+func TestInvitationUsecase_Invite_SendsMail(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	mockOrg := new(MockOrganizationRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	outbox := mailer.NewMemory()
+	cfg := invitationConfig()
+	uc := usecase.NewInvitationUsecase(mockRepo, mockOrg, mockUserRepo, mockAudit, fakeTransactor{},
+		loopbackPublisher{worker.NewMailWorker(outbox)}, cfg)
+
+	mockOrg.On("GetMembership", mock.Anything, "org-1", "owner-1").Return(&entity.Membership{
+		OrganizationID: "org-1", OrganizationName: "Acme", UserID: "owner-1", Role: entity.MembershipRoleOwner,
+	}, nil)
+	mockUserRepo.On("GetByEmail", mock.Anything, "new@example.com").Return(nil, repository.ErrUserNotFound)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(inv *entity.Invitation) bool {
+		return inv.Email == "new@example.com" && inv.Role == entity.MembershipRoleAdmin && inv.InvitedBy == "owner-1"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Invitation).ID = "inv-1"
+	}).Return(nil)
+	var storedHash string
+	mockRepo.On("UpdateToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		storedHash = args.Get(1).(*entity.Invitation).TokenHash
+	}).Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditActionInvitationCreate && e.TenantID == "org-1"
+	})).Return(nil)
+
+	ctx := tenant.WithID(context.Background(), "org-1")
+	inv, err := uc.Invite(ctx, "org-1", "owner-1", " New@Example.com ", entity.MembershipRoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, "inv-1", inv.ID)
+
+	sent := outbox.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "new@example.com", sent[0].To)
+	assert.Contains(t, sent[0].Subject, "Acme")
+
+	// The emailed link carries a signed token for this invitation whose hash was stored
+	idx := strings.Index(sent[0].Body, cfg.Invite.AcceptURL+"?token=")
+	require.GreaterOrEqual(t, idx, 0)
+	link := strings.Fields(sent[0].Body[idx:])[0]
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	token := parsed.Query().Get("token")
+	id, err := auth.ValidateInvitationToken(token, cfg.JWT)
+	require.NoError(t, err)
+	assert.Equal(t, "inv-1", id)
+	sum := sha256.Sum256([]byte(token))
+	assert.Equal(t, hex.EncodeToString(sum[:]), storedHash)
+}
+
+func TestInvitationUsecase_Invite_AdminRoleRequiresOwner(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	mockOrg := new(MockOrganizationRepository)
+	outbox := mailer.NewMemory()
+	uc := usecase.NewInvitationUsecase(mockRepo, mockOrg, nil, nil, fakeTransactor{},
+		loopbackPublisher{worker.NewMailWorker(outbox)}, invitationConfig())
+
+	mockOrg.On("GetMembership", mock.Anything, "org-1", "admin-1").
+		Return(&entity.Membership{OrganizationID: "org-1", UserID: "admin-1", Role: entity.MembershipRoleAdmin}, nil)
+
+	_, err := uc.Invite(tenant.WithID(context.Background(), "org-1"), "org-1", "admin-1", "new@example.com", entity.MembershipRoleAdmin)
+	assert.ErrorContains(t, err, "Only owners")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	assert.Empty(t, outbox.Sent())
+}
+
+func TestInvitationUsecase_Accept_CreatesAccount(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	mockOrg := new(MockOrganizationRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	cfg := invitationConfig()
+	uc := usecase.NewInvitationUsecase(mockRepo, mockOrg, mockUserRepo, mockAudit, fakeTransactor{}, nil, cfg)
+
+	expiresAt := time.Now().Add(time.Hour)
+	token, err := auth.GenerateInvitationToken("inv-1", expiresAt, cfg.JWT)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte(token))
+
+	mockRepo.On("GetByID", mock.Anything, "inv-1").Return(&entity.Invitation{
+		ID: "inv-1", OrganizationID: "org-1", Email: "new@example.com", Role: entity.MembershipRoleMember,
+		Status: entity.InvitationStatusPending, TokenHash: hex.EncodeToString(sum[:]), ExpiresAt: expiresAt,
+	}, nil)
+	mockUserRepo.On("GetByEmail", mock.Anything, "new@example.com").Return(nil, repository.ErrUserNotFound)
+	mockUserRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+		return u.Email == "new@example.com" && u.Password != "password123"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.User).ID = "user-9"
+	}).Return(nil)
+	mockOrg.On("GetMembership", mock.Anything, "org-1", "user-9").Return(nil, errors.New("membership not found"))
+	mockOrg.On("AddMember", mock.Anything, &entity.Membership{
+		OrganizationID: "org-1", UserID: "user-9", Role: entity.MembershipRoleMember,
+	}).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "inv-1", entity.InvitationStatusAccepted).Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditActionInvitationAccept && e.ActorID == "user-9" && e.TenantID == "org-1"
+	})).Return(nil)
+
+	res, err := uc.Accept(context.Background(), token, "password123")
+	require.NoError(t, err)
+	assert.Equal(t, "org-1", res.OrganizationID)
+	assert.Equal(t, "user-9", res.UserID)
+	assert.True(t, res.AccountCreated)
+	mockOrg.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInvitationUsecase_Accept_LookupFailureDoesNotCreateAccount(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	mockUserRepo := new(MockUserRepository)
+	cfg := invitationConfig()
+	uc := usecase.NewInvitationUsecase(mockRepo, nil, mockUserRepo, nil, fakeTransactor{}, nil, cfg)
+
+	expiresAt := time.Now().Add(time.Hour)
+	token, err := auth.GenerateInvitationToken("inv-1", expiresAt, cfg.JWT)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte(token))
+
+	mockRepo.On("GetByID", mock.Anything, "inv-1").Return(&entity.Invitation{
+		ID: "inv-1", OrganizationID: "org-1", Email: "taken@example.com", Role: entity.MembershipRoleMember,
+		Status: entity.InvitationStatusPending, TokenHash: hex.EncodeToString(sum[:]), ExpiresAt: expiresAt,
+	}, nil)
+	mockUserRepo.On("GetByEmail", mock.Anything, "taken@example.com").Return(nil, errors.New("connection reset"))
+
+	_, err = uc.Accept(context.Background(), token, "password123")
+	assert.ErrorContains(t, err, "Failed to load user")
+	mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInvitationUsecase_Accept_ReplacedToken(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	cfg := invitationConfig()
+	uc := usecase.NewInvitationUsecase(mockRepo, nil, nil, nil, fakeTransactor{}, nil, cfg)
+
+	token, err := auth.GenerateInvitationToken("inv-1", time.Now().Add(time.Hour), cfg.JWT)
+	require.NoError(t, err)
+	mockRepo.On("GetByID", mock.Anything, "inv-1").Return(&entity.Invitation{
+		ID: "inv-1", OrganizationID: "org-1", Status: entity.InvitationStatusPending,
+		TokenHash: "hash-of-a-newer-token", ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	_, err = uc.Accept(context.Background(), token, "")
+	assert.ErrorContains(t, err, "replaced")
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,15 @@
+package usecase_test
+
+import (
+	"os"
+	"testing"
+
+	"go-boilerplate/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	// Console-only logger, usecases log through the global logger
+	logger.InitLogger(nil)
+
+	os.Exit(m.Run())
+}
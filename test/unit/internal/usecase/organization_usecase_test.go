@@ -0,0 +1,140 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrganizationRepository
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepository) Create(ctx context.Context, org *entity.Organization) error {
+	args := m.Called(ctx, org)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) AddMember(ctx context.Context, membership *entity.Membership) error {
+	args := m.Called(ctx, membership)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetMembership(ctx context.Context, organizationID, userID string) (*entity.Membership, error) {
+	args := m.Called(ctx, organizationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Membership), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListMemberships(ctx context.Context, userID string) ([]entity.Membership, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Membership), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListMembers(ctx context.Context, organizationID string) ([]entity.Membership, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).([]entity.Membership), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	args := m.Called(ctx, organizationID, userID)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) RemoveUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestOrganizationUsecase_Create_OwnsAndAudits(t *testing.T) {
+	mockOrg := new(MockOrganizationRepository)
+	mockAudit := new(MockAuditRepository)
+	uc := usecase.NewOrganizationUsecase(mockOrg, mockAudit, fakeTransactor{})
+
+	mockOrg.On("Create", mock.Anything, mock.MatchedBy(func(o *entity.Organization) bool {
+		return o.Name == "Acme"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Organization).ID = "org-2"
+	}).Return(nil)
+	mockOrg.On("AddMember", mock.Anything, &entity.Membership{
+		OrganizationID: "org-2", UserID: "user-1", Role: entity.MembershipRoleOwner,
+	}).Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditActionOrganizationCreate && e.TenantID == "org-2"
+	})).Return(nil)
+
+	ctx := tenant.WithID(context.Background(), "org-1")
+	org, err := uc.Create(ctx, "user-1", "  Acme ")
+	assert.NoError(t, err)
+	assert.Equal(t, "org-2", org.ID)
+	mockOrg.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestOrganizationUsecase_ListMine_IgnoresCurrentTenant(t *testing.T) {
+	mockOrg := new(MockOrganizationRepository)
+	uc := usecase.NewOrganizationUsecase(mockOrg, nil, fakeTransactor{})
+
+	mockOrg.On("ListMemberships", mock.MatchedBy(func(ctx context.Context) bool {
+		scope, err := tenant.Scope(ctx)
+		return err == nil && scope == ""
+	}), "user-1").Return([]entity.Membership{{OrganizationID: "org-1"}, {OrganizationID: "org-2"}}, nil)
+
+	memberships, err := uc.ListMine(tenant.WithID(context.Background(), "org-1"), "user-1")
+	assert.NoError(t, err)
+	assert.Len(t, memberships, 2)
+}
+
+func TestOrganizationUsecase_CurrentMembership_UsesTokenTenant(t *testing.T) {
+	mockOrg := new(MockOrganizationRepository)
+	uc := usecase.NewOrganizationUsecase(mockOrg, nil, fakeTransactor{})
+
+	mockOrg.On("GetMembership", mock.Anything, "org-1", "user-1").
+		Return(&entity.Membership{OrganizationID: "org-1", UserID: "user-1", Role: entity.MembershipRoleAdmin}, nil)
+	mockOrg.On("GetMembership", mock.Anything, "org-2", "user-1").Return(nil, errors.New("membership not found"))
+
+	membership, err := uc.CurrentMembership(tenant.WithID(context.Background(), "org-1"), "user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.MembershipRoleAdmin, membership.Role)
+
+	_, err = uc.CurrentMembership(tenant.WithID(context.Background(), "org-2"), "user-1")
+	assert.ErrorContains(t, err, "Not a member")
+	_, err = uc.CurrentMembership(context.Background(), "user-1")
+	assert.ErrorContains(t, err, "Log in to an organization")
+}
+
+func TestOrganizationUsecase_RemoveMember_Rules(t *testing.T) {
+	mockOrg := new(MockOrganizationRepository)
+	mockAudit := new(MockAuditRepository)
+	uc := usecase.NewOrganizationUsecase(mockOrg, mockAudit, fakeTransactor{})
+	ctx := tenant.WithID(context.Background(), "org-1")
+
+	mockOrg.On("GetMembership", mock.Anything, "org-1", "admin-1").
+		Return(&entity.Membership{OrganizationID: "org-1", UserID: "admin-1", Role: entity.MembershipRoleAdmin}, nil)
+	mockOrg.On("GetMembership", mock.Anything, "org-1", "owner-1").
+		Return(&entity.Membership{OrganizationID: "org-1", UserID: "owner-1", Role: entity.MembershipRoleOwner}, nil)
+	mockOrg.On("GetMembership", mock.Anything, "org-1", "admin-2").
+		Return(&entity.Membership{OrganizationID: "org-1", UserID: "admin-2", Role: entity.MembershipRoleAdmin}, nil)
+	mockOrg.On("GetMembership", mock.Anything, "org-1", "member-1").
+		Return(&entity.Membership{OrganizationID: "org-1", UserID: "member-1", Role: entity.MembershipRoleMember}, nil)
+	mockOrg.On("RemoveMember", mock.Anything, "org-1", "member-1").Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditActionMemberRemove
+	})).Return(nil)
+
+	assert.ErrorContains(t, uc.RemoveMember(ctx, "org-1", "admin-1", "owner-1"), "Owners cannot be removed")
+	assert.ErrorContains(t, uc.RemoveMember(ctx, "org-1", "admin-1", "admin-2"), "Only owners")
+	assert.ErrorContains(t, uc.RemoveMember(ctx, "org-2", "admin-1", "member-1"), "Log in to this organization")
+	assert.NoError(t, uc.RemoveMember(ctx, "org-1", "admin-1", "member-1"))
+	mockOrg.AssertNumberOfCalls(t, "RemoveMember", 1)
+}
@@ -0,0 +1,157 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOutboxRepository
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Create(ctx context.Context, event *entity.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) ClaimPending(ctx context.Context, limit int) ([]entity.OutboxEvent, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]entity.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkSent(ctx context.Context, ids []string) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id string, reason string, maxAttempts int) (bool, error) {
+	args := m.Called(ctx, id, reason, maxAttempts)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOutboxRepository) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// fakeEventPublisher records published message IDs and fails those listed in fail
+type fakeEventPublisher struct {
+	published []string
+	fail      map[string]bool
+}
+
+func (p *fakeEventPublisher) PublishEvent(ctx context.Context, routingKey, messageID string, body []byte) error {
+	if p.fail[messageID] {
+		return errors.New("nacked")
+	}
+	p.published = append(p.published, routingKey+":"+messageID)
+	return nil
+}
+
+var outboxConfig = config.OutboxConfig{BatchSize: 10, PublishTimeout: time.Second, MaxAttempts: 3, Retention: time.Hour}
+
+func pendingEvents() []entity.OutboxEvent {
+	return []entity.OutboxEvent{
+		{ID: "evt-1", EventType: entity.EventUserRegistered, Payload: []byte(`{}`)},
+		{ID: "evt-2", EventType: entity.EventUserDeleted, Payload: []byte(`{}`)},
+		{ID: "evt-3", EventType: entity.EventUserRegistered, Payload: []byte(`{}`)},
+	}
+}
+
+func TestOutboxUsecase_Relay(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	publisher := &fakeEventPublisher{}
+	uc := usecase.NewOutboxUsecase(mockRepo, fakeTransactor{}, publisher, outboxConfig)
+
+	mockRepo.On("ClaimPending", mock.Anything, 10).Return(pendingEvents(), nil)
+	mockRepo.On("MarkSent", mock.Anything, []string{"evt-1", "evt-2", "evt-3"}).Return(nil)
+
+	sent, err := uc.Relay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, sent)
+	assert.Equal(t, []string{"user.registered:evt-1", "user.deleted:evt-2", "user.registered:evt-3"}, publisher.published)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOutboxUsecase_Relay_StopsAtFailure(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	publisher := &fakeEventPublisher{fail: map[string]bool{"evt-2": true}}
+	uc := usecase.NewOutboxUsecase(mockRepo, fakeTransactor{}, publisher, outboxConfig)
+
+	mockRepo.On("ClaimPending", mock.Anything, 10).Return(pendingEvents(), nil)
+	mockRepo.On("MarkFailed", mock.Anything, "evt-2", "nacked", 3).Return(false, nil)
+	mockRepo.On("MarkSent", mock.Anything, []string{"evt-1"}).Return(nil)
+
+	sent, err := uc.Relay(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, []string{"user.registered:evt-1"}, publisher.published)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOutboxUsecase_Relay_ParksEventAfterMaxAttempts(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	publisher := &fakeEventPublisher{fail: map[string]bool{"evt-1": true}}
+	uc := usecase.NewOutboxUsecase(mockRepo, fakeTransactor{}, publisher, outboxConfig)
+
+	events := pendingEvents()
+	events[0].Attempts = 2
+	mockRepo.On("ClaimPending", mock.Anything, 10).Return(events, nil).Once()
+	mockRepo.On("MarkFailed", mock.Anything, "evt-1", "nacked", 3).Return(true, nil)
+	mockRepo.On("MarkSent", mock.Anything, []string(nil)).Return(nil)
+
+	sent, err := uc.Relay(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 0, sent)
+
+	// Parked events are no longer claimed, so the next relay gets past it
+	mockRepo.On("ClaimPending", mock.Anything, 10).Return(events[1:], nil).Once()
+	mockRepo.On("MarkSent", mock.Anything, []string{"evt-2", "evt-3"}).Return(nil)
+
+	sent, err = uc.Relay(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOutboxUsecase_PurgeSent(t *testing.T) {
+	mockRepo := new(MockOutboxRepository)
+	uc := usecase.NewOutboxUsecase(mockRepo, fakeTransactor{}, &fakeEventPublisher{}, outboxConfig)
+
+	mockRepo.On("DeleteSent", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= time.Hour && time.Since(before) < time.Hour+time.Minute
+	})).Return(int64(4), nil)
+
+	purged, err := uc.PurgeSent(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+}
+
+func TestUserUsecase_DeleteUser_RecordsEvent(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	mockOutbox := new(MockOutboxRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, mockAudit, mockOutbox, fakeTransactor{}, &config.Config{}, nil, cache.New(unreachableRedis()))
+
+	mockRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(&entity.User{ID: "user-1", Email: "old@example.com"}, nil)
+	mockRepo.On("Delete", mock.Anything, "user-1").Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.OutboxEvent) bool {
+		return e.EventType == entity.EventUserDeleted && e.AggregateType == entity.AuditResourceUser && e.AggregateID == "user-1"
+	})).Return(nil)
+
+	err := uc.DeleteUser(context.Background(), "user-1")
+	assert.NoError(t, err)
+	mockOutbox.AssertExpectations(t)
+}
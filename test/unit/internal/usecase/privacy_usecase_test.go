@@ -0,0 +1,194 @@
+package usecase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/cache"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/auth"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *goredis.Client) {
+	mr := miniredis.RunT(t)
+	rdb := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return mr, rdb
+}
+
+func privacyConfig() *config.Config {
+	return &config.Config{
+		JWT:     config.JWTConfig{RefreshExpiresIn: 60},
+		Privacy: config.PrivacyConfig{ErasureQueue: "user.erasure", ExportLinkExpiry: 15 * time.Minute},
+	}
+}
+
+func TestPrivacyUsecase_ExportData(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	mockJobRepo := new(MockJobRepository)
+	mockStorage := new(MockStorage)
+	uc := usecase.NewPrivacyUsecase(mockUserRepo, mockAudit, mockJobRepo, nil, nil, fakeTransactor{}, mockStorage, nil, nil, nil, privacyConfig())
+
+	user := &entity.User{ID: "user-1", Email: "jane@example.com", Password: "hash"}
+	mockUserRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(user, nil)
+	mockAudit.On("ListByUser", mock.Anything, "user-1").Return([]entity.AuditEvent{{ID: "evt-1", Action: entity.AuditActionUserRegister}}, nil)
+	mockJobRepo.On("ListByCreator", mock.Anything, "user-1").Return([]entity.Job{}, nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditActionUserExport
+	})).Return(nil)
+
+	var archive []byte
+	var key string
+	mockStorage.On("Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "application/zip").Run(func(args mock.Arguments) {
+		key = args.String(1)
+		archive, _ = io.ReadAll(args.Get(2).(io.Reader))
+	}).Return(nil)
+	mockStorage.On("PresignedGetURL", mock.Anything, mock.Anything, 15*time.Minute).Return("https://minio.local/export.zip", nil)
+
+	res, err := uc.ExportData(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://minio.local/export.zip", res.URL)
+	assert.Contains(t, key, "exports/users/user-1/")
+
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	assert.Contains(t, files["profile.json"], "jane@example.com")
+	assert.NotContains(t, files["profile.json"], "hash")
+	assert.Contains(t, files["audit_events.json"], "evt-1")
+	assert.Contains(t, files, "jobs.json")
+}
+
+func TestPrivacyUsecase_RequestErasure_RevokesTokens(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	mockUserRepo := new(MockUserRepository)
+	mockJobRepo := new(MockJobRepository)
+	mockPublisher := new(MockPublisher)
+	uc := usecase.NewPrivacyUsecase(mockUserRepo, nil, mockJobRepo, nil, nil, fakeTransactor{}, nil, mockPublisher, cache.New(rdb), cache.New(rdb), privacyConfig())
+
+	mockUserRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(&entity.User{ID: "user-1"}, nil)
+	mockJobRepo.On("Create", mock.Anything, mock.MatchedBy(func(j *entity.Job) bool {
+		return j.Type == entity.JobTypeUserErasure && j.CreatedBy == "user-1"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Job).ID = "job-1"
+	}).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, "user.erasure", usecase.ErasureMessage{JobID: "job-1"}).Return(nil)
+
+	job, err := uc.RequestErasure(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", job.ID)
+
+	issuedBefore := &auth.Claims{UserID: "user-1"}
+	issuedBefore.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	revoked, err := auth.IsRevoked(context.Background(), cache.New(rdb), issuedBefore)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestPrivacyUsecase_ProcessErasure(t *testing.T) {
+	mr, rdb := newTestRedis(t)
+	mockUserRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	mockJobRepo := new(MockJobRepository)
+	mockOrgRepo := new(MockOrganizationRepository)
+	mockInvitationRepo := new(MockInvitationRepository)
+	mockStorage := new(MockStorage)
+	appCache := cache.New(rdb)
+	uc := usecase.NewPrivacyUsecase(mockUserRepo, mockAudit, mockJobRepo, mockOrgRepo, mockInvitationRepo, fakeTransactor{}, mockStorage, nil, appCache, appCache, privacyConfig())
+
+	ctx := context.Background()
+	appCache.Set(ctx, "user:user-1:org-1:UTC", entity.User{}, time.Hour, "user:user-1")
+	appCache.Set(ctx, "user:user-1:org-2:Asia/Jakarta", entity.User{}, time.Hour, "user:user-1")
+	appCache.Set(ctx, "user:user-2:org-1:UTC", entity.User{}, time.Hour, "user:user-2")
+
+	job := &entity.Job{ID: "job-1", Type: entity.JobTypeUserErasure, Status: entity.JobStatusPending, CreatedBy: "user-1"}
+	mockJobRepo.On("GetByID", mock.Anything, "job-1").Return(job, nil)
+	mockJobRepo.On("Claim", mock.Anything, "job-1").Return(true, nil)
+	mockJobRepo.On("UpdateStatus", mock.Anything, "job-1", entity.JobStatusCompleted, "").Return(nil)
+	mockInvitationRepo.On("DeleteByUser", mock.Anything, "user-1").Return(nil)
+	mockOrgRepo.On("RemoveUser", mock.Anything, "user-1").Return(nil)
+	mockStorage.On("DeletePrefix", mock.Anything, "exports/users/user-1/").Return(nil)
+	mockUserRepo.On("Anonymize", mock.Anything, "user-1", "erased+user-1@erased.invalid").Return(nil)
+	mockAudit.On("RedactUser", mock.Anything, "user-1").Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditActionUserErase && e.ResourceID == "user-1"
+	})).Return(nil)
+
+	var certData []byte
+	mockStorage.On("Upload", mock.Anything, "erasures/job-1/certificate.json", mock.Anything, mock.Anything, "application/json").
+		Run(func(args mock.Arguments) {
+			certData, _ = io.ReadAll(args.Get(2).(io.Reader))
+		}).Return(nil)
+
+	err := uc.ProcessErasure(context.Background(), "job-1")
+	require.NoError(t, err)
+
+	assert.False(t, mr.Exists("user:user-1:org-1:UTC"))
+	assert.False(t, mr.Exists("user:user-1:org-2:Asia/Jakarta"))
+	assert.True(t, mr.Exists("user:user-2:org-1:UTC"))
+	assert.True(t, mr.Exists(auth.RevokedKey("user-1")))
+
+	var cert entity.ErasureCertificate
+	require.NoError(t, json.Unmarshal(certData, &cert))
+	assert.Equal(t, "user-1", cert.SubjectID)
+	steps := make([]string, 0, len(cert.Steps))
+	for _, step := range cert.Steps {
+		steps = append(steps, step.Name)
+	}
+	assert.Equal(t, []string{
+		entity.ErasureStepDeleteInvitations,
+		entity.ErasureStepRemoveMemberships,
+		entity.ErasureStepAnonymizeProfile,
+		entity.ErasureStepRedactAuditTrail,
+		entity.ErasureStepDeleteExports,
+		entity.ErasureStepRevokeTokens,
+		entity.ErasureStepPurgeCache,
+	}, steps)
+
+	digest := cert.Digest
+	cert.Digest = ""
+	unsigned, _ := json.Marshal(cert)
+	sum := sha256.Sum256(unsigned)
+	assert.Equal(t, hex.EncodeToString(sum[:]), digest)
+	mockJobRepo.AssertExpectations(t)
+	mockInvitationRepo.AssertExpectations(t)
+	mockOrgRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestPrivacyUsecase_GetErasureCertificate_NotCompleted(t *testing.T) {
+	mockJobRepo := new(MockJobRepository)
+	uc := usecase.NewPrivacyUsecase(nil, nil, mockJobRepo, nil, nil, fakeTransactor{}, nil, nil, nil, nil, privacyConfig())
+
+	mockJobRepo.On("GetByID", mock.Anything, "job-1").
+		Return(&entity.Job{ID: "job-1", Type: entity.JobTypeUserErasure, Status: entity.JobStatusProcessing}, nil)
+
+	_, err := uc.GetErasureCertificate(context.Background(), "job-1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not completed")
+}
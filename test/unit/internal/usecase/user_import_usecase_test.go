@@ -0,0 +1,312 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockJobRepository
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Create(ctx context.Context, job *entity.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) GetByID(ctx context.Context, id string) (*entity.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) ListByCreator(ctx context.Context, userID string) ([]entity.Job, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Claim(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) ReclaimStale(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	args := m.Called(ctx, id, staleBefore)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) ListStale(ctx context.Context, jobType entity.JobType, staleBefore time.Time) ([]string, error) {
+	args := m.Called(ctx, jobType, staleBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockJobRepository) UpdateStatus(ctx context.Context, id string, status entity.JobStatus, errMessage string) error {
+	args := m.Called(ctx, id, status, errMessage)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) UpdateProgress(ctx context.Context, job *entity.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) AddErrors(ctx context.Context, jobID string, jobErrors []entity.JobError) error {
+	args := m.Called(ctx, jobID, jobErrors)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ListErrors(ctx context.Context, jobID string, limit int) ([]entity.JobError, error) {
+	args := m.Called(ctx, jobID, limit)
+	return args.Get(0).([]entity.JobError), args.Error(1)
+}
+
+// MockStorage
+type MockStorage struct {
+	mock.Mock
+}
+
+func (m *MockStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	args := m.Called(ctx, key, reader, size, contentType)
+	return args.Error(0)
+}
+
+func (m *MockStorage) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorage) PresignedGetURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	args := m.Called(ctx, key, expiry)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	args := m.Called(ctx, prefix)
+	return args.Error(0)
+}
+
+// MockPublisher
+type MockPublisher struct {
+	mock.Mock
+}
+
+func (m *MockPublisher) Publish(ctx context.Context, queue string, payload any) error {
+	args := m.Called(ctx, queue, payload)
+	return args.Error(0)
+}
+
+func TestUserImportUsecase_StartImport(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockJobRepo := new(MockJobRepository)
+	mockStorage := new(MockStorage)
+	mockPublisher := new(MockPublisher)
+	cfg := config.ImportConfig{Queue: "user.import", BatchSize: 2, MaxFileSize: 1024}
+
+	uc := usecase.NewUserImportUsecase(mockUserRepo, mockJobRepo, mockStorage, mockPublisher, cfg)
+
+	mockJobRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Job")).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Job).ID = "job-1"
+	}).Return(nil)
+	mockStorage.On("Upload", mock.Anything, "imports/users/job-1.csv", mock.Anything, int64(10), "text/csv").Return(nil)
+	mockPublisher.On("Publish", mock.Anything, "user.import", usecase.ImportMessage{JobID: "job-1"}).Return(nil)
+
+	job, err := uc.StartImport(context.Background(), "admin-1", "users.csv", strings.NewReader("email,password"), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "job-1", job.ID)
+	assert.Equal(t, "admin-1", job.CreatedBy)
+	mockJobRepo.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestUserImportUsecase_StartImport_RejectsNonCSV(t *testing.T) {
+	uc := usecase.NewUserImportUsecase(nil, nil, nil, nil, config.ImportConfig{})
+
+	_, err := uc.StartImport(context.Background(), "admin-1", "users.xlsx", strings.NewReader(""), 10)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Only CSV files are supported")
+}
+
+func TestUserImportUsecase_ProcessImport_ReportsRowErrors(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockJobRepo := new(MockJobRepository)
+	mockStorage := new(MockStorage)
+	cfg := config.ImportConfig{Queue: "user.import", BatchSize: 10}
+
+	uc := usecase.NewUserImportUsecase(mockUserRepo, mockJobRepo, mockStorage, nil, cfg)
+
+	csvData := "email,password\n" +
+		"new@example.com,secret123\n" +
+		"not-an-email,secret123\n" +
+		"new@example.com,secret456\n" +
+		"taken@example.com,secret123\n"
+
+	job := &entity.Job{ID: "job-1", Status: entity.JobStatusPending, ObjectKey: "imports/users/job-1.csv"}
+	mockJobRepo.On("GetByID", mock.Anything, "job-1").Return(job, nil)
+	mockJobRepo.On("Claim", mock.Anything, "job-1").Return(true, nil)
+	mockJobRepo.On("UpdateStatus", mock.Anything, "job-1", entity.JobStatusCompleted, "").Return(nil)
+	mockJobRepo.On("UpdateProgress", mock.Anything, job).Return(nil)
+	mockStorage.On("Download", mock.Anything, "imports/users/job-1.csv").Return(io.NopCloser(strings.NewReader(csvData)), nil)
+
+	mockUserRepo.On("FindExistingEmails", mock.Anything, []string{"new@example.com", "taken@example.com"}).
+		Return(map[string]bool{"taken@example.com": true}, nil)
+	mockUserRepo.On("BulkCreate", mock.Anything, mock.MatchedBy(func(users []entity.User) bool {
+		return len(users) == 1 && users[0].Email == "new@example.com" && users[0].Password != "secret123"
+	})).Return(map[string]bool{"new@example.com": true}, nil)
+
+	var reported []entity.JobError
+	mockJobRepo.On("AddErrors", mock.Anything, "job-1", mock.Anything).Run(func(args mock.Arguments) {
+		reported = append(reported, args.Get(2).([]entity.JobError)...)
+	}).Return(nil)
+
+	err := uc.ProcessImport(context.Background(), "job-1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, job.TotalRows)
+	assert.Equal(t, 4, job.ProcessedRows)
+	assert.Equal(t, 1, job.SucceededRows)
+	assert.Equal(t, 3, job.FailedRows)
+
+	assert.Len(t, reported, 3)
+	assert.Equal(t, 3, reported[0].Row)
+	assert.Equal(t, "invalid email", reported[0].Message)
+	assert.Equal(t, 4, reported[1].Row)
+	assert.Contains(t, reported[1].Message, "duplicate email in file")
+	assert.Equal(t, 5, reported[2].Row)
+	assert.Equal(t, "email already exists", reported[2].Message)
+
+	mockJobRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestUserImportUsecase_ProcessImport_ReportsOnlyConflictingRowsOfBatch(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockJobRepo := new(MockJobRepository)
+	mockStorage := new(MockStorage)
+	uc := usecase.NewUserImportUsecase(mockUserRepo, mockJobRepo, mockStorage, nil, config.ImportConfig{BatchSize: 10})
+
+	csvData := "email,password\n" +
+		"first@example.com,secret123\n" +
+		"raced@example.com,secret123\n"
+
+	job := &entity.Job{ID: "job-1", Status: entity.JobStatusPending, ObjectKey: "imports/users/job-1.csv"}
+	mockJobRepo.On("GetByID", mock.Anything, "job-1").Return(job, nil)
+	mockJobRepo.On("Claim", mock.Anything, "job-1").Return(true, nil)
+	mockJobRepo.On("UpdateStatus", mock.Anything, "job-1", entity.JobStatusCompleted, "").Return(nil)
+	mockJobRepo.On("UpdateProgress", mock.Anything, job).Return(nil)
+	mockStorage.On("Download", mock.Anything, "imports/users/job-1.csv").Return(io.NopCloser(strings.NewReader(csvData)), nil)
+
+	// raced@example.com registered between the existence check and the insert
+	mockUserRepo.On("FindExistingEmails", mock.Anything, mock.Anything).Return(map[string]bool{}, nil)
+	mockUserRepo.On("BulkCreate", mock.Anything, mock.Anything).Return(map[string]bool{"first@example.com": true}, nil)
+
+	var reported []entity.JobError
+	mockJobRepo.On("AddErrors", mock.Anything, "job-1", mock.Anything).Run(func(args mock.Arguments) {
+		reported = append(reported, args.Get(2).([]entity.JobError)...)
+	}).Return(nil)
+
+	require.NoError(t, uc.ProcessImport(context.Background(), "job-1"))
+
+	assert.Equal(t, 1, job.SucceededRows)
+	assert.Equal(t, 1, job.FailedRows)
+	require.Len(t, reported, 1)
+	assert.Equal(t, "raced@example.com", reported[0].Email)
+	assert.Equal(t, "email already exists", reported[0].Message)
+}
+
+func TestUserImportUsecase_ProcessImport_SkipsNonPendingJob(t *testing.T) {
+	mockJobRepo := new(MockJobRepository)
+	uc := usecase.NewUserImportUsecase(nil, mockJobRepo, nil, nil, config.ImportConfig{})
+
+	mockJobRepo.On("GetByID", mock.Anything, "job-1").
+		Return(&entity.Job{ID: "job-1", Status: entity.JobStatusCompleted}, nil)
+
+	err := uc.ProcessImport(context.Background(), "job-1")
+	assert.NoError(t, err)
+	mockJobRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserImportUsecase_ProcessImport_SkipsJobClaimedByAnotherWorker(t *testing.T) {
+	mockJobRepo := new(MockJobRepository)
+	uc := usecase.NewUserImportUsecase(nil, mockJobRepo, nil, nil, config.ImportConfig{})
+
+	mockJobRepo.On("GetByID", mock.Anything, "job-1").
+		Return(&entity.Job{ID: "job-1", Status: entity.JobStatusPending}, nil)
+	mockJobRepo.On("Claim", mock.Anything, "job-1").Return(false, nil)
+
+	err := uc.ProcessImport(context.Background(), "job-1")
+	assert.NoError(t, err)
+	mockJobRepo.AssertNotCalled(t, "UpdateProgress", mock.Anything, mock.Anything)
+	mockJobRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserImportUsecase_ProcessImport_RestartsStaleProcessingJob(t *testing.T) {
+	mockJobRepo := new(MockJobRepository)
+	mockStorage := new(MockStorage)
+	uc := usecase.NewUserImportUsecase(nil, mockJobRepo, mockStorage, nil, config.ImportConfig{StaleAfter: 10 * time.Minute})
+
+	job := &entity.Job{ID: "job-1", Status: entity.JobStatusProcessing, ObjectKey: "imports/users/job-1.csv", TotalRows: 7, SucceededRows: 5}
+	mockJobRepo.On("GetByID", mock.Anything, "job-1").Return(job, nil)
+	mockJobRepo.On("ReclaimStale", mock.Anything, "job-1", mock.MatchedBy(func(staleBefore time.Time) bool {
+		return time.Since(staleBefore) >= 10*time.Minute
+	})).Return(true, nil)
+	mockJobRepo.On("AddErrors", mock.Anything, "job-1", mock.Anything).Return(nil)
+	mockJobRepo.On("UpdateProgress", mock.Anything, job).Return(nil)
+	mockJobRepo.On("UpdateStatus", mock.Anything, "job-1", entity.JobStatusCompleted, "").Return(nil)
+	mockStorage.On("Download", mock.Anything, "imports/users/job-1.csv").Return(io.NopCloser(strings.NewReader("email,password\n")), nil)
+
+	require.NoError(t, uc.ProcessImport(context.Background(), "job-1"))
+
+	// Progress of the abandoned run is not counted again
+	assert.Equal(t, 0, job.TotalRows)
+	assert.Equal(t, 0, job.SucceededRows)
+	mockJobRepo.AssertNotCalled(t, "Claim", mock.Anything, mock.Anything)
+	mockJobRepo.AssertExpectations(t)
+}
+
+func TestUserImportUsecase_ProcessImport_SkipsProcessingJobWithLiveWorker(t *testing.T) {
+	mockJobRepo := new(MockJobRepository)
+	uc := usecase.NewUserImportUsecase(nil, mockJobRepo, nil, nil, config.ImportConfig{StaleAfter: 10 * time.Minute})
+
+	mockJobRepo.On("GetByID", mock.Anything, "job-1").
+		Return(&entity.Job{ID: "job-1", Status: entity.JobStatusProcessing}, nil)
+	mockJobRepo.On("ReclaimStale", mock.Anything, "job-1", mock.Anything).Return(false, nil)
+
+	require.NoError(t, uc.ProcessImport(context.Background(), "job-1"))
+	mockJobRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserImportUsecase_RequeueStale(t *testing.T) {
+	mockJobRepo := new(MockJobRepository)
+	mockPublisher := new(MockPublisher)
+	uc := usecase.NewUserImportUsecase(nil, mockJobRepo, nil, mockPublisher, config.ImportConfig{Queue: "user.import", StaleAfter: time.Minute})
+
+	mockJobRepo.On("ListStale", mock.Anything, entity.JobTypeUserImport, mock.Anything).Return([]string{"job-1", "job-2"}, nil)
+	mockPublisher.On("Publish", mock.Anything, "user.import", usecase.ImportMessage{JobID: "job-1"}).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, "user.import", usecase.ImportMessage{JobID: "job-2"}).Return(nil)
+
+	requeued, err := uc.RequeueStale(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, requeued)
+	mockPublisher.AssertExpectations(t)
+}
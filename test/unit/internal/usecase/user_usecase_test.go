@@ -2,12 +2,18 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"go-boilerplate/internal/config"
+	"go-boilerplate/internal/dto"
 	"go-boilerplate/internal/entity"
+	"go-boilerplate/internal/infrastructure/cache"
 	"go-boilerplate/internal/usecase"
+	"go-boilerplate/pkg/auth"
+	appErrors "go-boilerplate/pkg/errors"
+	"go-boilerplate/pkg/tenant"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -32,11 +38,28 @@ func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*ent
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
-func (m *MockUserRepository) List(ctx context.Context, page, limit int, order string, timezone string) ([]entity.User, int64, error) {
-	args := m.Called(ctx, page, limit, order, timezone)
+func (m *MockUserRepository) List(ctx context.Context, req dto.ListUsersRequest, timezone string) ([]entity.User, int64, error) {
+	args := m.Called(ctx, req, timezone)
 	return args.Get(0).([]entity.User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockUserRepository) Stream(ctx context.Context, req dto.ListUsersRequest, fn func(user entity.User) error) error {
+	args := m.Called(ctx, req, fn)
+	if users, ok := args.Get(0).([]entity.User); ok {
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockUserRepository) Search(ctx context.Context, query string, limit int) ([]entity.UserSearchResult, error) {
+	args := m.Called(ctx, query, limit)
+	return args.Get(0).([]entity.UserSearchResult), args.Error(1)
+}
+
 func (m *MockUserRepository) GetByID(ctx context.Context, id string, timezone string) (*entity.User, error) {
 	args := m.Called(ctx, id, timezone)
 	if args.Get(0) == nil {
@@ -55,11 +78,53 @@ func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id string, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateStatus(ctx context.Context, id string, status entity.UserStatus, reason string, expiresAt *time.Time) error {
+	args := m.Called(ctx, id, status, reason, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) LiftExpiredSuspensions(ctx context.Context, now time.Time) ([]string, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepository) Anonymize(ctx context.Context, id string, email string) error {
+	args := m.Called(ctx, id, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockUserRepository) BulkCreate(ctx context.Context, users []entity.User) (map[string]bool, error) {
+	args := m.Called(ctx, users)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
 func TestUserUsecase_Register_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
 	cfg := &config.Config{}
 
-	uc := usecase.NewUserUsecase(mockRepo, cfg, nil)
+	mockOrg := new(MockOrganizationRepository)
+	mockOutbox := new(MockOutboxRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockOrg, mockAudit, mockOutbox, fakeTransactor{}, cfg, nil, nil)
 
 	mockRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(nil, nil)
 	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
@@ -70,10 +135,30 @@ func TestUserUsecase_Register_Success(t *testing.T) {
 		u.CreatedAt = time.Now()
 		u.UpdatedAt = time.Now()
 	}).Return(nil)
+	mockOrg.On("Create", mock.Anything, mock.MatchedBy(func(o *entity.Organization) bool {
+		return o.Name == "test@example.com"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Organization).ID = "org-1"
+	}).Return(nil)
+	mockOrg.On("AddMember", mock.Anything, &entity.Membership{
+		OrganizationID: "org-1", UserID: "test-uuid", Role: entity.MembershipRoleOwner,
+	}).Return(nil)
+
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditActionUserRegister && e.ResourceID == "test-uuid" &&
+			e.ActorID == "test-uuid" && e.TenantID == "org-1"
+	})).Return(nil)
+	mockOutbox.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.OutboxEvent) bool {
+		return e.EventType == entity.EventUserRegistered && e.AggregateID == "test-uuid" &&
+			string(e.Payload) == `{"user_id":"test-uuid","email":"test@example.com"}`
+	})).Return(nil)
 
 	err := uc.Register(context.Background(), "test@example.com", "password123")
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockOrg.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+	mockOutbox.AssertExpectations(t)
 }
 
 func TestUserUsecase_Login_Success(t *testing.T) {
@@ -87,7 +172,8 @@ func TestUserUsecase_Login_Success(t *testing.T) {
 		RefreshExpiresIn: 10080,
 	}}
 
-	uc := usecase.NewUserUsecase(mockRepo, cfg, nil)
+	mockOrg := new(MockOrganizationRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockOrg, new(MockAuditRepository), nil, fakeTransactor{}, cfg, nil, nil)
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 	user := &entity.User{
@@ -97,11 +183,21 @@ func TestUserUsecase_Login_Success(t *testing.T) {
 	}
 
 	mockRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(user, nil)
+	mockOrg.On("ListMemberships", mock.Anything, user.ID).Return([]entity.Membership{
+		{OrganizationID: "org-1", UserID: user.ID}, {OrganizationID: "org-2", UserID: user.ID},
+	}, nil)
 
-	accessToken, refreshToken, err := uc.Login(context.Background(), "test@example.com", "password123")
+	accessToken, refreshToken, err := uc.Login(context.Background(), "test@example.com", "password123", "org-2")
 	assert.NoError(t, err)
-	assert.NotEmpty(t, accessToken)
 	assert.NotEmpty(t, refreshToken)
+
+	claims, err := auth.ValidateToken(accessToken, cfg.JWT)
+	assert.NoError(t, err)
+	assert.Equal(t, "org-2", claims.TenantID)
+
+	_, _, err = uc.Login(context.Background(), "test@example.com", "password123", "org-3")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not a member")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -109,15 +205,15 @@ func TestUserUsecase_ListUsers(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	cfg := &config.Config{}
 
-	uc := usecase.NewUserUsecase(mockRepo, cfg, nil)
+	uc := usecase.NewUserUsecase(mockRepo, nil, new(MockAuditRepository), nil, fakeTransactor{}, cfg, nil, nil)
 
 	users := []entity.User{
 		{ID: "019c514b-a933-74f2-8d08-a496675c66cf", Email: "u1@example.com"},
 	}
 
-	mockRepo.On("List", mock.Anything, 1, 10, "created_at desc", "UTC").Return(users, int64(1), nil)
+	mockRepo.On("List", mock.Anything, dto.ListUsersRequest{Page: 1, Limit: 10, Order: "created_at desc"}, "UTC").Return(users, int64(1), nil)
 
-	res, meta, err := uc.ListUsers(context.Background(), 1, 10, "", "UTC")
+	res, meta, err := uc.ListUsers(context.Background(), dto.ListUsersRequest{Page: 1, Limit: 10}, "UTC")
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), meta.Total)
 	assert.Equal(t, "created_at desc", meta.Order)
@@ -125,3 +221,237 @@ func TestUserUsecase_ListUsers(t *testing.T) {
 	assert.Equal(t, "u1@example.com", res[0].Email)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUserUsecase_ExportUsers_AppliesTimezone(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, new(MockAuditRepository), nil, fakeTransactor{}, &config.Config{}, nil, nil)
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := []entity.User{{ID: "1", Email: "u1@example.com", CreatedAt: createdAt, UpdatedAt: createdAt}}
+	req := dto.ListUsersRequest{Search: "u1"}
+	mockRepo.On("Stream", mock.Anything, req, mock.Anything).Return(users, nil)
+
+	var exported []dto.UserResponse
+	err := uc.ExportUsers(context.Background(), req, "Asia/Jakarta", func(user dto.UserResponse) error {
+		exported = append(exported, user)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, exported, 1)
+	_, offset := exported[0].CreatedAt.Zone()
+	assert.Equal(t, 7*3600, offset)
+	assert.True(t, exported[0].CreatedAt.Equal(createdAt))
+}
+
+func TestUserUsecase_ExportUsers_InvalidTimezone(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, new(MockAuditRepository), nil, fakeTransactor{}, &config.Config{}, nil, nil)
+
+	err := uc.ExportUsers(context.Background(), dto.ListUsersRequest{}, "Mars/Olympus", func(dto.UserResponse) error { return nil })
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "Stream", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserUsecase_SearchUsers_Highlights(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, new(MockAuditRepository), nil, fakeTransactor{}, &config.Config{}, nil, nil)
+
+	matches := []entity.UserSearchResult{
+		{User: entity.User{ID: "1", Email: "john@example.com", FirstName: "John", LastName: "<Doe>"}, Score: 1.8},
+		{User: entity.User{ID: "2", Email: "jon@example.com", FirstName: "Jon"}, Score: 0.6},
+	}
+	mockRepo.On("Search", mock.Anything, "joh", 10).Return(matches, nil)
+
+	results, err := uc.SearchUsers(context.Background(), dto.SearchUsersRequest{Query: " joh "})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	assert.Equal(t, "<mark>joh</mark>n@example.com", results[0].Highlights["email"])
+	assert.Equal(t, "<mark>Joh</mark>n", results[0].Highlights["first_name"])
+	assert.NotContains(t, results[0].Highlights, "last_name")
+	assert.Equal(t, 1.8, results[0].Score)
+
+	// Fuzzy-only match has nothing literal to highlight
+	assert.Empty(t, results[1].Highlights)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserUsecase_UpdateUser_InvalidatesEveryTimezone(t *testing.T) {
+	mr, rdb := newTestRedis(t)
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	cfg := &config.Config{Cache: config.CacheConfig{UserTTL: time.Hour}}
+	uc := usecase.NewUserUsecase(mockRepo, nil, mockAudit, nil, fakeTransactor{}, cfg, cache.New(rdb), cache.New(rdb))
+
+	ctx := tenant.WithID(context.Background(), "org-1")
+	for _, tz := range []string{"UTC", "Asia/Jakarta"} {
+		mockRepo.On("GetByID", mock.Anything, "user-1", tz).Return(&entity.User{ID: "user-1", Email: "old@example.com"}, nil)
+		_, err := uc.GetUser(ctx, "user-1", tz)
+		assert.NoError(t, err)
+	}
+	assert.True(t, mr.Exists("user:user-1:org-1:Asia/Jakarta"))
+
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.Anything).Return(nil)
+	assert.NoError(t, uc.UpdateUser(ctx, "user-1", "new@example.com"))
+
+	assert.False(t, mr.Exists("user:user-1:org-1:UTC"))
+	assert.False(t, mr.Exists("user:user-1:org-1:Asia/Jakarta"))
+}
+
+func TestUserUsecase_GetCurrentUser_CachesPerTenant(t *testing.T) {
+	mr, rdb := newTestRedis(t)
+	mockRepo := new(MockUserRepository)
+	cfg := &config.Config{Session: config.SessionConfig{CurrentUserTTL: 30 * time.Second}}
+	uc := usecase.NewUserUsecase(mockRepo, nil, nil, nil, fakeTransactor{}, cfg, cache.New(rdb), cache.New(rdb))
+
+	mockRepo.On("GetByID", mock.Anything, "user-1", "UTC").
+		Return(&entity.User{ID: "user-1", Email: "me@example.com", Password: "hash"}, nil).Once()
+
+	ctx := tenant.WithID(context.Background(), "org-1")
+	for i := 0; i < 2; i++ {
+		user, err := uc.GetCurrentUser(ctx, "user-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "me@example.com", user.Email)
+	}
+	mockRepo.AssertNumberOfCalls(t, "GetByID", 1)
+
+	cached, err := mr.Get("user:user-1:org-1:current")
+	assert.NoError(t, err)
+	assert.NotContains(t, cached, "hash")
+	assert.Equal(t, 30*time.Second, mr.TTL("user:user-1:org-1:current"))
+}
+
+func TestUserUsecase_GetCurrentUser_Deleted(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, nil, nil, fakeTransactor{}, &config.Config{}, cache.New(rdb), cache.New(rdb))
+
+	mockRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(nil, errors.New("user not found"))
+
+	_, err := uc.GetCurrentUser(tenant.WithID(context.Background(), "org-1"), "user-1")
+	if assert.Error(t, err) {
+		assert.Equal(t, 401, err.(*appErrors.CustomError).Code)
+	}
+}
+
+func TestUserUsecase_Login_Suspended(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockOrganizationRepository), nil, nil, fakeTransactor{}, &config.Config{}, nil, nil)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	expiresAt := time.Now().Add(time.Hour)
+	mockRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(&entity.User{
+		ID: "user-1", Password: string(hashedPassword), Status: entity.UserStatusSuspended, StatusExpiresAt: &expiresAt,
+	}, nil)
+
+	_, _, err := uc.Login(context.Background(), "test@example.com", "password123", "")
+	var customErr *appErrors.CustomError
+	if assert.ErrorAs(t, err, &customErr) {
+		assert.Equal(t, 403, customErr.Code)
+		assert.Equal(t, usecase.ReasonAccountSuspended, customErr.Reason)
+	}
+
+	// A wrong password still reads as invalid credentials
+	_, _, err = uc.Login(context.Background(), "test@example.com", "wrong", "")
+	if assert.ErrorAs(t, err, &customErr) {
+		assert.Equal(t, 401, customErr.Code)
+	}
+}
+
+func TestUserUsecase_GetCurrentUser_Banned(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	mockRepo := new(MockUserRepository)
+	cfg := &config.Config{Session: config.SessionConfig{CurrentUserTTL: 30 * time.Second}}
+	uc := usecase.NewUserUsecase(mockRepo, nil, nil, nil, fakeTransactor{}, cfg, cache.New(rdb), cache.New(rdb))
+
+	mockRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(&entity.User{ID: "user-1", Status: entity.UserStatusBanned}, nil).Once()
+
+	// The second read is served from the cache and still refused
+	for i := 0; i < 2; i++ {
+		_, err := uc.GetCurrentUser(tenant.WithID(context.Background(), "org-1"), "user-1")
+		var customErr *appErrors.CustomError
+		if assert.ErrorAs(t, err, &customErr) {
+			assert.Equal(t, 403, customErr.Code)
+			assert.Equal(t, usecase.ReasonAccountBanned, customErr.Reason)
+		}
+	}
+}
+
+func TestUserUsecase_ChangeStatus(t *testing.T) {
+	mr, rdb := newTestRedis(t)
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	cfg := &config.Config{Session: config.SessionConfig{CurrentUserTTL: 30 * time.Second}}
+	uc := usecase.NewUserUsecase(mockRepo, nil, mockAudit, nil, fakeTransactor{}, cfg, cache.New(rdb), cache.New(rdb))
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	mockRepo.On("GetByID", mock.Anything, "user-1", "UTC").Return(&entity.User{ID: "user-1", Status: entity.UserStatusActive}, nil)
+	for _, org := range []string{"org-1", "org-2"} {
+		_, err := uc.GetCurrentUser(tenant.WithID(context.Background(), org), "user-1")
+		assert.NoError(t, err)
+	}
+	assert.True(t, mr.Exists("user:user-1:org-1:current"))
+	mockRepo.On("UpdateStatus", mock.Anything, "user-1", entity.UserStatusSuspended, "spam", &expiresAt).Return(nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		change, ok := e.Changes["status"]
+		return e.Action == entity.AuditActionUserStatusChange && ok &&
+			change.Before == "active" && change.After == "suspended"
+	})).Return(nil)
+
+	err := uc.ChangeStatus(context.Background(), "admin-1", "user-1", dto.ChangeUserStatusRequest{
+		Status: "suspended", Reason: "spam", ExpiresAt: &expiresAt,
+	})
+	assert.NoError(t, err)
+	assert.False(t, mr.Exists("user:user-1:org-1:current"))
+	assert.False(t, mr.Exists("user:user-1:org-2:current"))
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestUserUsecase_ChangeStatus_Invalid(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, nil, nil, fakeTransactor{}, &config.Config{}, nil, nil)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	cases := map[string]struct {
+		actorID string
+		req     dto.ChangeUserStatusRequest
+	}{
+		"own account":        {"user-1", dto.ChangeUserStatusRequest{Status: "suspended"}},
+		"expiry in the past": {"admin-1", dto.ChangeUserStatusRequest{Status: "suspended", ExpiresAt: &past}},
+		"expiring ban":       {"admin-1", dto.ChangeUserStatusRequest{Status: "banned", ExpiresAt: &future}},
+		"unknown status":     {"admin-1", dto.ChangeUserStatusRequest{Status: "frozen"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := uc.ChangeStatus(context.Background(), tc.actorID, "user-1", tc.req)
+			var customErr *appErrors.CustomError
+			if assert.ErrorAs(t, err, &customErr) {
+				assert.Equal(t, 400, customErr.Code)
+			}
+		})
+	}
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserUsecase_LiftExpiredSuspensions(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditRepository)
+	uc := usecase.NewUserUsecase(mockRepo, nil, mockAudit, nil, fakeTransactor{}, &config.Config{}, cache.New(rdb), cache.New(rdb))
+
+	mockRepo.On("LiftExpiredSuspensions", mock.MatchedBy(func(ctx context.Context) bool {
+		_, err := tenant.Scope(ctx)
+		return err == nil
+	}), mock.Anything).Return([]string{"user-1", "user-2"}, nil)
+	mockAudit.On("Create", mock.Anything, mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditActionUserStatusChange && e.Changes["status"].After == "active"
+	})).Return(nil).Twice()
+
+	lifted, err := uc.LiftExpiredSuspensions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, lifted)
+	mockAudit.AssertExpectations(t)
+}
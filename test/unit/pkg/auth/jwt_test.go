@@ -56,7 +56,7 @@ func TestGenerateAndValidateToken(t *testing.T) {
 
 	userID := "019c514b-a933-74f2-8d08-a496675c66cf"
 	// Test Token Pair
-	accessToken, refreshToken, err := auth.GenerateTokenPair(userID, cfg)
+	accessToken, refreshToken, err := auth.GenerateTokenPair(auth.Subject{UserID: userID, Role: "admin"}, cfg)
 	require.NoError(t, err)
 	assert.NotEmpty(t, accessToken)
 	assert.NotEmpty(t, refreshToken)
@@ -65,6 +65,7 @@ func TestGenerateAndValidateToken(t *testing.T) {
 	claims, err := auth.ValidateToken(accessToken, cfg)
 	require.NoError(t, err)
 	assert.Equal(t, userID, claims.UserID)
+	assert.Equal(t, "admin", claims.Role)
 	assert.Equal(t, auth.TokenTypeAccess, claims.TokenType)
 
 	// Validate Refresh Token
@@ -81,4 +82,9 @@ func TestGenerateAndValidateToken(t *testing.T) {
 	// Test invalid token string
 	_, err = auth.ValidateToken(accessToken+"invalid", cfg)
 	assert.Error(t, err)
+
+	// Keys are read once, later validations do not touch the files
+	require.NoError(t, os.Remove(pubFile.Name()))
+	_, err = auth.ValidateToken(accessToken, cfg)
+	assert.NoError(t, err)
 }
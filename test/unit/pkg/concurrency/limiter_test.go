@@ -0,0 +1,104 @@
+package concurrency_test
+
+import (
+	"testing"
+	"time"
+
+	"go-boilerplate/pkg/concurrency"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLimiter(initial int) *concurrency.Limiter {
+	return concurrency.NewLimiter(concurrency.Options{
+		InitialLimit:     initial,
+		MinLimit:         2,
+		MaxLimit:         20,
+		LatencyTarget:    100 * time.Millisecond,
+		Backoff:          0.5,
+		LowPriorityShare: 0.5,
+	})
+}
+
+func TestLimiter_ShedsAboveLimit(t *testing.T) {
+	limiter := newLimiter(4)
+
+	var releases []func(time.Duration)
+	for i := 0; i < 4; i++ {
+		release, ok := limiter.Acquire(concurrency.PriorityNormal)
+		require.True(t, ok)
+		releases = append(releases, release)
+	}
+	_, ok := limiter.Acquire(concurrency.PriorityNormal)
+	assert.False(t, ok)
+	assert.Equal(t, 4, limiter.InFlight())
+
+	releases[0](time.Millisecond)
+	releases[0](time.Millisecond) // releasing twice is harmless
+	assert.Equal(t, 3, limiter.InFlight())
+	_, ok = limiter.Acquire(concurrency.PriorityNormal)
+	assert.True(t, ok)
+}
+
+func TestLimiter_LowPriorityShare(t *testing.T) {
+	limiter := newLimiter(4)
+
+	for i := 0; i < 2; i++ {
+		_, ok := limiter.Acquire(concurrency.PriorityLow)
+		require.True(t, ok)
+	}
+	_, ok := limiter.Acquire(concurrency.PriorityLow)
+	assert.False(t, ok, "low priority requests only get half the limit")
+
+	_, ok = limiter.Acquire(concurrency.PriorityNormal)
+	assert.True(t, ok, "the rest is kept for normal priority")
+}
+
+func TestLimiter_AdaptsToLatency(t *testing.T) {
+	limiter := newLimiter(8)
+
+	// A slow request halves the limit, concurrent slow ones do not halve it again
+	for i := 0; i < 3; i++ {
+		release, ok := limiter.Acquire(concurrency.PriorityNormal)
+		require.True(t, ok)
+		release(time.Second)
+	}
+	assert.Equal(t, 4, limiter.Limit())
+
+	// Fast requests at full utilization grow it back additively
+	for i := 0; i < 40; i++ {
+		var releases []func(time.Duration)
+		for j := 0; j < limiter.Limit(); j++ {
+			release, ok := limiter.Acquire(concurrency.PriorityNormal)
+			require.True(t, ok)
+			releases = append(releases, release)
+		}
+		for _, release := range releases {
+			release(time.Millisecond)
+		}
+	}
+	assert.Greater(t, limiter.Limit(), 8)
+	assert.LessOrEqual(t, limiter.Limit(), 20)
+}
+
+func TestLimiter_DoesNotGrowWhenIdle(t *testing.T) {
+	limiter := newLimiter(8)
+
+	for i := 0; i < 100; i++ {
+		release, _ := limiter.Acquire(concurrency.PriorityNormal)
+		release(time.Millisecond)
+	}
+	assert.Equal(t, 8, limiter.Limit())
+}
+
+func TestLimiter_RespectsMinLimit(t *testing.T) {
+	limiter := newLimiter(4)
+
+	for i := 0; i < 5; i++ {
+		release, _ := limiter.Acquire(concurrency.PriorityNormal)
+		release(time.Second)
+		time.Sleep(110 * time.Millisecond)
+	}
+	assert.Equal(t, 2, limiter.Limit())
+}